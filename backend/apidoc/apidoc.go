@@ -0,0 +1,439 @@
+// Package apidoc describes the API as an OpenAPI 3 document. Routes are described where
+// they are registered on the mux router, with the models they read and write, and the
+// document is built by walking the router, so it lists exactly the routes served.
+package apidoc
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"eaglekidz-backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// Access is what a route needs to know about the minister making the request
+type Access int
+
+const (
+	Public     Access = iota // Anyone may call the route
+	Caller                   // The minister must identify themselves with the minister header
+	Restricted               // The minister must hold one of the restricted access roles
+	Redacted                 // Restricted fields are left out unless a restricted minister calls
+)
+
+// Operation describes what a route does and the types it reads and writes
+type Operation struct {
+	ID           string                 // Operation ID; defaults to the name of the route's handler
+	Summary      string                 // One line, also shown in the startup route table
+	Description  string                 // Optional details
+	Access       Access                 // What the route needs to know about the caller
+	Params       []Param                // Query parameters, and descriptions of path parameters
+	Request      interface{}            // Value of the request body's type, e.g. models.CreateWeekRequest{}
+	OptionalBody bool                   // The request body may be left out
+	Status       int                    // Status of a successful response; defaults to 200
+	Response     interface{}            // Value of the type of the response's data; nil when it only has a message
+	Meta         map[string]interface{} // Values of the types of the response's meta entries
+	Files        []string               // Content types sent instead of the JSON envelope, e.g. "text/csv"
+	Tags         []string
+}
+
+// Param describes a query or path parameter
+type Param struct {
+	Name        string
+	Type        string // "string" (the default), "integer" or "boolean"
+	Description string
+	Required    bool
+	Enum        []string // Values a string parameter may take
+}
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Tags       []Tag                            `json:"tags,omitempty"`
+	Paths      map[string]map[string]*OpenAPIOp `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag groups operations
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// OpenAPIOp is an OpenAPI operation object
+type OpenAPIOp struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is an OpenAPI parameter object, or a reference to one
+type Parameter struct {
+	Ref         string  `json:"$ref,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	In          string  `json:"in,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody is an OpenAPI request body object
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is an OpenAPI response object, or a reference to one
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is an OpenAPI media type object
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the parts of the document that operations refer to
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	Parameters      map[string]Parameter      `json:"parameters"`
+	Responses       map[string]*Response      `json:"responses"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme is an OpenAPI security scheme object
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	In          string `json:"in"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Route is a method and path served by the router
+type Route struct {
+	Method  string
+	Path    string
+	Summary string
+	Access  Access
+}
+
+// Docs collects the descriptions of routes as they are registered
+type Docs struct {
+	Info         Info
+	CallerHeader string // Header carrying the token of the minister making the request
+	FormatHeader string // Header choosing the response format
+	operations   map[*mux.Route]Operation
+}
+
+// New returns Docs for an API
+func New(info Info, callerHeader, formatHeader string) *Docs {
+	return &Docs{
+		Info:         info,
+		CallerHeader: callerHeader,
+		FormatHeader: formatHeader,
+		operations:   map[*mux.Route]Operation{},
+	}
+}
+
+// Describe records what a route does and returns the route
+func (d *Docs) Describe(route *mux.Route, op Operation) *mux.Route {
+	d.operations[route] = op
+	return route
+}
+
+// Group describes routes under a tag
+func (d *Docs) Group(tag string) *Group {
+	return &Group{docs: d, tag: tag}
+}
+
+// Group describes routes that share a tag, such as the week routes
+type Group struct {
+	docs *Docs
+	tag  string
+}
+
+// Describe records what a route does under the group's tag and returns the route
+func (g *Group) Describe(route *mux.Route, op Operation) *mux.Route {
+	op.Tags = append([]string{g.tag}, op.Tags...)
+	return g.docs.Describe(route, op)
+}
+
+// Routes lists every method and path served by the router, in registration order.
+// CORS preflight requests are left out.
+func (d *Docs) Routes(router *mux.Router) ([]Route, error) {
+	var routes []Route
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Path prefixes of subrouters serve no method of their own
+			return nil
+		}
+		for _, method := range methods {
+			if method != http.MethodOptions {
+				op := d.operations[route]
+				routes = append(routes, Route{Method: method, Path: path, Summary: op.Summary, Access: op.Access})
+			}
+		}
+		return nil
+	})
+	return routes, err
+}
+
+// PrintRoutes writes the route table of the router
+func (d *Docs) PrintRoutes(w io.Writer, router *mux.Router) error {
+	routes, err := d.Routes(router)
+	if err != nil {
+		return err
+	}
+	for _, route := range routes {
+		line := fmt.Sprintf("  %s %s", route.Method, route.Path)
+		if route.Summary != "" {
+			line += " - " + route.Summary
+		}
+		if route.Access == Restricted {
+			line += " (restricted)"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pathVariable matches the variables of a mux path template, e.g. "{id}" or "{id:[0-9]+}"
+var pathVariable = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+
+// Document builds the OpenAPI document of the routes served by the router
+func (d *Docs) Document(router *mux.Router) (*Document, error) {
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    d.Info,
+		Paths:   map[string]map[string]*OpenAPIOp{},
+	}
+	s := newSchemas()
+	envelope := s.of(reflect.TypeOf(models.APIResponse{}))
+	ids := map[string]string{}
+	tags := map[string]bool{}
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		described := d.operations[route]
+		path := pathVariable.ReplaceAllString(template, "{$1}")
+		for _, method := range methods {
+			if method == http.MethodOptions {
+				continue
+			}
+			op := d.operation(s, route, template, described)
+			if op.OperationID != "" {
+				if other, taken := ids[op.OperationID]; taken {
+					return fmt.Errorf("apidoc: %s %s and %s share the operation ID %q; set Operation.ID", method, path, other, op.OperationID)
+				}
+				ids[op.OperationID] = method + " " + path
+			}
+			for _, tag := range op.Tags {
+				if !tags[tag] {
+					tags[tag] = true
+					doc.Tags = append(doc.Tags, Tag{Name: tag})
+				}
+			}
+			if doc.Paths[path] == nil {
+				doc.Paths[path] = map[string]*OpenAPIOp{}
+			}
+			doc.Paths[path][strings.ToLower(method)] = op
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc.Components = Components{
+		Schemas: s.defs,
+		Parameters: map[string]Parameter{
+			"ResponseFormat": {
+				Name:        d.FormatHeader,
+				In:          "header",
+				Description: "Response format of this request: the envelope, or the legacy shapes used before it",
+				Schema:      &Schema{Type: "string", Enum: []string{"envelope", "legacy"}},
+			},
+		},
+		Responses: map[string]*Response{
+			"Error": {
+				Description: "The request failed; error.code says why",
+				Content:     map[string]MediaType{"application/json": {Schema: envelope}},
+			},
+		},
+		SecuritySchemes: map[string]SecurityScheme{
+			"minister": {
+				Type:        "apiKey",
+				In:          "header",
+				Name:        d.CallerHeader,
+				Description: "Token of the minister making the request",
+			},
+		},
+	}
+	return doc, nil
+}
+
+// operation builds the OpenAPI operation of a described route
+func (d *Docs) operation(s *schemas, route *mux.Route, template string, described Operation) *OpenAPIOp {
+	op := &OpenAPIOp{
+		OperationID: described.ID,
+		Summary:     described.Summary,
+		Description: described.Description,
+		Tags:        described.Tags,
+		Responses:   map[string]*Response{"default": {Ref: "#/components/responses/Error"}},
+	}
+	if op.OperationID == "" {
+		op.OperationID = handlerName(route.GetHandler())
+	}
+
+	params := map[string]Param{}
+	for _, param := range described.Params {
+		params[param.Name] = param
+	}
+	for _, match := range pathVariable.FindAllStringSubmatch(template, -1) {
+		param := params[match[1]]
+		delete(params, match[1])
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        match[1],
+			In:          "path",
+			Description: param.Description,
+			Required:    true,
+			Schema:      paramSchema(param),
+		})
+	}
+	for _, param := range described.Params {
+		if _, ok := params[param.Name]; ok {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        param.Name,
+				In:          "query",
+				Description: param.Description,
+				Required:    param.Required,
+				Schema:      paramSchema(param),
+			})
+		}
+	}
+	op.Parameters = append(op.Parameters, Parameter{Ref: "#/components/parameters/ResponseFormat"})
+
+	switch described.Access {
+	case Caller:
+		op.Security = []map[string][]string{{"minister": {}}}
+	case Restricted:
+		op.Security = []map[string][]string{{"minister": {}}}
+		op.Description = joinText(op.Description, "Restricted to ministers holding a restricted access role.")
+	case Redacted:
+		op.Security = []map[string][]string{{}, {"minister": {}}}
+		op.Description = joinText(op.Description, "Restricted fields are left out unless the caller holds a restricted access role.")
+	}
+
+	if described.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: !described.OptionalBody,
+			Content:  map[string]MediaType{"application/json": {Schema: s.of(reflect.TypeOf(described.Request))}},
+		}
+	}
+
+	status := described.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status), Content: map[string]MediaType{}}
+	if described.Response != nil || len(described.Files) == 0 {
+		success.Content["application/json"] = MediaType{Schema: successSchema(s, described)}
+	}
+	for _, contentType := range described.Files {
+		schema := &Schema{Type: "string", Format: "binary"}
+		if contentType == "application/json" {
+			schema = &Schema{Type: "object"}
+		}
+		success.Content[contentType] = MediaType{Schema: schema}
+	}
+	op.Responses[fmt.Sprint(status)] = success
+	return op
+}
+
+// successSchema returns the schema of the envelope of a successful response
+func successSchema(s *schemas, described Operation) *Schema {
+	schema := &Schema{
+		Type:     "object",
+		Required: []string{"success"},
+		Properties: map[string]*Schema{
+			"success": {Type: "boolean"},
+			"message": {Type: "string"},
+		},
+	}
+	if described.Response != nil {
+		schema.Properties["data"] = s.of(reflect.TypeOf(described.Response))
+		schema.Required = append(schema.Required, "data")
+	}
+	if len(described.Meta) > 0 {
+		meta := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for key, value := range described.Meta {
+			meta.Properties[key] = s.of(reflect.TypeOf(value))
+			meta.Required = append(meta.Required, key)
+		}
+		sort.Strings(meta.Required)
+		schema.Properties["meta"] = meta
+	}
+	return schema
+}
+
+func paramSchema(param Param) *Schema {
+	switch param.Type {
+	case "integer", "boolean":
+		return &Schema{Type: param.Type}
+	default:
+		return &Schema{Type: "string", Enum: param.Enum}
+	}
+}
+
+// handlerName returns the name of a route's handler function, e.g. "CreateWeek"
+func handlerName(handler http.Handler) string {
+	fn, ok := handler.(http.HandlerFunc)
+	if !ok {
+		return ""
+	}
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func joinText(text, sentence string) string {
+	if text == "" {
+		return sentence
+	}
+	return text + " " + sentence
+}
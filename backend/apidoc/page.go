@@ -0,0 +1,9 @@
+package apidoc
+
+import _ "embed"
+
+// Page is the HTML page that renders the document. It loads the document from
+// "openapi.json" next to it and needs nothing else, so it works offline.
+//
+//go:embed page.html
+var Page []byte
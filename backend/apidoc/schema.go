@@ -0,0 +1,219 @@
+package apidoc
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Schema is an OpenAPI schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	objectIDType      = reflect.TypeOf(primitive.ObjectID{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// objectIDPattern matches the hex form ObjectIDs take in JSON
+const objectIDPattern = "^[0-9a-f]{24}$"
+
+// schemas builds the schemas of Go types, keeping every named struct once under
+// components/schemas and referring to it from the others
+type schemas struct {
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{defs: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// of returns the schema of the JSON encoding of t
+func (s *schemas) of(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case objectIDType:
+		return &Schema{Type: "string", Pattern: objectIDPattern}
+	}
+	if t.Kind() != reflect.Ptr && t.Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.of(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + s.define(t)}
+	default:
+		// interface{} and anything else JSON can hold
+		return &Schema{}
+	}
+}
+
+// define adds the schema of a named struct to the components and returns its name
+func (s *schemas) define(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := s.defs[name]; taken {
+		// Another package has a type of the same name
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	s.names[t] = name
+	s.defs[name] = &Schema{} // Placeholder for types that refer to themselves
+	s.defs[name] = s.object(t)
+	return name
+}
+
+// object returns the schema of a struct, with the fields encoding/json would write
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(schema, t)
+	return schema
+}
+
+func (s *schemas) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		// Fields of embedded structs are written as fields of the outer struct
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				s.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.of(field.Type)
+		if applyRules(property, field) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyRules describes a field's `binding` rules on its schema and reports whether the
+// field is required
+func applyRules(schema *Schema, field reflect.StructField) bool {
+	rules := field.Tag.Get("binding")
+	if rules == "" {
+		return false
+	}
+	if schema.Ref != "" {
+		// A $ref cannot carry other keywords, so only required is kept
+		return strings.Contains(","+rules+",", ",required,")
+	}
+
+	kind := field.Type.Kind()
+	if kind == reflect.Ptr {
+		kind = field.Type.Elem().Kind()
+	}
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+			if kind == reflect.String {
+				schema.MinLength = intPtr(1)
+			}
+		case "notblank":
+			schema.MinLength = intPtr(1)
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "email":
+			schema.Format = "email"
+		case "phone":
+			schema.Description = "Phone number of 7 to 15 digits, optionally starting with + and written with spaces, dashes, dots or parentheses"
+		case "min", "max":
+			limit, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			switch {
+			case kind == reflect.String && name == "min":
+				schema.MinLength = intPtr(limit)
+			case kind == reflect.String:
+				schema.MaxLength = intPtr(limit)
+			case (kind == reflect.Slice || kind == reflect.Array) && name == "min":
+				schema.MinItems = intPtr(limit)
+			case kind == reflect.Slice || kind == reflect.Array:
+				schema.MaxItems = intPtr(limit)
+			case name == "min":
+				schema.Minimum = intPtr(limit)
+			default:
+				schema.Maximum = intPtr(limit)
+			}
+		}
+	}
+	return required
+}
+
+func intPtr(n int) *int {
+	return &n
+}
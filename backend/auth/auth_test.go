@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestTokens(t *testing.T, seed byte) *Tokens {
+	t.Helper()
+	secret := base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune('a'+seed)), 32)))
+	tokens, err := NewTokens(secret)
+	if err != nil {
+		t.Fatalf("NewTokens: %v", err)
+	}
+	return tokens
+}
+
+func TestNewTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		enabled bool
+		wantErr bool
+	}{
+		{"unset", "", false, false},
+		{"blank", "   ", false, false},
+		{"not base64", "not base64!", false, true},
+		{"too short", base64.StdEncoding.EncodeToString([]byte("short")), false, true},
+		{"valid", base64.StdEncoding.EncodeToString(make([]byte, 32)), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := NewTokens(tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if tokens.Enabled() != tt.enabled {
+				t.Errorf("Enabled() = %v, want %v", tokens.Enabled(), tt.enabled)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tokens := newTestTokens(t, 0)
+	now := time.Unix(1700000000, 0)
+	valid, err := tokens.Issue("65a1b2c3d4e5f60718293a4b", now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	expired, _ := tokens.Issue("65a1b2c3d4e5f60718293a4b", now)
+	otherSecret, _ := newTestTokens(t, 1).Issue("65a1b2c3d4e5f60718293a4b", now.Add(time.Hour))
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		want    string
+		wantErr error
+	}{
+		{"valid", valid, "65a1b2c3d4e5f60718293a4b", nil},
+		{"expired", expired, "", ErrExpiredToken},
+		{"other secret", otherSecret, "", ErrInvalidToken},
+		{"other minister", strings.Join([]string{parts[0], "65a1b2c3d4e5f60718293a4c", parts[2], parts[3]}, "."), "", ErrInvalidToken},
+		{"later expiry", strings.Join([]string{parts[0], parts[1], "9999999999", parts[3]}, "."), "", ErrInvalidToken},
+		{"no signature", strings.Join(parts[:3], "."), "", ErrInvalidToken},
+		{"empty signature", strings.Join(parts[:3], ".") + ".", "", ErrInvalidToken},
+		{"other version", strings.Join([]string{"v2", parts[1], parts[2], parts[3]}, "."), "", ErrInvalidToken},
+		{"minister ID only", "65a1b2c3d4e5f60718293a4b", "", ErrInvalidToken},
+		{"empty", "", "", ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokens.Verify(tt.token, now)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("minister = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	var tokens *Tokens
+	if _, err := tokens.Issue("65a1b2c3d4e5f60718293a4b", time.Now().Add(time.Hour)); err != ErrDisabled {
+		t.Errorf("Issue err = %v, want %v", err, ErrDisabled)
+	}
+
+	valid, _ := newTestTokens(t, 0).Issue("65a1b2c3d4e5f60718293a4b", time.Now().Add(time.Hour))
+	if _, err := tokens.Verify(valid, time.Now()); err != ErrDisabled {
+		t.Errorf("Verify err = %v, want %v", err, ErrDisabled)
+	}
+}
+
+func TestIssueRejectsIDsThatBreakTheFormat(t *testing.T) {
+	tokens := newTestTokens(t, 0)
+	for _, id := range []string{"", "a.b"} {
+		if _, err := tokens.Issue(id, time.Now().Add(time.Hour)); err == nil {
+			t.Errorf("Issue(%q) succeeded, want error", id)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// prefix marks a stored value as ciphertext: "enc:v1:<key id>:<base64 nonce+ciphertext>"
+const prefix = "enc:v1:"
+
+// Keyring holds the AES-256 keys used for field-level encryption. The first key is
+// current and used to encrypt; older keys are kept so existing values can still be
+// decrypted until they are rotated. A nil Keyring disables encryption and stores
+// values as plaintext.
+type Keyring struct {
+	keys     map[string][]byte
+	current  string
+	blindKey []byte
+}
+
+// NewKeyring parses keys given as "id:base64key,id:base64key" (current key first) and a
+// base64 blind index key. It returns nil when no keys are configured.
+func NewKeyring(keySpec, blindIndexKey string) (*Keyring, error) {
+	keySpec = strings.TrimSpace(keySpec)
+	if keySpec == "" {
+		return nil, nil
+	}
+
+	k := &Keyring{keys: make(map[string][]byte)}
+	for _, entry := range strings.Split(keySpec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("encryption keys must be formatted as id:base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("encryption key %q must be 32 bytes encoded as base64", parts[0])
+		}
+		if _, exists := k.keys[parts[0]]; exists {
+			return nil, fmt.Errorf("encryption key %q is listed twice", parts[0])
+		}
+		k.keys[parts[0]] = key
+		if k.current == "" {
+			k.current = parts[0]
+		}
+	}
+
+	blindKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(blindIndexKey))
+	if err != nil || len(blindKey) < 32 {
+		return nil, fmt.Errorf("blind index key must be at least 32 bytes encoded as base64")
+	}
+	k.blindKey = blindKey
+
+	return k, nil
+}
+
+// Enabled reports whether values are encrypted
+func (k *Keyring) Enabled() bool {
+	return k != nil
+}
+
+// CurrentKeyID returns the ID of the key new values are encrypted with
+func (k *Keyring) CurrentKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.current
+}
+
+// Encrypt encrypts a value with the current key. Empty values stay empty.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if k == nil || plaintext == "" {
+		return plaintext, nil
+	}
+
+	gcm, err := newGCM(k.keys[k.current])
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %v", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(k.current))
+	return prefix + k.current + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt. Values without the ciphertext
+// prefix were stored before encryption was enabled and are returned as they are.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if k == nil {
+		return "", fmt.Errorf("value is encrypted but no encryption keys are configured")
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(value, prefix), ":", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+	key, ok := k.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("encryption key %q is not configured", parts[0])
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(parts[0]))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %v", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether a stored value is plaintext or encrypted with an old key
+func (k *Keyring) NeedsRotation(value string) bool {
+	if k == nil || value == "" {
+		return false
+	}
+	return !strings.HasPrefix(value, prefix+k.current+":")
+}
+
+// BlindIndex returns a keyed hash of value for equality search on encrypted fields.
+// It returns an empty string when encryption is disabled or value is empty.
+func (k *Keyring) BlindIndex(value string) string {
+	if k == nil || value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, k.blindKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsEncrypted reports whether value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %v", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,173 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+var (
+	oldKey   = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	newKey   = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	blindKey = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, 32))
+)
+
+func keyring(t *testing.T, keySpec string) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(keySpec, blindKey)
+	if err != nil {
+		t.Fatalf("NewKeyring(%q) = %v", keySpec, err)
+	}
+	return k
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	k := keyring(t, "k1:"+oldKey)
+
+	for _, plaintext := range []string{"", "peanuts", "Ünïcödé allergy 🥜"} {
+		ciphertext, err := k.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("Encrypt(%q) = %v", plaintext, err)
+		}
+		if plaintext != "" && (!IsEncrypted(ciphertext) || strings.Contains(ciphertext, plaintext)) {
+			t.Errorf("Encrypt(%q) = %q, want ciphertext", plaintext, ciphertext)
+		}
+		got, err := k.Decrypt(ciphertext)
+		if err != nil || got != plaintext {
+			t.Errorf("Decrypt(Encrypt(%q)) = %q, %v", plaintext, got, err)
+		}
+	}
+
+	a, _ := k.Encrypt("peanuts")
+	b, _ := k.Encrypt("peanuts")
+	if a == b {
+		t.Errorf("Encrypt gave the same ciphertext twice, want a fresh nonce each time")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	old := keyring(t, "k1:"+oldKey)
+	stored, err := old.Encrypt("peanuts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The new key is current and the old one is kept for decryption
+	rotated := keyring(t, "k2:"+newKey+",k1:"+oldKey)
+	if got, err := rotated.Decrypt(stored); err != nil || got != "peanuts" {
+		t.Errorf("Decrypt with the old key kept = %q, %v", got, err)
+	}
+	if !rotated.NeedsRotation(stored) {
+		t.Errorf("NeedsRotation(old ciphertext) = false, want true")
+	}
+	if !rotated.NeedsRotation("plaintext") {
+		t.Errorf("NeedsRotation(plaintext) = false, want true")
+	}
+	if rotated.NeedsRotation("") {
+		t.Errorf("NeedsRotation(\"\") = true, want false")
+	}
+	current, _ := rotated.Encrypt("peanuts")
+	if rotated.NeedsRotation(current) {
+		t.Errorf("NeedsRotation(current ciphertext) = true, want false")
+	}
+
+	// Once the old key is rotated out, its values can no longer be read
+	retired := keyring(t, "k2:"+newKey)
+	if _, err := retired.Decrypt(stored); err == nil {
+		t.Errorf("Decrypt with the key rotated out succeeded, want an error")
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	k := keyring(t, "k1:"+oldKey)
+	stored, err := k.Encrypt("peanuts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := strings.TrimPrefix(stored, prefix+"k1:")
+	sealed, _ := base64.StdEncoding.DecodeString(encoded)
+	sealed[len(sealed)-1] ^= 1
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"flipped bit", prefix + "k1:" + base64.StdEncoding.EncodeToString(sealed)},
+		{"truncated", prefix + "k1:" + base64.StdEncoding.EncodeToString(sealed[:4])},
+		{"not base64", prefix + "k1:%%%"},
+		{"no key id", prefix + encoded},
+		{"key id swapped", prefix + "k2:" + encoded},
+	}
+
+	swapped := keyring(t, "k1:"+oldKey+",k2:"+newKey)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := swapped.Decrypt(tt.value); err == nil {
+				t.Errorf("Decrypt(%q) = %q, want an error", tt.value, got)
+			}
+		})
+	}
+}
+
+func TestWithoutKeyring(t *testing.T) {
+	k, err := NewKeyring("", "")
+	if err != nil || k != nil {
+		t.Fatalf("NewKeyring with no keys = %v, %v, want nil", k, err)
+	}
+	if k.Enabled() {
+		t.Errorf("Enabled() = true, want false")
+	}
+
+	stored, err := k.Encrypt("peanuts")
+	if err != nil || stored != "peanuts" {
+		t.Errorf("Encrypt = %q, %v, want the plaintext", stored, err)
+	}
+	if got, err := k.Decrypt("peanuts"); err != nil || got != "peanuts" {
+		t.Errorf("Decrypt = %q, %v, want the plaintext", got, err)
+	}
+	if k.NeedsRotation("peanuts") {
+		t.Errorf("NeedsRotation = true, want false")
+	}
+	if got := k.BlindIndex("peanuts"); got != "" {
+		t.Errorf("BlindIndex = %q, want empty", got)
+	}
+
+	// Ciphertext cannot be read once the keys are removed
+	encrypted, _ := keyring(t, "k1:"+oldKey).Encrypt("peanuts")
+	if _, err := k.Decrypt(encrypted); err == nil {
+		t.Errorf("Decrypt of ciphertext without keys succeeded, want an error")
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	k := keyring(t, "k1:"+oldKey)
+	index := k.BlindIndex("+44 20 7946 0958")
+	if index == "" || index == "+44 20 7946 0958" {
+		t.Fatalf("BlindIndex = %q, want a keyed hash", index)
+	}
+	if got := k.BlindIndex("+44 20 7946 0958"); got != index {
+		t.Errorf("BlindIndex changed between calls: %q, %q", index, got)
+	}
+	// Rotating encryption keys keeps the index, so lookups still find old records
+	if got := keyring(t, "k2:"+newKey+",k1:"+oldKey).BlindIndex("+44 20 7946 0958"); got != index {
+		t.Errorf("BlindIndex after key rotation = %q, want %q", got, index)
+	}
+	if got := k.BlindIndex("+44 20 7946 0959"); got == index {
+		t.Errorf("BlindIndex of a different value matched")
+	}
+	if got := k.BlindIndex(""); got != "" {
+		t.Errorf("BlindIndex(\"\") = %q, want empty", got)
+	}
+}
+
+func TestNewKeyringErrors(t *testing.T) {
+	for _, spec := range []string{"k1", ":" + oldKey, "k1:short", "k1:" + oldKey + ",k1:" + newKey} {
+		if _, err := NewKeyring(spec, blindKey); err == nil {
+			t.Errorf("NewKeyring(%q) succeeded, want an error", spec)
+		}
+	}
+	if _, err := NewKeyring("k1:"+oldKey, ""); err == nil {
+		t.Errorf("NewKeyring without a blind index key succeeded, want an error")
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+)
+
+type ActionItemHandler struct {
+	actionItemService *services.ActionItemService
+	weekService       *services.WeekService
+}
+
+func NewActionItemHandler(actionItemService *services.ActionItemService, weekService *services.WeekService) *ActionItemHandler {
+	return &ActionItemHandler{
+		actionItemService: actionItemService,
+		weekService:       weekService,
+	}
+}
+
+// CreateActionItem handles POST /api/v1/reviews/{id}/action-items
+func (h *ActionItemHandler) CreateActionItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	reviewID := vars["id"]
+
+	var req models.CreateActionItemRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	item, err := h.actionItemService.CreateActionItem(r.Context(), reviewID, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusCreated, item)
+}
+
+// GetReviewActionItems handles GET /api/v1/reviews/{id}/action-items
+func (h *ActionItemHandler) GetReviewActionItems(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	reviewID := vars["id"]
+
+	items, err := h.actionItemService.GetReviewActionItems(r.Context(), reviewID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, items)
+}
+
+// GetWeekActionItems handles GET /api/v1/weeks/{id}/action-items
+func (h *ActionItemHandler) GetWeekActionItems(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	weekID := vars["id"]
+
+	week, err := h.weekService.GetWeekByID(r.Context(), weekID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	items, err := h.actionItemService.GetWeekActionItems(r.Context(), week)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, items)
+}
+
+// GetActionItems handles GET /api/v1/action-items
+func (h *ActionItemHandler) GetActionItems(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.ActionItemFilter{OwnerID: query.Get("owner_id")}
+	if status := query.Get("status"); status != "" {
+		filter.Status = strings.Split(status, ",")
+	}
+
+	items, err := h.actionItemService.GetActionItems(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, items)
+}
+
+// GetOverdue handles GET /api/v1/action-items/overdue
+func (h *ActionItemHandler) GetOverdue(w http.ResponseWriter, r *http.Request) {
+	items, err := h.actionItemService.GetOverdue(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, items)
+}
+
+// GetActionItem handles GET /api/v1/action-items/{id}
+func (h *ActionItemHandler) GetActionItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	item, err := h.actionItemService.GetActionItem(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, item)
+}
+
+// UpdateActionItem handles PUT /api/v1/action-items/{id}
+func (h *ActionItemHandler) UpdateActionItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateActionItemRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	item, err := h.actionItemService.UpdateActionItem(r.Context(), id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, item)
+}
+
+// AddComment handles POST /api/v1/action-items/{id}/comments
+func (h *ActionItemHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.AddActionItemCommentRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	item, err := h.actionItemService.AddComment(r.Context(), id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusCreated, item)
+}
+
+// DeleteActionItem handles DELETE /api/v1/action-items/{id}
+func (h *ActionItemHandler) DeleteActionItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.actionItemService.DeleteActionItem(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeMessage(w, r, "Action item deleted successfully")
+}
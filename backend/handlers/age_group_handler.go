@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+)
+
+// AgeGroupHandler serves the age group catalog and promotions. Changes to the catalog
+// carry over to people, so creating, updating and deleting age groups is restricted.
+type AgeGroupHandler struct {
+	ageGroupService *services.AgeGroupService
+	accessService   *services.AccessService
+}
+
+func NewAgeGroupHandler(ageGroupService *services.AgeGroupService, accessService *services.AccessService) *AgeGroupHandler {
+	return &AgeGroupHandler{
+		ageGroupService: ageGroupService,
+		accessService:   accessService,
+	}
+}
+
+// CreateAgeGroup handles POST /api/v1/age-groups
+func (h *AgeGroupHandler) CreateAgeGroup(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	var req models.CreateAgeGroupRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	group, err := h.ageGroupService.CreateAgeGroup(ctx, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusCreated, group)
+}
+
+// GetAgeGroups handles GET /api/v1/age-groups
+func (h *AgeGroupHandler) GetAgeGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.ageGroupService.GetAgeGroups(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, groups)
+}
+
+// GetAgeGroup handles GET /api/v1/age-groups/{id}
+func (h *AgeGroupHandler) GetAgeGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	group, err := h.ageGroupService.GetAgeGroupByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, group)
+}
+
+// UpdateAgeGroup handles PUT /api/v1/age-groups/{id}
+func (h *AgeGroupHandler) UpdateAgeGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAgeGroupRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	group, err := h.ageGroupService.UpdateAgeGroup(ctx, id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, group)
+}
+
+// DeleteAgeGroup handles DELETE /api/v1/age-groups/{id}
+func (h *AgeGroupHandler) DeleteAgeGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	if err := h.ageGroupService.DeleteAgeGroup(ctx, id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeMessage(w, r, "Age group deleted successfully")
+}
+
+// PreviewPromotion handles GET /api/v1/promotions/preview
+func (h *AgeGroupHandler) PreviewPromotion(w http.ResponseWriter, r *http.Request) {
+	asOf := h.ageGroupService.PromotionDay().Next(time.Now())
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		parsed, err := parseReportDate(value)
+		if err != nil {
+			writeError(w, r, invalidRequest("'as_of' must be a date (YYYY-MM-DD or RFC 3339)"))
+			return
+		}
+		asOf = parsed
+	}
+
+	plan, err := h.ageGroupService.PreviewPromotion(r.Context(), asOf)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, plan)
+}
+
+// ApplyPromotion handles POST /api/v1/promotions
+func (h *AgeGroupHandler) ApplyPromotion(w http.ResponseWriter, r *http.Request) {
+	// The body is optional; without it the next promotion date is applied
+	var req models.ApplyPromotionRequest
+	if !decodeOptionalRequest(w, r, &req) {
+		return
+	}
+
+	asOf := h.ageGroupService.PromotionDay().Next(time.Now())
+	if req.AsOf != nil {
+		asOf = *req.AsOf
+	}
+
+	plan, err := h.ageGroupService.ApplyPromotion(r.Context(), asOf, req.AppliedBy)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, models.APIResponse{Data: plan, Message: fmt.Sprintf("Moved %d children", len(plan.Moves))})
+}
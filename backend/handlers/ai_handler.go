@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
+
+	"eaglekidz-backend/sanitize"
+	"eaglekidz-backend/services"
 )
 
 type AIHandler struct{}
@@ -16,9 +20,9 @@ func NewAIHandler() *AIHandler {
 
 // AIRequest represents the request structure for AI summarization
 type AIRequest struct {
-	WhatWentWell string `json:"what_went_well"`
-	CanImprove   string `json:"can_improve"`
-	ActionPlans  string `json:"action_plans"`
+	WhatWentWell string `json:"what_went_well" binding:"required,max=20000"`
+	CanImprove   string `json:"can_improve" binding:"required,max=20000"`
+	ActionPlans  string `json:"action_plans" binding:"max=20000"`
 }
 
 // AIResponse represents the response structure from AI service
@@ -29,31 +33,23 @@ type AIResponse struct {
 // GenerateSummary handles POST /api/v1/ai/summarize
 func (h *AIHandler) GenerateSummary(w http.ResponseWriter, r *http.Request) {
 	var req AIRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
-	// Validate required fields
-	if req.WhatWentWell == "" || req.CanImprove == "" {
-		http.Error(w, "Both 'what_went_well' and 'can_improve' fields are required", http.StatusBadRequest)
-		return
-	}
-
-	// Generate AI summary
-	summary, err := h.callAIService(req.WhatWentWell, req.CanImprove, req.ActionPlans)
+	// Generate AI summary from the plain text of the editor's HTML
+	summary, err := h.callAIService(sanitize.PlainText(req.WhatWentWell), sanitize.PlainText(req.CanImprove), sanitize.PlainText(req.ActionPlans))
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to generate AI summary: %v", err), http.StatusInternalServerError)
+		// The upstream error can hold details of the AI service, so it is only logged
+		log.Printf("%s %s: AI service: %v", r.Method, r.URL.Path, err)
+		writeError(w, r, services.NewError(services.ErrUnavailable, services.CodeServiceUnavailable, "AI summary is unavailable, please try again later"))
 		return
 	}
+	// The model is asked for HTML, which is rendered by the frontend, so only allowlisted markup is kept
+	summary = sanitize.HTML(summary)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data": AIResponse{
-			Summary: summary,
-		},
+	writeData(w, r, http.StatusOK, AIResponse{
+		Summary: summary,
 	})
 }
 
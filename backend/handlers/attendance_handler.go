@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+)
+
+type AttendanceHandler struct {
+	attendanceService *services.AttendanceService
+	accessService     *services.AccessService
+}
+
+func NewAttendanceHandler(attendanceService *services.AttendanceService, accessService *services.AccessService) *AttendanceHandler {
+	return &AttendanceHandler{
+		attendanceService: attendanceService,
+		accessService:     accessService,
+	}
+}
+
+// CheckIn handles POST /api/v1/attendance/check-in
+func (h *AttendanceHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	var req models.CheckInRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	attendance, err := h.attendanceService.CheckIn(r.Context(), req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusCreated, attendance)
+}
+
+// CheckOut handles PUT /api/v1/attendance/{id}/check-out
+func (h *AttendanceHandler) CheckOut(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.CheckOutRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	attendance, err := h.attendanceService.CheckOut(r.Context(), id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, attendance)
+}
+
+// UnlockCheckOut handles PUT /api/v1/attendance/{id}/unlock
+func (h *AttendanceHandler) UnlockCheckOut(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	attendance, err := h.attendanceService.UnlockCheckOut(ctx, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, attendance)
+}
+
+// GetCheckedInByWeek handles GET /api/v1/weeks/{weekId}/checked-in
+func (h *AttendanceHandler) GetCheckedInByWeek(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	weekID := vars["weekId"]
+
+	checkedIn, err := h.attendanceService.GetCheckedInByWeek(r.Context(), weekID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, checkedIn)
+}
+
+// GetAttendanceByWeek handles GET /api/v1/weeks/{weekId}/attendance
+func (h *AttendanceHandler) GetAttendanceByWeek(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	weekID := vars["weekId"]
+
+	records, err := h.attendanceService.GetAttendanceByWeek(r.Context(), weekID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, records)
+}
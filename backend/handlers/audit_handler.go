@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"eaglekidz-backend/services"
+)
+
+type AuditHandler struct {
+	auditService  *services.AuditService
+	accessService *services.AccessService
+}
+
+func NewAuditHandler(auditService *services.AuditService, accessService *services.AccessService) *AuditHandler {
+	return &AuditHandler{
+		auditService:  auditService,
+		accessService: accessService,
+	}
+}
+
+// GetEntries handles GET /api/v1/audit
+func (h *AuditHandler) GetEntries(w http.ResponseWriter, r *http.Request) {
+	if _, ok := authorizeRestricted(w, r, h.accessService); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	entries, err := h.auditService.GetEntries(r.Context(), query.Get("entity_type"), query.Get("entity_id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, entries)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"eaglekidz-backend/auth"
+	"eaglekidz-backend/services"
+)
+
+// CallerHeader carries the token of the minister making the request
+const CallerHeader = "X-Minister-Token"
+
+type callerKey struct{}
+
+// Authenticate is middleware that verifies the token sent in CallerHeader and carries the
+// ID of the minister it was issued to. Requests without a token continue anonymously;
+// requests with an invalid or expired token are rejected.
+func Authenticate(tokens *auth.Tokens) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := strings.TrimSpace(r.Header.Get(CallerHeader))
+			if token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ministerID, err := tokens.Verify(token, time.Now())
+			if err != nil {
+				writeError(w, r, services.NewError(services.ErrUnauthenticated, services.CodeUnauthenticated, err.Error()))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), callerKey{}, ministerID)))
+		})
+	}
+}
+
+// callerID returns the ID of the minister whose token was sent with the request, if any
+func callerID(r *http.Request) string {
+	ministerID, _ := r.Context().Value(callerKey{}).(string)
+	return ministerID
+}
+
+// authorizeRestricted checks that the caller may see restricted data and returns a
+// context carrying the caller. It writes the error response when they may not.
+func authorizeRestricted(w http.ResponseWriter, r *http.Request, accessService *services.AccessService) (context.Context, bool) {
+	caller, err := accessService.AuthorizeRestricted(r.Context(), callerID(r))
+	if err != nil {
+		writeError(w, r, err)
+		return nil, false
+	}
+	return services.WithAuthorizedCaller(r.Context(), caller), true
+}
+
+// requestContext returns the request context, carrying the caller when they are
+// authorized for restricted data. Unlike authorizeRestricted it never rejects the
+// request; services redact protected fields for unauthorized callers instead.
+func requestContext(r *http.Request, accessService *services.AccessService) context.Context {
+	caller, err := accessService.AuthorizeRestricted(r.Context(), callerID(r))
+	if err != nil {
+		return r.Context()
+	}
+	return services.WithAuthorizedCaller(r.Context(), caller)
+}
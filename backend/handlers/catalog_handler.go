@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"eaglekidz-backend/services"
+)
+
+type CatalogHandler struct {
+	catalogService *services.CatalogService
+	accessService  *services.AccessService
+}
+
+func NewCatalogHandler(catalogService *services.CatalogService, accessService *services.AccessService) *CatalogHandler {
+	return &CatalogHandler{
+		catalogService: catalogService,
+		accessService:  accessService,
+	}
+}
+
+// MigratePeople handles POST /api/v1/admin/catalogs/migrate
+func (h *CatalogHandler) MigratePeople(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	report, err := h.catalogService.MigratePeople(ctx, dryRun)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, report)
+}
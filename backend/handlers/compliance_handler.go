@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+)
+
+type ComplianceHandler struct {
+	complianceService *services.ComplianceService
+}
+
+func NewComplianceHandler(complianceService *services.ComplianceService) *ComplianceHandler {
+	return &ComplianceHandler{
+		complianceService: complianceService,
+	}
+}
+
+// CreateItem handles POST /api/v1/people/{id}/compliance
+func (h *ComplianceHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.CreateComplianceItemRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	item, err := h.complianceService.CreateItem(r.Context(), id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusCreated, item)
+}
+
+// GetMinisterCompliance handles GET /api/v1/people/{id}/compliance
+func (h *ComplianceHandler) GetMinisterCompliance(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	compliance, err := h.complianceService.GetMinisterCompliance(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, compliance)
+}
+
+// UpdateItem handles PUT /api/v1/compliance/{id}
+func (h *ComplianceHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateComplianceItemRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	item, err := h.complianceService.UpdateItem(r.Context(), id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, item)
+}
+
+// DeleteItem handles DELETE /api/v1/compliance/{id}
+func (h *ComplianceHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.complianceService.DeleteItem(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeMessage(w, r, "Compliance item deleted successfully")
+}
+
+// GetExpiring handles GET /api/v1/compliance/expiring
+func (h *ComplianceHandler) GetExpiring(w http.ResponseWriter, r *http.Request) {
+	items, err := h.complianceService.GetFlaggedItems(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, models.APIResponse{
+		Data: items,
+		Meta: map[string]interface{}{"policy": h.complianceService.Policy()},
+	})
+}
+
+// FlagExpiring handles POST /api/v1/compliance/flag-expiring
+func (h *ComplianceHandler) FlagExpiring(w http.ResponseWriter, r *http.Request) {
+	items, err := h.complianceService.FlagExpiring(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, models.APIResponse{Data: items, Message: fmt.Sprintf("Flagged %d expiring items", len(items))})
+}
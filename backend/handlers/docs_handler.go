@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"eaglekidz-backend/apidoc"
+
+	"github.com/gorilla/mux"
+)
+
+type DocsHandler struct {
+	docs   *apidoc.Docs
+	router *mux.Router
+
+	// The document is built on first use, once every route has been registered
+	once sync.Once
+	spec []byte
+	err  error
+}
+
+func NewDocsHandler(docs *apidoc.Docs, router *mux.Router) *DocsHandler {
+	return &DocsHandler{
+		docs:   docs,
+		router: router,
+	}
+}
+
+// GetSpec handles GET /api/v1/openapi.json
+func (h *DocsHandler) GetSpec(w http.ResponseWriter, r *http.Request) {
+	h.once.Do(func() {
+		var doc *apidoc.Document
+		if doc, h.err = h.docs.Document(h.router); h.err == nil {
+			h.spec, h.err = json.MarshalIndent(doc, "", "  ")
+		}
+	})
+	if h.err != nil {
+		writeError(w, r, h.err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.spec)
+}
+
+// GetPage handles GET /api/v1/docs
+func (h *DocsHandler) GetPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(apidoc.Page)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"eaglekidz-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EncryptionHandler struct {
+	peopleService   *services.PeopleService
+	medicalService  *services.MedicalService
+	incidentService *services.IncidentService
+	auditService    *services.AuditService
+	accessService   *services.AccessService
+}
+
+func NewEncryptionHandler(peopleService *services.PeopleService, medicalService *services.MedicalService, incidentService *services.IncidentService, auditService *services.AuditService, accessService *services.AccessService) *EncryptionHandler {
+	return &EncryptionHandler{
+		peopleService:   peopleService,
+		medicalService:  medicalService,
+		incidentService: incidentService,
+		auditService:    auditService,
+		accessService:   accessService,
+	}
+}
+
+// RotateKeys handles POST /api/v1/admin/encryption/rotate
+func (h *EncryptionHandler) RotateKeys(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	people, err := h.peopleService.RotateEncryption(ctx)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	medical, err := h.medicalService.RotateEncryption(ctx)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	incidents, err := h.incidentService.RotateEncryption(ctx)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	caller, _ := services.AuthorizedCaller(ctx)
+	details := fmt.Sprintf("re-encrypted %d people, %d medical records and %d incidents", people, medical, incidents)
+	if err := h.auditService.Record(ctx, services.AuditActionUpdate, services.AuditEntityEncryption, primitive.NilObjectID, caller.ID.Hex(), details); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, map[string]int{
+		"people":       people,
+		"medical_info": medical,
+		"incidents":    incidents,
+	})
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+)
+
+type FollowUpHandler struct {
+	followUpService *services.FollowUpService
+	accessService   *services.AccessService
+	criteria        models.AbsenteeCriteria
+}
+
+// NewFollowUpHandler creates a handler whose detection endpoint falls back to the given criteria
+func NewFollowUpHandler(followUpService *services.FollowUpService, accessService *services.AccessService, criteria models.AbsenteeCriteria) *FollowUpHandler {
+	return &FollowUpHandler{
+		followUpService: followUpService,
+		accessService:   accessService,
+		criteria:        criteria,
+	}
+}
+
+// DetectAbsentees handles POST /api/v1/follow-ups/detect
+func (h *FollowUpHandler) DetectAbsentees(w http.ResponseWriter, r *http.Request) {
+	criteria := h.criteria
+	query := r.URL.Query()
+	for _, param := range []struct {
+		name   string
+		target *int
+	}{
+		{"n", &criteria.MinAttended},
+		{"m", &criteria.LookbackWeeks},
+		{"k", &criteria.MissedWeeks},
+	} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			writeError(w, r, invalidRequest("'%s' must be a number", param.name))
+			return
+		}
+		*param.target = parsed
+	}
+
+	if err := services.ValidateAbsenteeCriteria(criteria); err != nil {
+		writeError(w, r, invalidRequest("%v", err))
+		return
+	}
+
+	followUps, err := h.followUpService.DetectAbsentees(requestContext(r, h.accessService), criteria)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, models.APIResponse{
+		Data: followUps,
+		Meta: map[string]interface{}{"criteria": criteria},
+	})
+}
+
+// GetFollowUps handles GET /api/v1/follow-ups
+func (h *FollowUpHandler) GetFollowUps(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status != "" && status != models.FollowUpStatusOpen && status != models.FollowUpStatusContacted {
+		writeError(w, r, invalidRequest("Status must be 'open' or 'contacted'"))
+		return
+	}
+
+	followUps, err := h.followUpService.GetFollowUps(requestContext(r, h.accessService), status)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, followUps)
+}
+
+// MarkContacted handles PUT /api/v1/follow-ups/{id}/contacted
+func (h *FollowUpHandler) MarkContacted(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.MarkContactedRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	followUp, err := h.followUpService.MarkContacted(requestContext(r, h.accessService), id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, followUp)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"eaglekidz-backend/database"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestMain points the services at a MongoDB server that is never reached, so handler
+// tests cover what happens before a request reaches the database; anything that does
+// reach it fails fast with an internal error.
+func TestMain(m *testing.M) {
+	client, err := mongo.Connect(context.Background(), options.Client().
+		ApplyURI("mongodb://127.0.0.1:1").
+		SetServerSelectionTimeout(50*time.Millisecond))
+	if err != nil {
+		panic(err)
+	}
+	database.Client = client
+	database.Database = client.Database(database.DatabaseName + "_test")
+	os.Exit(m.Run())
+}
+
+// serve sends a request with a JSON body to handler, registered at path, as the given
+// minister, or anonymously when ministerID is empty
+func serve(handler http.HandlerFunc, method, path, target, body, ministerID string) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc(path, handler).Methods(method)
+
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if ministerID != "" {
+		r = r.WithContext(context.WithValue(r.Context(), callerKey{}, ministerID))
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
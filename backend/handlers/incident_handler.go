@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"net/http"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IncidentHandler struct {
+	incidentService *services.IncidentService
+	accessService   *services.AccessService
+}
+
+func NewIncidentHandler(incidentService *services.IncidentService, accessService *services.AccessService) *IncidentHandler {
+	return &IncidentHandler{
+		incidentService: incidentService,
+		accessService:   accessService,
+	}
+}
+
+// CreateIncident handles POST /api/v1/incidents
+func (h *IncidentHandler) CreateIncident(w http.ResponseWriter, r *http.Request) {
+	// Any minister can report an incident; reading incidents back is restricted
+	ctx := requestContext(r, h.accessService)
+
+	var req models.CreateIncidentRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	incident, err := h.incidentService.CreateIncident(ctx, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusCreated, incident)
+}
+
+// GetIncidents handles GET /api/v1/incidents
+func (h *IncidentHandler) GetIncidents(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.IncidentFilter{FollowUpStatus: query.Get("follow_up_status")}
+	for _, param := range []struct {
+		name   string
+		target **primitive.ObjectID
+	}{
+		{"week_id", &filter.WeekID},
+		{"child_id", &filter.ChildID},
+	} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		objID, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			writeError(w, r, services.NewError(services.ErrInvalid, services.CodeInvalidID, "'%s' must be a valid ID", param.name))
+			return
+		}
+		*param.target = &objID
+	}
+
+	incidents, err := h.incidentService.GetIncidents(ctx, filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, incidents)
+}
+
+// GetIncident handles GET /api/v1/incidents/{id}
+func (h *IncidentHandler) GetIncident(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	incident, err := h.incidentService.GetIncident(ctx, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, incident)
+}
+
+// UpdateIncident handles PUT /api/v1/incidents/{id}
+func (h *IncidentHandler) UpdateIncident(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateIncidentRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	incident, err := h.incidentService.UpdateIncident(ctx, id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, incident)
+}
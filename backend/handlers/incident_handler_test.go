@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+)
+
+func TestIncidentAccess(t *testing.T) {
+	peopleService := services.NewPeopleService(database.Database, nil, services.NewAgeGroupService(services.DefaultPromotionDay), services.NewRoleService())
+	accessService := services.NewAccessService(peopleService, services.DefaultRestrictedAccessRoles)
+	weekService := services.NewWeekService(services.NewComplianceService(peopleService, models.CompliancePolicy{}), peopleService)
+	incidentService := services.NewIncidentService(weekService, peopleService, services.NewAuditService(), nil)
+	h := NewIncidentHandler(incidentService, accessService)
+	const body = `{"week_id": "65a1b2c3d4e5f60718293a4c", "service_name": "Morning", "service_time": "09:00",
+		"reported_by": "65a1b2c3d4e5f60718293a4b", "child_ids": ["65a1b2c3d4e5f60718293a4d"], "type": "injury",
+		"description": {"summary": "Fell over"}}`
+
+	// Reporting needs no restricted access, so a request without a caller reaches the database
+	if w := serve(h.CreateIncident, "POST", "/incidents", "/incidents", body, ""); w.Code != http.StatusInternalServerError {
+		t.Errorf("report without caller: status = %d, want %d: %s", w.Code, http.StatusInternalServerError, w.Body)
+	}
+	if w := serve(h.CreateIncident, "POST", "/incidents", "/incidents", `{"week_id": "65a1b2c3d4e5f60718293a4c"}`, ""); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("report without reporter: status = %d, want %d: %s", w.Code, http.StatusUnprocessableEntity, w.Body)
+	}
+
+	if w := serve(h.GetIncidents, "GET", "/incidents", "/incidents", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("list without caller: status = %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+	}
+	if w := serve(h.GetIncident, "GET", "/incidents/{id}", "/incidents/65a1b2c3d4e5f60718293a4e", "", "65a1b2c3d4e5f60718293a4b"); w.Code != http.StatusForbidden {
+		t.Errorf("get as unauthorized caller: status = %d, want %d: %s", w.Code, http.StatusForbidden, w.Body)
+	}
+}
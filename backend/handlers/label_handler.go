@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+)
+
+type LabelHandler struct {
+	labelService  *services.LabelService
+	accessService *services.AccessService
+}
+
+func NewLabelHandler(labelService *services.LabelService, accessService *services.AccessService) *LabelHandler {
+	return &LabelHandler{
+		labelService:  labelService,
+		accessService: accessService,
+	}
+}
+
+// GetLayouts handles GET /api/v1/labels/layouts
+func (h *LabelHandler) GetLayouts(w http.ResponseWriter, r *http.Request) {
+	layouts := make([]services.LabelLayout, 0, len(services.LabelLayouts))
+	for _, layout := range services.LabelLayouts {
+		layouts = append(layouts, layout)
+	}
+	sort.Slice(layouts, func(i, j int) bool { return layouts[i].Name < layouts[j].Name })
+
+	writeResponse(w, r, http.StatusOK, models.APIResponse{
+		Data: layouts,
+		Meta: map[string]interface{}{"default": services.DefaultLabelLayout},
+	})
+}
+
+// GetAttendanceLabels handles GET /api/v1/attendance/{id}/labels
+func (h *LabelHandler) GetAttendanceLabels(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	pdf, err := h.labelService.RenderAttendanceLabels(ctx, id, r.URL.Query().Get("layout"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writePDF(w, fmt.Sprintf("labels-%s.pdf", id), pdf)
+}
+
+// GetWeekLabels handles GET /api/v1/weeks/{weekId}/labels
+func (h *LabelHandler) GetWeekLabels(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	weekID := vars["weekId"]
+	query := r.URL.Query()
+
+	pdf, err := h.labelService.RenderWeekLabels(ctx, weekID, query.Get("service_name"), query.Get("service_time"), query.Get("layout"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writePDF(w, fmt.Sprintf("labels-week-%s.pdf", weekID), pdf)
+}
+
+// writePDF sends a PDF inline so browsers open the print preview
+func writePDF(w http.ResponseWriter, filename string, pdf []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	w.Write(pdf)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MedicalHandler struct {
+	medicalService *services.MedicalService
+	accessService  *services.AccessService
+}
+
+func NewMedicalHandler(medicalService *services.MedicalService, accessService *services.AccessService) *MedicalHandler {
+	return &MedicalHandler{
+		medicalService: medicalService,
+		accessService:  accessService,
+	}
+}
+
+// GetMedicalInfo handles GET /api/v1/people/{id}/medical
+func (h *MedicalHandler) GetMedicalInfo(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	info, err := h.medicalService.GetMedicalInfo(ctx, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, info)
+}
+
+// UpsertMedicalInfo handles PUT /api/v1/people/{id}/medical
+func (h *MedicalHandler) UpsertMedicalInfo(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpsertMedicalInfoRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	info, err := h.medicalService.UpsertMedicalInfo(ctx, id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, info)
+}
+
+// GetAlertFlags handles GET /api/v1/people/{id}/alerts
+func (h *MedicalHandler) GetAlertFlags(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		writeError(w, r, services.NewError(services.ErrInvalid, services.CodeInvalidID, "invalid ID format"))
+		return
+	}
+
+	flags, err := h.medicalService.GetAlertFlags(requestContext(r, h.accessService), []primitive.ObjectID{objID})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	personFlags := flags[objID]
+	if personFlags == nil {
+		personFlags = []models.AlertFlag{}
+	}
+
+	writeData(w, r, http.StatusOK, personFlags)
+}
@@ -1,259 +1,271 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
 	"net/http"
 
 	"eaglekidz-backend/models"
 	"eaglekidz-backend/services"
 
 	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type PeopleHandler struct {
-	peopleService *services.PeopleService
+	peopleService  *services.PeopleService
+	medicalService *services.MedicalService
+	accessService  *services.AccessService
 }
 
-func NewPeopleHandler(peopleService *services.PeopleService) *PeopleHandler {
+func NewPeopleHandler(peopleService *services.PeopleService, medicalService *services.MedicalService, accessService *services.AccessService) *PeopleHandler {
 	return &PeopleHandler{
-		peopleService: peopleService,
+		peopleService:  peopleService,
+		medicalService: medicalService,
+		accessService:  accessService,
 	}
 }
 
-// CreatePeople handles POST /api/v1/people
-func (h *PeopleHandler) CreatePeople(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+// attachAlerts fills in the medical alert flags of the children in people; they only name
+// allergens, medications, conditions and needs when ctx carries an authorized caller
+func (h *PeopleHandler) attachAlerts(ctx context.Context, people []models.People) error {
+	var childIDs []primitive.ObjectID
+	for _, person := range people {
+		if person.Type == "children" {
+			childIDs = append(childIDs, person.ID)
+		}
+	}
 
-	var req models.CreatePeopleRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
+	flags, err := h.medicalService.GetAlertFlags(ctx, childIDs)
+	if err != nil {
+		return err
 	}
 
-	// Validate type
-	if req.Type != "minister" && req.Type != "children" {
-		http.Error(w, "Type must be 'minister' or 'children'", http.StatusBadRequest)
-		return
+	for i := range people {
+		people[i].Alerts = flags[people[i].ID]
 	}
+	return nil
+}
 
-	people, err := h.peopleService.CreatePeople(req)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// CreatePeople handles POST /api/v1/people
+func (h *PeopleHandler) CreatePeople(w http.ResponseWriter, r *http.Request) {
+	var req models.CreatePeopleRequest
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
-	response := map[string]interface{}{
-		"message": "Person created successfully",
-		"status":  "success",
-		"data":    people,
+	people, err := h.peopleService.CreatePeople(requestContext(r, h.accessService), req)
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	writePeopleResponse(w, r, http.StatusCreated, "Person created successfully", people)
 }
 
-// GetAllPeople handles GET /api/v1/people
+// GetAllPeople handles GET /api/v1/people, filtered by ?status= (default active) and optionally ?phone=
 func (h *PeopleHandler) GetAllPeople(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	people, err := h.peopleService.GetAllPeople()
+	// Children's protected fields are encrypted, so phone lookups go through the
+	// service rather than a client-side filter. A lookup tells whether a number
+	// belongs to a child even when the result is redacted, so it is restricted.
+	query := r.URL.Query()
+	var ctx context.Context
+	var people []models.People
+	var err error
+	if phone := query.Get("phone"); phone != "" {
+		var ok bool
+		if ctx, ok = authorizeRestricted(w, r, h.accessService); !ok {
+			return
+		}
+		people, err = h.peopleService.GetPeopleByPhone(ctx, phone, query.Get("status"))
+	} else {
+		ctx = requestContext(r, h.accessService)
+		people, err = h.peopleService.GetAllPeople(ctx, query.Get("status"))
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"message": "People retrieved successfully",
-		"status":  "success",
-		"data":    people,
+	if err := h.attachAlerts(ctx, people); err != nil {
+		writeError(w, r, err)
+		return
 	}
 
-	json.NewEncoder(w).Encode(response)
+	writePeopleResponse(w, r, http.StatusOK, "People retrieved successfully", people)
 }
 
 // GetPeopleByType handles GET /api/v1/people/type/{type}
 func (h *PeopleHandler) GetPeopleByType(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
 	vars := mux.Vars(r)
 	peopleType := vars["type"]
 
 	// Validate type
 	if peopleType != "minister" && peopleType != "children" {
-		http.Error(w, "Type must be 'minister' or 'children'", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("Type must be 'minister' or 'children'"))
 		return
 	}
 
-	people, err := h.peopleService.GetPeopleByType(peopleType)
+	ctx := requestContext(r, h.accessService)
+	people, err := h.peopleService.GetPeopleByType(ctx, peopleType, r.URL.Query().Get("status"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"message": "People retrieved successfully",
-		"status":  "success",
-		"data":    people,
+	if err := h.attachAlerts(ctx, people); err != nil {
+		writeError(w, r, err)
+		return
 	}
 
-	json.NewEncoder(w).Encode(response)
+	writePeopleResponse(w, r, http.StatusOK, "People retrieved successfully", people)
 }
 
 // GetPeople handles GET /api/v1/people/{id}
 func (h *PeopleHandler) GetPeople(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	people, err := h.peopleService.GetPeopleByID(id)
+	ctx := requestContext(r, h.accessService)
+	people, err := h.peopleService.GetPeopleByID(ctx, id)
 	if err != nil {
-		if err.Error() == "person not found" {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"message": "Person retrieved successfully",
-		"status":  "success",
-		"data":    people,
+	single := []models.People{*people}
+	if err := h.attachAlerts(ctx, single); err != nil {
+		writeError(w, r, err)
+		return
 	}
+	people = &single[0]
 
-	json.NewEncoder(w).Encode(response)
+	writePeopleResponse(w, r, http.StatusOK, "Person retrieved successfully", people)
 }
 
-// UpdatePeople handles PUT /api/v1/people/{id}
+// UpdatePeople handles PUT /api/v1/people/{id}. Roles grant restricted access, so only
+// an authorized caller may change them.
 func (h *PeopleHandler) UpdatePeople(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
 	vars := mux.Vars(r)
 	id := vars["id"]
 
 	var req models.UpdatePeopleRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
-	// Validate type if provided
-	if req.Type != nil && *req.Type != "minister" && *req.Type != "children" {
-		http.Error(w, "Type must be 'minister' or 'children'", http.StatusBadRequest)
-		return
+	ctx := requestContext(r, h.accessService)
+	if req.Roles != nil {
+		var ok bool
+		if ctx, ok = authorizeRestricted(w, r, h.accessService); !ok {
+			return
+		}
 	}
 
-	people, err := h.peopleService.UpdatePeople(id, req)
+	people, err := h.peopleService.UpdatePeople(ctx, id, req)
 	if err != nil {
-		if err.Error() == "person not found" {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"message": "Person updated successfully",
-		"status":  "success",
-		"data":    people,
+	writePeopleResponse(w, r, http.StatusOK, "Person updated successfully", people)
+}
+
+// ChangeStatus handles PUT /api/v1/people/{id}/status. Only active ministers keep
+// restricted access, so only an authorized caller may change a status.
+func (h *PeopleHandler) ChangeStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
 	}
 
-	json.NewEncoder(w).Encode(response)
+	var req models.ChangeStatusRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	people, err := h.peopleService.ChangeStatus(ctx, id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writePeopleResponse(w, r, http.StatusOK, "Person status updated successfully", people)
 }
 
 // DeletePeople handles DELETE /api/v1/people/{id}
 func (h *PeopleHandler) DeletePeople(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.peopleService.DeletePeople(id)
+	err := h.peopleService.DeletePeople(requestContext(r, h.accessService), id)
 	if err != nil {
-		if err.Error() == "person not found" {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"message": "Person deleted successfully",
-		"status":  "success",
-	}
-
-	json.NewEncoder(w).Encode(response)
+	writePeopleResponse(w, r, http.StatusOK, "Person deleted successfully", nil)
 }
 
 // GetDeletedPeople handles GET /api/v1/people/deleted
 func (h *PeopleHandler) GetDeletedPeople(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	people, err := h.peopleService.GetDeletedPeople()
+	people, err := h.peopleService.GetDeletedPeople(requestContext(r, h.accessService))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"message": "Deleted people retrieved successfully",
-		"status":  "success",
-		"data":    people,
-	}
-
-	json.NewEncoder(w).Encode(response)
+	writePeopleResponse(w, r, http.StatusOK, "Deleted people retrieved successfully", people)
 }
 
 // HardDeletePeople handles DELETE /api/v1/people/{id}/permanent
 func (h *PeopleHandler) HardDeletePeople(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.peopleService.HardDeletePeople(id)
+	err := h.peopleService.HardDeletePeople(requestContext(r, h.accessService), id)
 	if err != nil {
-		if err.Error() == "person not found or not deleted" {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"message": "Person permanently deleted successfully",
-		"status":  "success",
-	}
-
-	json.NewEncoder(w).Encode(response)
+	writePeopleResponse(w, r, http.StatusOK, "Person permanently deleted successfully", nil)
 }
 
 // RestorePeople handles PUT /api/v1/people/{id}/restore
 func (h *PeopleHandler) RestorePeople(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	people, err := h.peopleService.RestorePeople(id)
+	people, err := h.peopleService.RestorePeople(requestContext(r, h.accessService), id)
 	if err != nil {
-		if err.Error() == "person not found or not deleted" {
-			http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, err)
+		return
+	}
+
+	writePeopleResponse(w, r, http.StatusOK, "Person restored successfully", people)
+}
+
+// writePeopleResponse writes a successful response. The legacy format wrapped people
+// as {"message", "status": "success", "data"}.
+func writePeopleResponse(w http.ResponseWriter, r *http.Request, status int, message string, data interface{}) {
+	if !Legacy(r) {
+		if data == nil {
+			writeMessage(w, r, message)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeData(w, r, status, data)
 		return
 	}
 
 	response := map[string]interface{}{
-		"message": "Person restored successfully",
+		"message": message,
 		"status":  "success",
-		"data":    people,
 	}
-
-	json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+	if data != nil {
+		response["data"] = data
+	}
+	writeJSON(w, status, response)
+}
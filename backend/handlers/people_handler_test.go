@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/services"
+)
+
+func newTestPeopleHandler() *PeopleHandler {
+	ageGroupService := services.NewAgeGroupService(services.DefaultPromotionDay)
+	peopleService := services.NewPeopleService(database.Database, nil, ageGroupService, services.NewRoleService())
+	medicalService := services.NewMedicalService(peopleService, services.NewAuditService(), nil)
+	accessService := services.NewAccessService(peopleService, services.DefaultRestrictedAccessRoles)
+	return NewPeopleHandler(peopleService, medicalService, accessService)
+}
+
+func TestUpdatePeopleRoles(t *testing.T) {
+	h := newTestPeopleHandler()
+	const caller = "65a1b2c3d4e5f60718293a4b"
+
+	tests := []struct {
+		name     string
+		body     string
+		caller   string
+		wantCode int
+	}{
+		// Roles need an authorized caller, checked before the person is looked up
+		{"roles without caller", `{"roles": ["Leader"]}`, "", http.StatusUnauthorized},
+		{"roles from unauthorized caller", `{"roles": ["Leader"]}`, caller, http.StatusForbidden},
+		{"clearing roles without caller", `{"roles": []}`, "", http.StatusUnauthorized},
+		// Other fields go on to the service, which rejects the ID
+		{"other fields without caller", `{"first_name": "Sam"}`, "", http.StatusBadRequest},
+		{"other fields from unauthorized caller", `{"first_name": "Sam"}`, caller, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h.UpdatePeople, "PUT", "/people/{id}", "/people/not-an-id", tt.body, tt.caller)
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+		})
+	}
+}
+
+func TestChangeStatusIsRestricted(t *testing.T) {
+	h := newTestPeopleHandler()
+	body := `{"status": "inactive", "reason": "Moved away"}`
+
+	tests := []struct {
+		name     string
+		caller   string
+		wantCode int
+	}{
+		{"without caller", "", http.StatusUnauthorized},
+		{"unauthorized caller", "65a1b2c3d4e5f60718293a4b", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h.ChangeStatus, "PUT", "/people/{id}/status", "/people/65a1b2c3d4e5f60718293a4c/status", body, tt.caller)
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+		})
+	}
+}
+
+func TestCreatePeopleCatalogFields(t *testing.T) {
+	h := newTestPeopleHandler()
+	const person = `"first_name": "Sam", "last_name": "Lee", "phone": "+44 20 7946 0958"`
+
+	tests := []struct {
+		name     string
+		body     string
+		rejected bool
+	}{
+		{"child with date of birth and no roles or age group", `{` + person + `, "type": "children", "date_of_birth": "2018-03-01T00:00:00Z"}`, false},
+		{"child with age group and no roles", `{` + person + `, "type": "children", "age_group": ["Juniors"]}`, false},
+		{"child without date of birth or age group", `{` + person + `, "type": "children", "roles": []}`, true},
+		{"minister with roles and no age group", `{` + person + `, "type": "minister", "roles": ["Leader"]}`, false},
+		{"minister without roles", `{` + person + `, "type": "minister", "age_group": ["Juniors"]}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h.CreatePeople, "POST", "/people", "/people", tt.body, "")
+			// Accepted requests go on to the database, which is unreachable in tests
+			rejected := w.Code == http.StatusBadRequest || w.Code == http.StatusUnprocessableEntity
+			if rejected != tt.rejected {
+				t.Errorf("status = %d, want rejected %v: %s", w.Code, tt.rejected, w.Body)
+			}
+		})
+	}
+}
+
+func TestPhoneLookupIsRestricted(t *testing.T) {
+	h := newTestPeopleHandler()
+
+	tests := []struct {
+		name     string
+		target   string
+		caller   string
+		wantCode int
+	}{
+		{"lookup without caller", "/people?phone=%2B442079460958", "", http.StatusUnauthorized},
+		{"lookup from unauthorized caller", "/people?phone=%2B442079460958", "65a1b2c3d4e5f60718293a4b", http.StatusForbidden},
+		// Listing people stays open and reaches the database
+		{"list without caller", "/people", "", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h.GetAllPeople, "GET", "/people", tt.target, "", tt.caller)
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+		})
+	}
+}
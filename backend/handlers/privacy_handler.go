@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+)
+
+type PrivacyHandler struct {
+	privacyService *services.PrivacyService
+	accessService  *services.AccessService
+}
+
+func NewPrivacyHandler(privacyService *services.PrivacyService, accessService *services.AccessService) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+		accessService:  accessService,
+	}
+}
+
+// ExportPerson handles GET /api/v1/people/{id}/export
+func (h *PrivacyHandler) ExportPerson(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	export, err := h.privacyService.ExportPerson(ctx, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "zip" || strings.Contains(r.Header.Get("Accept"), "application/zip") {
+		archive, err := services.BuildExportArchive(export)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "person-"+id+".zip"))
+		w.Write(archive)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, export)
+}
+
+// ErasePerson handles POST /api/v1/people/{id}/erase
+func (h *PrivacyHandler) ErasePerson(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// The body is optional; it only carries the reason for the erasure
+	var req models.EraseRequest
+	if !decodeOptionalRequest(w, r, &req) {
+		return
+	}
+
+	tombstone, err := h.privacyService.ErasePerson(ctx, id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, tombstone)
+}
+
+// GetErasures handles GET /api/v1/erasures
+func (h *PrivacyHandler) GetErasures(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	tombstones, err := h.privacyService.GetErasures(ctx)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, tombstones)
+}
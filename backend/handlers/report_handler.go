@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+)
+
+type ReportHandler struct {
+	reportService *services.ReportService
+}
+
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// GetWeeklyAttendance handles GET /api/v1/reports/attendance/weekly
+func (h *ReportHandler) GetWeeklyAttendance(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseReportFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	reports, err := h.reportService.GetWeeklyAttendance(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := make([][]string, len(reports))
+		for i, report := range reports {
+			rows[i] = append([]string{
+				report.WeekID.Hex(),
+				report.StartTime.Format(reportDateLayout),
+				report.EndTime.Format(reportDateLayout),
+				strconv.Itoa(report.Total),
+				strconv.Itoa(report.FirstTime),
+			}, trendRow(report.AttendanceTrend)...)
+		}
+		writeCSV(w, "weekly-attendance.csv",
+			append([]string{"week_id", "start_time", "end_time", "total", "first_time"}, trendHeader...),
+			rows)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, reports)
+}
+
+// GetServiceAttendance handles GET /api/v1/reports/attendance/services
+func (h *ReportHandler) GetServiceAttendance(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseReportFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	reports, err := h.reportService.GetServiceAttendance(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := make([][]string, len(reports))
+		for i, report := range reports {
+			rows[i] = append([]string{
+				report.WeekID.Hex(),
+				report.StartTime.Format(reportDateLayout),
+				report.ServiceName,
+				report.ServiceTime,
+				strconv.Itoa(report.Total),
+				strconv.Itoa(report.FirstTime),
+			}, trendRow(report.AttendanceTrend)...)
+		}
+		writeCSV(w, "service-attendance.csv",
+			append([]string{"week_id", "start_time", "service_name", "service_time", "total", "first_time"}, trendHeader...),
+			rows)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, reports)
+}
+
+// GetAgeGroupAttendance handles GET /api/v1/reports/attendance/age-groups
+func (h *ReportHandler) GetAgeGroupAttendance(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseReportFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	reports, err := h.reportService.GetAgeGroupAttendance(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := make([][]string, len(reports))
+		for i, report := range reports {
+			rows[i] = append([]string{
+				report.WeekID.Hex(),
+				report.StartTime.Format(reportDateLayout),
+				report.AgeGroup,
+				strconv.Itoa(report.Total),
+				strconv.Itoa(report.FirstTime),
+			}, trendRow(report.AttendanceTrend)...)
+		}
+		writeCSV(w, "age-group-attendance.csv",
+			append([]string{"week_id", "start_time", "age_group", "total", "first_time"}, trendHeader...),
+			rows)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, reports)
+}
+
+// GetWeeklyServiceRatings handles GET /api/v1/reports/reviews/services/weekly
+func (h *ReportHandler) GetWeeklyServiceRatings(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseReportFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	reports, err := h.reportService.GetWeeklyServiceRatings(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := make([][]string, len(reports))
+		for i, report := range reports {
+			rows[i] = append([]string{
+				report.WeekID.Hex(),
+				report.StartTime.Format(reportDateLayout),
+			}, ratingRow(report)...)
+		}
+		writeCSV(w, "weekly-service-ratings.csv",
+			append([]string{"week_id", "start_time"}, ratingHeader...),
+			rows)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, reports)
+}
+
+// GetServiceRatings handles GET /api/v1/reports/reviews/services
+func (h *ReportHandler) GetServiceRatings(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseReportFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	reports, err := h.reportService.GetServiceRatings(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := make([][]string, len(reports))
+		for i, report := range reports {
+			rows[i] = ratingRow(report)
+		}
+		writeCSV(w, "service-ratings.csv", ratingHeader, rows)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, reports)
+}
+
+const reportDateLayout = "2006-01-02"
+
+// trendHeader names the columns written by trendRow
+var trendHeader = []string{"rolling_average", "change", "change_percent"}
+
+// trendRow formats an attendance trend as CSV columns
+func trendRow(trend models.AttendanceTrend) []string {
+	return []string{
+		strconv.FormatFloat(trend.RollingAverage, 'f', 2, 64),
+		formatOptionalInt(trend.Change),
+		formatOptionalFloat(trend.ChangePercent),
+	}
+}
+
+// ratingHeader names the columns written by ratingRow
+var ratingHeader = []string{"service_name", "service_time", "reviews", "engagement", "preparation", "punctuality", "safety", "overall"}
+
+// ratingRow formats the service and average ratings of a rating report as CSV columns
+func ratingRow(report models.ServiceRatingReport) []string {
+	return []string{
+		report.ServiceName,
+		report.ServiceTime,
+		strconv.Itoa(report.Reviews),
+		formatOptionalFloat(report.Engagement),
+		formatOptionalFloat(report.Preparation),
+		formatOptionalFloat(report.Punctuality),
+		formatOptionalFloat(report.Safety),
+		formatOptionalFloat(report.Overall),
+	}
+}
+
+// parseReportFilter reads the from, to and window query parameters.
+// Dates may be given as YYYY-MM-DD or RFC 3339.
+func parseReportFilter(r *http.Request) (models.AttendanceReportFilter, error) {
+	query := r.URL.Query()
+	var filter models.AttendanceReportFilter
+
+	for _, param := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseReportDate(value)
+		if err != nil {
+			return filter, invalidRequest("'%s' must be a date (YYYY-MM-DD or RFC 3339)", param.name)
+		}
+		*param.target = &parsed
+	}
+
+	if value := query.Get("window"); value != "" {
+		window, err := strconv.Atoi(value)
+		if err != nil || window < 1 {
+			return filter, invalidRequest("'window' must be a positive number of weeks")
+		}
+		filter.Window = window
+	}
+
+	return filter, nil
+}
+
+func parseReportDate(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse(reportDateLayout, value)
+}
+
+// wantsCSV reports whether the caller asked for CSV with ?format=csv or an Accept header
+func wantsCSV(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "csv" || r.Header.Get("Accept") == "text/csv"
+}
+
+// writeCSV writes a CSV attachment with a header row
+func writeCSV(w http.ResponseWriter, filename string, header []string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	writer := csv.NewWriter(w)
+	writer.Write(header)
+	writer.WriteAll(rows)
+}
+
+func formatOptionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', 2, 64)
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+	"eaglekidz-backend/validation"
+)
+
+// Response formats. Every endpoint answers with the models.APIResponse envelope; the
+// legacy format keeps the shapes used before it, with plain-text errors, for clients
+// that have not moved over yet.
+const (
+	FormatEnvelope = "envelope"
+	FormatLegacy   = "legacy"
+)
+
+// FormatHeader lets a client pick the response format of a single request
+const FormatHeader = "X-Response-Format"
+
+type formatKey struct{}
+
+// ResponseFormat is middleware that picks the response format of each request: the one
+// named in FormatHeader, or defaultFormat when the header is missing or unknown
+func ResponseFormat(defaultFormat string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			format := strings.ToLower(strings.TrimSpace(r.Header.Get(FormatHeader)))
+			if format != FormatEnvelope && format != FormatLegacy {
+				format = defaultFormat
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), formatKey{}, format)))
+		})
+	}
+}
+
+// Legacy reports whether a request asked for the legacy response format
+func Legacy(r *http.Request) bool {
+	format, _ := r.Context().Value(formatKey{}).(string)
+	return format == FormatLegacy
+}
+
+// writeData writes a successful response carrying data
+func writeData(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	writeResponse(w, r, status, models.APIResponse{Data: data})
+}
+
+// writeMessage writes a successful response confirming an action, such as a delete
+func writeMessage(w http.ResponseWriter, r *http.Request, message string) {
+	writeResponse(w, r, http.StatusOK, models.APIResponse{Message: message})
+}
+
+// writeResponse writes a successful response. In the legacy format the envelope's
+// fields, and its meta entries, are written at the top level.
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, body models.APIResponse) {
+	body.Success = true
+	if !Legacy(r) {
+		writeJSON(w, status, body)
+		return
+	}
+
+	response := map[string]interface{}{"success": true}
+	if body.Data != nil {
+		response["data"] = body.Data
+	}
+	if body.Message != "" {
+		response["message"] = body.Message
+	}
+	for key, value := range body.Meta {
+		response[key] = value
+	}
+	writeJSON(w, status, response)
+}
+
+// writeError writes the response for an error returned by a service or a request check.
+// Service errors keep their code and message; any other error is logged and reported
+// as an internal error without its details.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status, apiErr := toAPIError(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+		if Legacy(r) {
+			// The legacy format always sent the error text
+			apiErr.Message = err.Error()
+		}
+	}
+	writeAPIError(w, r, status, apiErr)
+}
+
+// writeAPIError writes a failed response. The legacy format sends the message as plain
+// text, or field errors in their earlier JSON shape.
+func writeAPIError(w http.ResponseWriter, r *http.Request, status int, apiErr *models.APIError) {
+	if !Legacy(r) {
+		writeJSON(w, status, models.APIResponse{Error: apiErr})
+		return
+	}
+	if len(apiErr.Fields) > 0 {
+		writeJSON(w, status, map[string]interface{}{
+			"success": false,
+			"error":   apiErr.Message,
+			"errors":  apiErr.Fields,
+		})
+		return
+	}
+	http.Error(w, apiErr.Message, status)
+}
+
+// toAPIError maps an error to its HTTP status and API error
+func toAPIError(err error) (int, *models.APIError) {
+	var fieldErrs validation.Errors
+	if errors.As(err, &fieldErrs) {
+		return http.StatusUnprocessableEntity, &models.APIError{
+			Code:    services.CodeValidationFailed,
+			Message: "validation failed",
+			Fields:  fieldErrors(fieldErrs),
+		}
+	}
+
+	var refErr *services.ReferenceError
+	if errors.As(err, &refErr) {
+		return http.StatusUnprocessableEntity, &models.APIError{
+			Code:    services.CodeInvalidReference,
+			Message: refErr.Message,
+			Fields:  []models.FieldError{{Field: refErr.Field, Rule: "reference", Message: refErr.Message}},
+		}
+	}
+
+	var serviceErr *services.Error
+	if errors.As(err, &serviceErr) {
+		return statusOf(serviceErr.Kind), &models.APIError{Code: serviceErr.Code, Message: serviceErr.Message}
+	}
+
+	return http.StatusInternalServerError, &models.APIError{Code: services.CodeInternal, Message: "internal server error"}
+}
+
+// statusOf returns the HTTP status of a kind of service error
+func statusOf(kind error) int {
+	switch kind {
+	case services.ErrNotFound:
+		return http.StatusNotFound
+	case services.ErrInvalid:
+		return http.StatusBadRequest
+	case services.ErrConflict:
+		return http.StatusConflict
+	case services.ErrUnauthenticated:
+		return http.StatusUnauthorized
+	case services.ErrForbidden:
+		return http.StatusForbidden
+	case services.ErrGone:
+		return http.StatusGone
+	case services.ErrUnprocessable:
+		return http.StatusUnprocessableEntity
+	case services.ErrUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// invalidRequest returns the error for a request a handler refuses, such as one with a
+// malformed query parameter
+func invalidRequest(format string, args ...interface{}) error {
+	return services.NewError(services.ErrInvalid, services.CodeInvalidValue, format, args...)
+}
+
+func fieldErrors(errs validation.Errors) []models.FieldError {
+	fields := make([]models.FieldError, len(errs))
+	for i, err := range errs {
+		fields[i] = models.FieldError{Field: err.Field, Rule: err.Rule, Message: err.Message}
+	}
+	return fields
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// NotFound answers requests to unknown routes
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, services.NewError(services.ErrNotFound, services.CodeNotFound, "route not found"))
+}
+
+// MethodNotAllowed answers requests using a method a route does not support
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeAPIError(w, r, http.StatusMethodNotAllowed, &models.APIError{
+		Code:    services.CodeMethodNotAllowed,
+		Message: "method not allowed",
+	})
+}
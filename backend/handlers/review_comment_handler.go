@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+)
+
+type ReviewCommentHandler struct {
+	commentService *services.ReviewCommentService
+}
+
+func NewReviewCommentHandler(commentService *services.ReviewCommentService) *ReviewCommentHandler {
+	return &ReviewCommentHandler{
+		commentService: commentService,
+	}
+}
+
+// CreateComment handles POST /api/v1/reviews/{id}/comments
+func (h *ReviewCommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	reviewID := vars["id"]
+
+	var req models.CreateReviewCommentRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	comment, err := h.commentService.CreateComment(r.Context(), reviewID, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusCreated, comment)
+}
+
+// GetReviewComments handles GET /api/v1/reviews/{id}/comments
+func (h *ReviewCommentHandler) GetReviewComments(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	reviewID := vars["id"]
+
+	comments, err := h.commentService.GetReviewComments(r.Context(), reviewID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, comments)
+}
+
+// GetDeletedComments handles GET /api/v1/reviews/{id}/deleted-comments
+func (h *ReviewCommentHandler) GetDeletedComments(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	reviewID := vars["id"]
+
+	comments, err := h.commentService.GetDeletedComments(r.Context(), reviewID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, comments)
+}
+
+// UpdateComment handles PUT /api/v1/review-comments/{id}
+func (h *ReviewCommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateReviewCommentRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(r.Context(), id, req, callerID(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, comment)
+}
+
+// DeleteComment handles DELETE /api/v1/review-comments/{id}
+func (h *ReviewCommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.commentService.DeleteComment(r.Context(), id, callerID(r)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeMessage(w, r, "Comment deleted successfully")
+}
+
+// RestoreComment handles PUT /api/v1/review-comments/{id}/restore
+func (h *ReviewCommentHandler) RestoreComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	comment, err := h.commentService.RestoreComment(r.Context(), id, callerID(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, models.APIResponse{Data: comment, Message: "Comment restored successfully"})
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"eaglekidz-backend/models"
@@ -14,38 +13,26 @@ type ReviewHandler struct {
 	reviewService *services.ReviewService
 }
 
-func NewReviewHandler() *ReviewHandler {
+func NewReviewHandler(reviewService *services.ReviewService) *ReviewHandler {
 	return &ReviewHandler{
-		reviewService: services.NewReviewService(),
+		reviewService: reviewService,
 	}
 }
 
 // CreateReview handles POST /api/v1/reviews
 func (h *ReviewHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateReviewRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
-	// Validate required fields
-	if req.WeekID == "" {
-		http.Error(w, "Week ID is required", http.StatusBadRequest)
-		return
-	}
-
-	review, err := h.reviewService.CreateReview(r.Context(), req)
+	review, err := h.reviewService.CreateReview(r.Context(), req, callerID(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    review,
-	})
+	writeData(w, r, http.StatusCreated, review)
 }
 
 // GetReview handles GET /api/v1/reviews/{id}
@@ -53,21 +40,13 @@ func (h *ReviewHandler) GetReview(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	review, err := h.reviewService.GetReviewByID(r.Context(), id)
+	review, err := h.reviewService.GetVisibleReview(r.Context(), id, callerID(r))
 	if err != nil {
-		if err.Error() == "review not found" {
-			http.Error(w, "Review not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    review,
-	})
+	writeData(w, r, http.StatusOK, review)
 }
 
 // GetReviewsByWeek handles GET /api/v1/weeks/{weekId}/reviews
@@ -75,32 +54,24 @@ func (h *ReviewHandler) GetReviewsByWeek(w http.ResponseWriter, r *http.Request)
 	vars := mux.Vars(r)
 	weekID := vars["weekId"]
 
-	reviews, err := h.reviewService.GetReviewsByWeekID(r.Context(), weekID)
+	reviews, err := h.reviewService.GetReviewsByWeekID(r.Context(), weekID, r.URL.Query().Get("status"), callerID(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    reviews,
-	})
+	writeData(w, r, http.StatusOK, reviews)
 }
 
 // GetAllReviews handles GET /api/v1/reviews
 func (h *ReviewHandler) GetAllReviews(w http.ResponseWriter, r *http.Request) {
-	reviews, err := h.reviewService.GetAllReviews(r.Context())
+	reviews, err := h.reviewService.GetAllReviews(r.Context(), r.URL.Query().Get("status"), callerID(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    reviews,
-	})
+	writeData(w, r, http.StatusOK, reviews)
 }
 
 // UpdateReview handles PUT /api/v1/reviews/{id}
@@ -109,26 +80,17 @@ func (h *ReviewHandler) UpdateReview(w http.ResponseWriter, r *http.Request) {
 	id := vars["id"]
 
 	var req models.UpdateReviewRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	review, err := h.reviewService.UpdateReview(r.Context(), id, req)
 	if err != nil {
-		if err.Error() == "review not found" {
-			http.Error(w, "Review not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    review,
-	})
+	writeData(w, r, http.StatusOK, review)
 }
 
 // DeleteReview handles DELETE /api/v1/reviews/{id}
@@ -138,19 +100,30 @@ func (h *ReviewHandler) DeleteReview(w http.ResponseWriter, r *http.Request) {
 
 	err := h.reviewService.DeleteReview(r.Context(), id)
 	if err != nil {
-		if err.Error() == "review not found" {
-			http.Error(w, "Review not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+
+	writeMessage(w, r, "Review deleted successfully")
+}
+
+// ChangeReviewStatus handles PUT /api/v1/reviews/{id}/status
+func (h *ReviewHandler) ChangeReviewStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.ChangeReviewStatusRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	review, err := h.reviewService.ChangeStatus(r.Context(), id, req, callerID(r))
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"message": "Review deleted successfully",
-	})
+	writeResponse(w, r, http.StatusOK, models.APIResponse{Data: review, Message: "Review status updated successfully"})
 }
 
 // GetDeletedReviewsByWeek handles GET /api/v1/weeks/{weekId}/deleted-reviews
@@ -158,17 +131,13 @@ func (h *ReviewHandler) GetDeletedReviewsByWeek(w http.ResponseWriter, r *http.R
 	vars := mux.Vars(r)
 	weekID := vars["weekId"]
 
-	reviews, err := h.reviewService.GetDeletedReviewsByWeekID(r.Context(), weekID)
+	reviews, err := h.reviewService.GetDeletedReviewsByWeekID(r.Context(), weekID, callerID(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    reviews,
-	})
+	writeData(w, r, http.StatusOK, reviews)
 }
 
 // HardDeleteReview handles DELETE /api/v1/reviews/{id}/permanent
@@ -178,19 +147,11 @@ func (h *ReviewHandler) HardDeleteReview(w http.ResponseWriter, r *http.Request)
 
 	err := h.reviewService.HardDeleteReview(r.Context(), id)
 	if err != nil {
-		if err.Error() == "review not found" {
-			http.Error(w, "Review not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"message": "Review permanently deleted",
-	})
+	writeMessage(w, r, "Review permanently deleted")
 }
 
 // RestoreReview handles PUT /api/v1/reviews/{id}/restore
@@ -199,21 +160,15 @@ func (h *ReviewHandler) RestoreReview(w http.ResponseWriter, r *http.Request) {
 	reviewID := vars["id"]
 
 	if reviewID == "" {
-		http.Error(w, "Review ID is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("Review ID is required"))
 		return
 	}
 
 	review, err := h.reviewService.RestoreReview(r.Context(), reviewID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    review,
-		"message": "Review restored successfully",
-	})
-}
\ No newline at end of file
+	writeResponse(w, r, http.StatusOK, models.APIResponse{Data: review, Message: "Review restored successfully"})
+}
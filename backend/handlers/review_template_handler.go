@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+)
+
+type ReviewTemplateHandler struct {
+	templateService *services.ReviewTemplateService
+}
+
+func NewReviewTemplateHandler(templateService *services.ReviewTemplateService) *ReviewTemplateHandler {
+	return &ReviewTemplateHandler{
+		templateService: templateService,
+	}
+}
+
+// CreateTemplate handles POST /api/v1/review-templates
+func (h *ReviewTemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateReviewTemplateRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	template, err := h.templateService.CreateTemplate(r.Context(), req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusCreated, template)
+}
+
+// GetTemplates handles GET /api/v1/review-templates
+func (h *ReviewTemplateHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.templateService.GetTemplates(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, templates)
+}
+
+// GetTemplate handles GET /api/v1/review-templates/{id}
+func (h *ReviewTemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	template, err := h.templateService.GetTemplateByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, template)
+}
+
+// GetTemplateVersions handles GET /api/v1/review-templates/{id}/versions
+func (h *ReviewTemplateHandler) GetTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	versions, err := h.templateService.GetTemplateVersions(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, versions)
+}
+
+// GetTemplateVersion handles GET /api/v1/review-templates/{id}/versions/{version}
+func (h *ReviewTemplateHandler) GetTemplateVersion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		writeError(w, r, invalidRequest("'version' must be a number"))
+		return
+	}
+
+	template, err := h.templateService.GetTemplateByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	snapshot, err := h.templateService.GetTemplateVersion(r.Context(), template.ID, version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, snapshot)
+}
+
+// UpdateTemplate handles PUT /api/v1/review-templates/{id}
+func (h *ReviewTemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.UpdateReviewTemplateRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	template, err := h.templateService.UpdateTemplate(r.Context(), id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, template)
+}
+
+// DeleteTemplate handles DELETE /api/v1/review-templates/{id}
+func (h *ReviewTemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.templateService.DeleteTemplate(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeMessage(w, r, "Review template deleted successfully")
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+)
+
+// RoleHandler serves the role catalog. Roles decide who has restricted access, so
+// creating, renaming and deleting them is restricted.
+type RoleHandler struct {
+	roleService   *services.RoleService
+	accessService *services.AccessService
+}
+
+func NewRoleHandler(roleService *services.RoleService, accessService *services.AccessService) *RoleHandler {
+	return &RoleHandler{
+		roleService:   roleService,
+		accessService: accessService,
+	}
+}
+
+// CreateRole handles POST /api/v1/roles
+func (h *RoleHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	var req models.CreateRoleRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	role, err := h.roleService.CreateRole(ctx, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusCreated, role)
+}
+
+// GetRoles handles GET /api/v1/roles
+func (h *RoleHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.roleService.GetRoles(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, roles)
+}
+
+// GetRole handles GET /api/v1/roles/{id}
+func (h *RoleHandler) GetRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	role, err := h.roleService.GetRoleByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, role)
+}
+
+// UpdateRole handles PUT /api/v1/roles/{id}
+func (h *RoleHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRoleRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(ctx, id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, role)
+}
+
+// DeleteRole handles DELETE /api/v1/roles/{id}
+func (h *RoleHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	ctx, ok := authorizeRestricted(w, r, h.accessService)
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.DeleteRole(ctx, id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeMessage(w, r, "Role deleted successfully")
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/services"
+)
+
+func TestCatalogWritesAreRestricted(t *testing.T) {
+	peopleService := services.NewPeopleService(database.Database, nil, services.NewAgeGroupService(services.DefaultPromotionDay), services.NewRoleService())
+	accessService := services.NewAccessService(peopleService, services.DefaultRestrictedAccessRoles)
+	roles := NewRoleHandler(services.NewRoleService(), accessService)
+	ageGroups := NewAgeGroupHandler(services.NewAgeGroupService(services.DefaultPromotionDay), accessService)
+	const id = "65a1b2c3d4e5f60718293a4c"
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		path    string
+		target  string
+		body    string
+	}{
+		{"create role", roles.CreateRole, "POST", "/roles", "/roles", `{"name": "Leader"}`},
+		{"rename role", roles.UpdateRole, "PUT", "/roles/{id}", "/roles/" + id, `{"name": "Leader"}`},
+		{"delete role", roles.DeleteRole, "DELETE", "/roles/{id}", "/roles/" + id, ``},
+		{"create age group", ageGroups.CreateAgeGroup, "POST", "/age-groups", "/age-groups", `{"name": "Juniors"}`},
+		{"rename age group", ageGroups.UpdateAgeGroup, "PUT", "/age-groups/{id}", "/age-groups/" + id, `{"name": "Juniors"}`},
+		{"delete age group", ageGroups.DeleteAgeGroup, "DELETE", "/age-groups/{id}", "/age-groups/" + id, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serve(tt.handler, tt.method, tt.path, tt.target, tt.body, ""); w.Code != http.StatusUnauthorized {
+				t.Errorf("without caller: status = %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+			}
+			if w := serve(tt.handler, tt.method, tt.path, tt.target, tt.body, "65a1b2c3d4e5f60718293a4b"); w.Code != http.StatusForbidden {
+				t.Errorf("unauthorized caller: status = %d, want %d: %s", w.Code, http.StatusForbidden, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+
+	"github.com/gorilla/mux"
+)
+
+type SafeguardingHandler struct {
+	safeguardingService *services.SafeguardingService
+}
+
+func NewSafeguardingHandler(safeguardingService *services.SafeguardingService) *SafeguardingHandler {
+	return &SafeguardingHandler{
+		safeguardingService: safeguardingService,
+	}
+}
+
+// GetWeekReport handles GET /api/v1/weeks/{id}/safeguarding
+func (h *SafeguardingHandler) GetWeekReport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	report, err := h.safeguardingService.GetWeekReport(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, models.APIResponse{
+		Data: report,
+		Meta: map[string]interface{}{"policy": h.safeguardingService.Policy()},
+	})
+}
+
+// GetViolations handles GET /api/v1/safeguarding/violations
+func (h *SafeguardingHandler) GetViolations(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseReportFilter(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	reports, err := h.safeguardingService.GetViolations(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, models.APIResponse{
+		Data: reports,
+		Meta: map[string]interface{}{"policy": h.safeguardingService.Policy()},
+	})
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+)
+
+type SearchHandler struct {
+	searchService *services.SearchService
+	accessService *services.AccessService
+}
+
+func NewSearchHandler(searchService *services.SearchService, accessService *services.AccessService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+		accessService: accessService,
+	}
+}
+
+// Search handles GET /api/v1/search
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		writeError(w, r, invalidRequest("'q' is required"))
+		return
+	}
+
+	var types []string
+	if value := query.Get("type"); value != "" {
+		types = strings.Split(value, ",")
+	}
+
+	limit := services.DefaultSearchLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			writeError(w, r, invalidRequest("'limit' must be a number"))
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.searchService.Search(requestContext(r, h.accessService), q, types, limit)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, models.APIResponse{Data: results, Message: fmt.Sprintf("Found %d results", len(results))})
+}
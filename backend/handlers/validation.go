@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"time"
+
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/services"
+	"eaglekidz-backend/validation"
+)
+
+// decodeRequest decodes a JSON request body into v and checks it against the binding
+// rules of its fields. It writes a 400 response when the body is not valid JSON of the
+// right shape, or a 422 response listing every field that breaks a rule, and reports
+// whether the request can go on. Binding tags that are not valid are reported as an
+// internal error.
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	return decode(w, r, v, false)
+}
+
+// decodeOptionalRequest is decodeRequest for endpoints whose body may be left out
+func decodeOptionalRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	return decode(w, r, v, true)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}, optional bool) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !(optional && err == io.EOF) {
+		writeAPIError(w, r, http.StatusBadRequest, &models.APIError{
+			Code:    services.CodeInvalidBody,
+			Message: "invalid request body",
+			Fields:  fieldErrors(validation.Errors{bodyError(err)}),
+		})
+		return false
+	}
+	if err := validation.Struct(v); err != nil {
+		writeError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// bodyError describes why a request body could not be decoded, naming the field when
+// a value has the wrong type
+func bodyError(err error) validation.FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return validation.FieldError{Field: typeErr.Field, Rule: "type", Message: "must be " + jsonKind(typeErr.Type)}
+	}
+	if err == io.EOF {
+		return validation.FieldError{Field: "body", Rule: "json", Message: "is required"}
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return validation.FieldError{Field: "body", Rule: "json", Message: "must be valid JSON"}
+	}
+	var timeErr *time.ParseError
+	if errors.As(err, &timeErr) {
+		return validation.FieldError{Field: "body", Rule: "type", Message: "times must be in RFC 3339 format, e.g. 2024-01-07T09:00:00Z"}
+	}
+	return validation.FieldError{Field: "body", Rule: "json", Message: err.Error()}
+}
+
+// jsonKind names the JSON type a Go type is decoded from
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
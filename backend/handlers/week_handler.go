@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"eaglekidz-backend/models"
@@ -11,61 +10,41 @@ import (
 )
 
 type WeekHandler struct {
-	weekService *services.WeekService
+	weekService         *services.WeekService
+	medicalService      *services.MedicalService
+	safeguardingService *services.SafeguardingService
+	actionItemService   *services.ActionItemService
 }
 
-func NewWeekHandler() *WeekHandler {
+func NewWeekHandler(weekService *services.WeekService, medicalService *services.MedicalService, safeguardingService *services.SafeguardingService, actionItemService *services.ActionItemService) *WeekHandler {
 	return &WeekHandler{
-		weekService: services.NewWeekService(),
+		weekService:         weekService,
+		medicalService:      medicalService,
+		safeguardingService: safeguardingService,
+		actionItemService:   actionItemService,
 	}
 }
 
 // CreateWeek handles POST /api/v1/weeks
 func (h *WeekHandler) CreateWeek(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateWeekRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	// Validate required fields
-	if req.StartTime.IsZero() || req.EndTime.IsZero() {
-		http.Error(w, "Start time and end time are required", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Validate that start time is before end time
 	if req.StartTime.After(req.EndTime) {
-		http.Error(w, "Start time must be before end time", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("Start time must be before end time"))
 		return
 	}
 
 	week, err := h.weekService.CreateWeek(r.Context(), req)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		// Check if it's a duplicate week error
-		if err.Error() == "a week with the same start and end dates already exists" {
-			w.WriteHeader(http.StatusConflict)
-			json.NewEncoder(w).Encode(map[string]string{
-				"message": "Week with the same start and end date already exists",
-				"status":  "error",
-			})
-			return
-		}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": err.Error(),
-			"status":  "error",
-		})
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    week,
-	})
+		writeWeekSaveError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusCreated, week)
 }
 
 // GetWeek handles GET /api/v1/weeks/{id}
@@ -75,34 +54,50 @@ func (h *WeekHandler) GetWeek(w http.ResponseWriter, r *http.Request) {
 
 	week, err := h.weekService.GetWeekByID(r.Context(), id)
 	if err != nil {
-		if err.Error() == "week not found" {
-			http.Error(w, "Week not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    week,
-	})
+	// Show medical alerts for the children checked in to each service
+	alerts, err := h.medicalService.GetServiceAlerts(r.Context(), week)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	for i, service := range week.Services {
+		week.Services[i].Alerts = alerts[services.ServiceKey(service.Name, service.Time)]
+	}
+
+	// Flag SICs who are missing safeguarding items for the week
+	if err := h.weekService.AnnotateCompliance(r.Context(), week); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Flag services that break the two-adult rule or the child-to-minister ratio
+	if err := h.safeguardingService.AnnotateWeek(r.Context(), week); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Show the week's action items and those still open from earlier weeks
+	if week.ActionItems, err = h.actionItemService.GetWeekActionItems(r.Context(), week); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeData(w, r, http.StatusOK, week)
 }
 
 // GetAllWeeks handles GET /api/v1/weeks
 func (h *WeekHandler) GetAllWeeks(w http.ResponseWriter, r *http.Request) {
 	weeks, err := h.weekService.GetAllWeeks(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    weeks,
-	})
+	writeData(w, r, http.StatusOK, weeks)
 }
 
 // UpdateWeekServices handles PUT /api/v1/weeks/{id}/services
@@ -111,32 +106,42 @@ func (h *WeekHandler) UpdateWeekServices(w http.ResponseWriter, r *http.Request)
 	id := vars["id"]
 
 	var req models.UpdateWeekServicesRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
-	// Validate that services are provided
-	if len(req.Services) == 0 {
-		http.Error(w, "Services are required", http.StatusBadRequest)
+	week, err := h.weekService.UpdateWeekServices(r.Context(), id, req)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	week, err := h.weekService.UpdateWeekServices(r.Context(), id, req)
+	writeData(w, r, http.StatusOK, week)
+}
+
+// CloneWeek handles POST /api/v1/weeks/{id}/clone
+func (h *WeekHandler) CloneWeek(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req models.CloneWeekRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	// Validate that start time is before end time
+	if req.StartTime.After(req.EndTime) {
+		writeError(w, r, invalidRequest("Start time must be before end time"))
+		return
+	}
+
+	week, err := h.weekService.CloneWeek(r.Context(), id, req)
 	if err != nil {
-		if err.Error() == "week not found" {
-			http.Error(w, "Week not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeWeekSaveError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    week,
-	})
+	writeData(w, r, http.StatusCreated, week)
 }
 
 // DeleteWeek handles DELETE /api/v1/weeks/{id}
@@ -146,17 +151,23 @@ func (h *WeekHandler) DeleteWeek(w http.ResponseWriter, r *http.Request) {
 
 	err := h.weekService.DeleteWeek(r.Context(), id)
 	if err != nil {
-		if err.Error() == "week not found" {
-			http.Error(w, "Week not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"message": "Week deleted successfully",
+	writeMessage(w, r, "Week deleted successfully")
+}
+
+// writeWeekSaveError writes the error of creating or cloning a week. The legacy format
+// reported these errors as {"message", "status": "error"} JSON rather than plain text.
+func writeWeekSaveError(w http.ResponseWriter, r *http.Request, err error) {
+	status, apiErr := toAPIError(err)
+	if !Legacy(r) || len(apiErr.Fields) > 0 {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, status, map[string]string{
+		"message": err.Error(),
+		"status":  "error",
 	})
-}
\ No newline at end of file
+}
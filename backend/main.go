@@ -8,18 +8,27 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"eaglekidz-backend/apidoc"
+	"eaglekidz-backend/auth"
 	"eaglekidz-backend/database"
+	"eaglekidz-backend/encryption"
 	"eaglekidz-backend/handlers"
+	"eaglekidz-backend/models"
 	"eaglekidz-backend/services"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 )
 
-// Response represents a standard API response
+// apiVersion is the version reported by the health check and the OpenAPI document
+const apiVersion = "1.0.0"
+
+// Response represents an API response in the legacy format
 type Response struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
@@ -28,25 +37,25 @@ type Response struct {
 
 // Health check endpoint
 func healthHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	response := Response{
-		Message: "Server is running",
-		Status:  "success",
-		Data: map[string]interface{}{
-			"timestamp": time.Now().UTC(),
-			"version":   "1.0.0",
-		},
-	}
-	json.NewEncoder(w).Encode(response)
+	writeStatus(w, r, "Server is running", map[string]interface{}{
+		"timestamp": time.Now().UTC(),
+		"version":   apiVersion,
+	})
 }
 
 // Sample API endpoint
 func apiHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	response := Response{
-		Message: "Welcome to EagleKidz API",
-		Status:  "success",
+	writeStatus(w, r, "Welcome to EagleKidz API", nil)
+}
+
+// writeStatus writes the response of the server endpoints, in the envelope or, for the
+// legacy format, as a Response
+func writeStatus(w http.ResponseWriter, r *http.Request, message string, data interface{}) {
+	var response interface{} = models.APIResponse{Success: true, Message: message, Data: data}
+	if handlers.Legacy(r) {
+		response = Response{Message: message, Status: "success", Data: data}
 	}
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -55,7 +64,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+handlers.CallerHeader+", "+handlers.FormatHeader)
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -75,12 +84,83 @@ func loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// envInt reads an integer environment variable, falling back to def when unset or invalid
+func envInt(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: %s must be a number, using %d", name, def)
+		return def
+	}
+	return parsed
+}
+
+// envList reads a comma-separated environment variable, falling back to def when unset
+func envList(name string, def []string) []string {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	return strings.Split(value, ",")
+}
+
+// issueToken prints a caller token for the minister ID in args, valid for the duration
+// given after it or for auth.DefaultValidity
+func issueToken(tokens *auth.Tokens, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: issue-token <minister-id> [valid-for, e.g. 720h]")
+	}
+	validity := auth.DefaultValidity
+	if len(args) == 2 {
+		parsed, err := time.ParseDuration(args[1])
+		if err != nil || parsed <= 0 {
+			return fmt.Errorf("valid-for must be a positive duration such as 720h")
+		}
+		validity = parsed
+	}
+
+	expiresAt := time.Now().Add(validity)
+	token, err := tokens.Issue(args[0], expiresAt)
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	fmt.Fprintf(os.Stderr, "Valid until %s\n", expiresAt.UTC().Format(time.RFC3339))
+	return nil
+}
+
+// envFormat reads the default response format, which is the envelope unless set to legacy
+func envFormat(name string) string {
+	if strings.EqualFold(strings.TrimSpace(os.Getenv(name)), handlers.FormatLegacy) {
+		return handlers.FormatLegacy
+	}
+	return handlers.FormatEnvelope
+}
+
 func main() {
 	// Load environment variables from .env file
 	if err := godotenv.Load(); err != nil {
 		log.Println("Warning: .env file not found, using system environment variables")
 	}
 
+	// Caller tokens prove which minister is making a request
+	tokens, err := auth.NewTokens(os.Getenv("CALLER_TOKEN_SECRET"))
+	if err != nil {
+		log.Fatal("Invalid CALLER_TOKEN_SECRET:", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "issue-token" {
+		if err := issueToken(tokens, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if !tokens.Enabled() {
+		log.Println("Warning: CALLER_TOKEN_SECRET not set, requests that need a minister will be rejected")
+	}
+
 	// Get MongoDB URI from environment variable
 	mongoURI := os.Getenv("MONGODB_URI")
 	if mongoURI == "" {
@@ -95,61 +175,608 @@ func main() {
 
 	fmt.Println("Connected to MongoDB successfully")
 
+	// Field-level encryption of children's personal data and medical records
+	keyring, err := encryption.NewKeyring(os.Getenv("ENCRYPTION_KEYS"), os.Getenv("BLIND_INDEX_KEY"))
+	if err != nil {
+		log.Fatal("Invalid encryption configuration:", err)
+	}
+	if !keyring.Enabled() {
+		log.Println("Warning: ENCRYPTION_KEYS not set, children's personal data will be stored unencrypted")
+	}
+
+	// Children move up to their next age group each year on the promotion date
+	promotionDay := services.DefaultPromotionDay
+	if value := os.Getenv("PROMOTION_DATE"); value != "" {
+		if promotionDay, err = services.ParsePromotionDay(value); err != nil {
+			log.Fatal("Invalid PROMOTION_DATE:", err)
+		}
+	}
+
+	// Safeguarding items ministers must hold and what happens when rostering someone without them
+	compliancePolicy := models.CompliancePolicy{
+		RequiredTypes:     envList("REQUIRED_COMPLIANCE", services.DefaultCompliancePolicy.RequiredTypes),
+		Enforcement:       services.DefaultCompliancePolicy.Enforcement,
+		ExpiryWarningDays: envInt("COMPLIANCE_EXPIRY_WARNING_DAYS", services.DefaultCompliancePolicy.ExpiryWarningDays),
+	}
+	if value := os.Getenv("COMPLIANCE_ENFORCEMENT"); value != "" {
+		compliancePolicy.Enforcement = value
+	}
+	if err := services.ValidateCompliancePolicy(compliancePolicy); err != nil {
+		log.Fatal("Invalid compliance configuration:", err)
+	}
+
+	// Two-adult rule and the child-to-minister ratio for age groups without their own
+	safeguardingPolicy := models.SafeguardingPolicy{
+		MinAdults:               envInt("MIN_ADULTS_PER_SERVICE", services.DefaultSafeguardingPolicy.MinAdults),
+		DefaultChildrenPerAdult: envInt("DEFAULT_CHILDREN_PER_ADULT", services.DefaultSafeguardingPolicy.DefaultChildrenPerAdult),
+	}
+	if err := services.ValidateSafeguardingPolicy(safeguardingPolicy); err != nil {
+		log.Fatal("Invalid safeguarding configuration:", err)
+	}
+
 	// Create services
-	peopleService := services.NewPeopleService(database.Database)
+	ageGroupService := services.NewAgeGroupService(promotionDay)
+	roleService := services.NewRoleService()
+	catalogService := services.NewCatalogService(ageGroupService, roleService)
+	peopleService := services.NewPeopleService(database.Database, keyring, ageGroupService, roleService)
+	complianceService := services.NewComplianceService(peopleService, compliancePolicy)
+	weekService := services.NewWeekService(complianceService, peopleService)
+	reviewTemplateService := services.NewReviewTemplateService()
+	auditService := services.NewAuditService()
+	accessService := services.NewAccessService(peopleService, envList("RESTRICTED_ACCESS_ROLES", services.DefaultRestrictedAccessRoles))
+	reviewApprovers := services.NewAccessService(peopleService, envList("REVIEW_APPROVER_ROLES", services.DefaultReviewApproverRoles))
+	reviewService := services.NewReviewService(weekService, reviewTemplateService, reviewApprovers)
+	medicalService := services.NewMedicalService(peopleService, auditService, keyring)
+	attendanceService := services.NewAttendanceService(weekService, peopleService, medicalService, auditService)
+	reportService := services.NewReportService()
+	followUpService := services.NewFollowUpService(peopleService)
+	labelService := services.NewLabelService(attendanceService, peopleService, medicalService)
+	safeguardingService := services.NewSafeguardingService(weekService, peopleService, ageGroupService, safeguardingPolicy)
+	incidentService := services.NewIncidentService(weekService, peopleService, auditService, keyring)
+	actionItemService := services.NewActionItemService(reviewService, peopleService)
+	reviewCommentService := services.NewReviewCommentService(reviewService, peopleService)
+	searchService := services.NewSearchService(peopleService)
+	privacyService := services.NewPrivacyService(peopleService, medicalService, complianceService, incidentService, actionItemService, reviewCommentService, auditService)
+
+	// Search needs text indexes and check-in its open check-in index; create them for
+	// databases set up before they existed
+	indexCtx, cancelIndexes := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := searchService.EnsureIndexes(indexCtx); err != nil {
+		log.Println("Warning: search may be unavailable:", err)
+	}
+	if err := attendanceService.EnsureIndexes(indexCtx); err != nil {
+		log.Println("Warning: a child may be checked in twice to the same week:", err)
+	}
+	cancelIndexes()
+
+	// Reviews written before templates existed move onto the built-in template
+	migrateCtx, cancelMigration := context.WithTimeout(context.Background(), 5*time.Minute)
+	if _, err := reviewTemplateService.EnsureDefaultTemplate(migrateCtx); err != nil {
+		log.Fatal("Failed to create the built-in review template:", err)
+	}
+	if migrated, err := reviewService.MigrateToTemplates(migrateCtx); err != nil {
+		log.Println("Warning: failed to migrate reviews to the built-in template:", err)
+	} else if migrated > 0 {
+		log.Printf("Migrated %d reviews to the built-in review template", migrated)
+	}
+	cancelMigration()
+
+	// Absentee follow-up criteria: attended N of the previous M weeks but missed the latest K
+	absenteeCriteria := models.AbsenteeCriteria{
+		MinAttended:   envInt("ABSENTEE_MIN_ATTENDED", services.DefaultAbsenteeCriteria.MinAttended),
+		LookbackWeeks: envInt("ABSENTEE_LOOKBACK_WEEKS", services.DefaultAbsenteeCriteria.LookbackWeeks),
+		MissedWeeks:   envInt("ABSENTEE_MISSED_WEEKS", services.DefaultAbsenteeCriteria.MissedWeeks),
+	}
+	if err := services.ValidateAbsenteeCriteria(absenteeCriteria); err != nil {
+		log.Fatal("Invalid absentee follow-up configuration:", err)
+	}
 
 	// Create handlers
-	weekHandler := handlers.NewWeekHandler()
-	reviewHandler := handlers.NewReviewHandler()
+	weekHandler := handlers.NewWeekHandler(weekService, medicalService, safeguardingService, actionItemService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
 	aiHandler := handlers.NewAIHandler()
-	peopleHandler := handlers.NewPeopleHandler(peopleService)
+	peopleHandler := handlers.NewPeopleHandler(peopleService, medicalService, accessService)
+	attendanceHandler := handlers.NewAttendanceHandler(attendanceService, accessService)
+	reportHandler := handlers.NewReportHandler(reportService)
+	followUpHandler := handlers.NewFollowUpHandler(followUpService, accessService, absenteeCriteria)
+	labelHandler := handlers.NewLabelHandler(labelService, accessService)
+	medicalHandler := handlers.NewMedicalHandler(medicalService, accessService)
+	auditHandler := handlers.NewAuditHandler(auditService, accessService)
+	ageGroupHandler := handlers.NewAgeGroupHandler(ageGroupService, accessService)
+	roleHandler := handlers.NewRoleHandler(roleService, accessService)
+	catalogHandler := handlers.NewCatalogHandler(catalogService, accessService)
+	privacyHandler := handlers.NewPrivacyHandler(privacyService, accessService)
+	encryptionHandler := handlers.NewEncryptionHandler(peopleService, medicalService, incidentService, auditService, accessService)
+	complianceHandler := handlers.NewComplianceHandler(complianceService)
+	safeguardingHandler := handlers.NewSafeguardingHandler(safeguardingService)
+	incidentHandler := handlers.NewIncidentHandler(incidentService, accessService)
+	searchHandler := handlers.NewSearchHandler(searchService, accessService)
+	actionItemHandler := handlers.NewActionItemHandler(actionItemService, weekService)
+	reviewCommentHandler := handlers.NewReviewCommentHandler(reviewCommentService)
+	reviewTemplateHandler := handlers.NewReviewTemplateHandler(reviewTemplateService)
 
 	// Create a new router
 	r := mux.NewRouter()
 
+	// Answer in the envelope unless API_RESPONSE_FORMAT asks for the legacy shapes
+	responseFormat := handlers.ResponseFormat(envFormat("API_RESPONSE_FORMAT"))
+
 	// Add middleware
 	r.Use(corsMiddleware)
 	r.Use(loggingMiddleware)
+	r.Use(responseFormat)
+	r.Use(handlers.Authenticate(tokens))
+
+	// Unknown routes and methods bypass the middleware above, so they pick their own format
+	r.NotFoundHandler = corsMiddleware(responseFormat(http.HandlerFunc(handlers.NotFound)))
+	r.MethodNotAllowedHandler = corsMiddleware(responseFormat(http.HandlerFunc(handlers.MethodNotAllowed)))
+
+	// Describe every route where it is registered; the OpenAPI document and the startup
+	// route table are built from the router
+	docs := apidoc.New(apidoc.Info{
+		Title:       "EagleKidz API",
+		Version:     apiVersion,
+		Description: "Weekly services, reviews, people and attendance of the EagleKidz children's ministry",
+	}, handlers.CallerHeader, handlers.FormatHeader)
+
+	// Query parameters shared by the report routes
+	reportParams := []apidoc.Param{
+		{Name: "from", Description: "Earliest week to include (YYYY-MM-DD or RFC 3339)"},
+		{Name: "to", Description: "Latest week to include (YYYY-MM-DD or RFC 3339)"},
+		{Name: "window", Type: "integer", Description: "Number of weeks in the rolling average"},
+		{Name: "format", Enum: []string{"json", "csv"}, Description: "csv to download the report, as does Accept: text/csv"},
+	}
+	peopleStatus := apidoc.Param{Name: "status", Description: "Comma-separated statuses, or all (default active)"}
+	reviewStatus := apidoc.Param{Name: "status", Description: "Comma-separated statuses: draft, submitted, changes_requested or approved, or all (default approved)"}
+	reviewVisibility := "Reviews that are not approved are only shown to their author and to ministers holding a review approver role."
 
 	// Define routes
-	r.HandleFunc("/health", healthHandler).Methods("GET")
-	r.HandleFunc("/api", apiHandler).Methods("GET")
+	status := docs.Group("Status")
+	status.Describe(r.HandleFunc("/health", healthHandler).Methods("GET"), apidoc.Operation{
+		ID: "GetHealth", Summary: "Health check",
+	})
+	status.Describe(r.HandleFunc("/api", apiHandler).Methods("GET"), apidoc.Operation{
+		ID: "GetWelcome", Summary: "Welcome message",
+	})
 
 	// API v1 routes
 	api := r.PathPrefix("/api/v1").Subrouter()
-	api.HandleFunc("/status", healthHandler).Methods("GET")
+	status.Describe(api.HandleFunc("/status", healthHandler).Methods("GET"), apidoc.Operation{
+		ID: "GetStatus", Summary: "API status",
+	})
+
+	// Documentation routes
+	docsHandler := handlers.NewDocsHandler(docs, r)
+	documentation := docs.Group("Documentation")
+	documentation.Describe(api.HandleFunc("/openapi.json", docsHandler.GetSpec).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "OpenAPI document of this API", Files: []string{"application/json"},
+	})
+	documentation.Describe(api.HandleFunc("/docs", docsHandler.GetPage).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "API documentation page", Files: []string{"text/html"},
+	})
 
 	// Week routes
-	api.HandleFunc("/weeks", weekHandler.CreateWeek).Methods("POST", "OPTIONS")
-	api.HandleFunc("/weeks", weekHandler.GetAllWeeks).Methods("GET", "OPTIONS")
-	api.HandleFunc("/weeks/{id}", weekHandler.GetWeek).Methods("GET", "OPTIONS")
-	api.HandleFunc("/weeks/{id}/services", weekHandler.UpdateWeekServices).Methods("PUT", "OPTIONS")
-	api.HandleFunc("/weeks/{id}", weekHandler.DeleteWeek).Methods("DELETE", "OPTIONS")
+	weeks := docs.Group("Weeks")
+	weeks.Describe(api.HandleFunc("/weeks", weekHandler.CreateWeek).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Create week", Request: models.CreateWeekRequest{}, Status: http.StatusCreated, Response: models.Week{},
+	})
+	weeks.Describe(api.HandleFunc("/weeks", weekHandler.GetAllWeeks).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get all weeks", Response: []models.Week{},
+	})
+	weeks.Describe(api.HandleFunc("/weeks/{id}", weekHandler.GetWeek).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get week by ID", Response: models.Week{},
+	})
+	weeks.Describe(api.HandleFunc("/weeks/{id}/services", weekHandler.UpdateWeekServices).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Replace a week's services and rosters", Request: models.UpdateWeekServicesRequest{}, Response: models.Week{},
+	})
+	weeks.Describe(api.HandleFunc("/weeks/{id}/clone", weekHandler.CloneWeek).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Clone week to a new date range", Request: models.CloneWeekRequest{}, Status: http.StatusCreated, Response: models.Week{},
+	})
+	weeks.Describe(api.HandleFunc("/weeks/{id}", weekHandler.DeleteWeek).Methods("DELETE", "OPTIONS"), apidoc.Operation{
+		Summary: "Delete week", Description: "Weeks with reviews, attendance, action items or incidents cannot be deleted.",
+	})
+
+	// Safeguarding routes
+	safeguarding := docs.Group("Safeguarding")
+	safeguarding.Describe(api.HandleFunc("/weeks/{id}/safeguarding", safeguardingHandler.GetWeekReport).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary:  "Check a week's rosters against the two-adult rule and ratios",
+		Response: models.SafeguardingReport{}, Meta: map[string]interface{}{"policy": models.SafeguardingPolicy{}},
+	})
+	safeguarding.Describe(api.HandleFunc("/safeguarding/violations", safeguardingHandler.GetViolations).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get weeks whose rosters break a safeguarding rule", Params: reportParams[:2],
+		Response: []models.SafeguardingReport{}, Meta: map[string]interface{}{"policy": models.SafeguardingPolicy{}},
+	})
 
 	// Review routes
-	api.HandleFunc("/reviews", reviewHandler.CreateReview).Methods("POST", "OPTIONS")
-	api.HandleFunc("/reviews", reviewHandler.GetAllReviews).Methods("GET", "OPTIONS")
-	api.HandleFunc("/reviews/{id}", reviewHandler.GetReview).Methods("GET", "OPTIONS")
-	api.HandleFunc("/reviews/{id}", reviewHandler.UpdateReview).Methods("PUT", "OPTIONS")
-	api.HandleFunc("/reviews/{id}", reviewHandler.DeleteReview).Methods("DELETE", "OPTIONS")
-	api.HandleFunc("/weeks/{weekId}/reviews", reviewHandler.GetReviewsByWeek).Methods("GET", "OPTIONS")
-	api.HandleFunc("/weeks/{weekId}/deleted-reviews", reviewHandler.GetDeletedReviewsByWeek).Methods("GET", "OPTIONS")
-	api.HandleFunc("/reviews/{id}/permanent", reviewHandler.HardDeleteReview).Methods("DELETE", "OPTIONS")
-	api.HandleFunc("/reviews/{id}/restore", reviewHandler.RestoreReview).Methods("PUT", "OPTIONS")
+	reviews := docs.Group("Reviews")
+	reviews.Describe(api.HandleFunc("/reviews", reviewHandler.CreateReview).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Create review", Request: models.CreateReviewRequest{}, Status: http.StatusCreated, Response: models.Review{},
+	})
+	reviews.Describe(api.HandleFunc("/reviews", reviewHandler.GetAllReviews).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get all reviews", Params: []apidoc.Param{reviewStatus}, Response: []models.Review{},
+		Description: reviewVisibility,
+	})
+	reviews.Describe(api.HandleFunc("/reviews/{id}", reviewHandler.GetReview).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get review by ID", Response: models.Review{}, Description: reviewVisibility,
+	})
+	reviews.Describe(api.HandleFunc("/reviews/{id}", reviewHandler.UpdateReview).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Update review", Request: models.UpdateReviewRequest{}, Response: models.Review{},
+	})
+	reviews.Describe(api.HandleFunc("/reviews/{id}", reviewHandler.DeleteReview).Methods("DELETE", "OPTIONS"), apidoc.Operation{
+		Summary: "Soft delete review",
+	})
+	reviews.Describe(api.HandleFunc("/weeks/{weekId}/reviews", reviewHandler.GetReviewsByWeek).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get reviews by week", Params: []apidoc.Param{reviewStatus}, Response: []models.Review{},
+		Description: reviewVisibility,
+	})
+	reviews.Describe(api.HandleFunc("/weeks/{weekId}/deleted-reviews", reviewHandler.GetDeletedReviewsByWeek).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get deleted reviews by week", Response: []models.Review{}, Description: reviewVisibility,
+	})
+	reviews.Describe(api.HandleFunc("/reviews/{id}/permanent", reviewHandler.HardDeleteReview).Methods("DELETE", "OPTIONS"), apidoc.Operation{
+		Summary: "Permanently delete review", Description: "Only deleted reviews that are drafts or have changes requested can be removed.",
+	})
+	reviews.Describe(api.HandleFunc("/reviews/{id}/restore", reviewHandler.RestoreReview).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Restore deleted review", Response: models.Review{},
+	})
+	reviews.Describe(api.HandleFunc("/reviews/{id}/status", reviewHandler.ChangeReviewStatus).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Submit, approve, send back or reopen review", Access: apidoc.Caller,
+		Description: "Approving, sending back and reopening need a minister holding a review approver role.",
+		Request:     models.ChangeReviewStatusRequest{}, Response: models.Review{},
+	})
+
+	// Review template routes
+	templates := docs.Group("Review templates")
+	templates.Describe(api.HandleFunc("/review-templates", reviewTemplateHandler.CreateTemplate).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Create review template", Request: models.CreateReviewTemplateRequest{}, Status: http.StatusCreated, Response: models.ReviewTemplate{},
+	})
+	templates.Describe(api.HandleFunc("/review-templates", reviewTemplateHandler.GetTemplates).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get review templates", Response: []models.ReviewTemplate{},
+	})
+	templates.Describe(api.HandleFunc("/review-templates/{id}", reviewTemplateHandler.GetTemplate).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get latest version of a review template", Response: models.ReviewTemplate{},
+	})
+	templates.Describe(api.HandleFunc("/review-templates/{id}", reviewTemplateHandler.UpdateTemplate).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Edit review template as a new version", Request: models.UpdateReviewTemplateRequest{}, Response: models.ReviewTemplate{},
+	})
+	templates.Describe(api.HandleFunc("/review-templates/{id}", reviewTemplateHandler.DeleteTemplate).Methods("DELETE", "OPTIONS"), apidoc.Operation{
+		Summary: "Retire review template (stays readable)",
+	})
+	templates.Describe(api.HandleFunc("/review-templates/{id}/versions", reviewTemplateHandler.GetTemplateVersions).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get every version of a review template", Response: []models.ReviewTemplateVersion{},
+	})
+	templates.Describe(api.HandleFunc("/review-templates/{id}/versions/{version}", reviewTemplateHandler.GetTemplateVersion).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get one version of a review template", Params: []apidoc.Param{{Name: "version", Type: "integer"}},
+		Response: models.ReviewTemplateVersion{},
+	})
+
+	// Review comment routes
+	comments := docs.Group("Review comments")
+	comments.Describe(api.HandleFunc("/reviews/{id}/comments", reviewCommentHandler.CreateComment).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Comment on a review or reply to a comment", Request: models.CreateReviewCommentRequest{}, Status: http.StatusCreated, Response: models.ReviewComment{},
+	})
+	comments.Describe(api.HandleFunc("/reviews/{id}/comments", reviewCommentHandler.GetReviewComments).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get a review's comment threads", Response: []models.ReviewComment{},
+	})
+	comments.Describe(api.HandleFunc("/reviews/{id}/deleted-comments", reviewCommentHandler.GetDeletedComments).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get a review's deleted comments", Response: []models.ReviewComment{},
+	})
+	comments.Describe(api.HandleFunc("/review-comments/{id}", reviewCommentHandler.UpdateComment).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Edit own comment", Access: apidoc.Caller, Request: models.UpdateReviewCommentRequest{}, Response: models.ReviewComment{},
+	})
+	comments.Describe(api.HandleFunc("/review-comments/{id}", reviewCommentHandler.DeleteComment).Methods("DELETE", "OPTIONS"), apidoc.Operation{
+		Summary: "Soft delete own comment", Access: apidoc.Caller,
+	})
+	comments.Describe(api.HandleFunc("/review-comments/{id}/restore", reviewCommentHandler.RestoreComment).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Restore own deleted comment", Access: apidoc.Caller, Response: models.ReviewComment{},
+	})
+
+	// Action item routes
+	actionItems := docs.Group("Action items")
+	actionItems.Describe(api.HandleFunc("/reviews/{id}/action-items", actionItemHandler.CreateActionItem).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Add an action item to a review", Request: models.CreateActionItemRequest{}, Status: http.StatusCreated, Response: models.ActionItem{},
+	})
+	actionItems.Describe(api.HandleFunc("/reviews/{id}/action-items", actionItemHandler.GetReviewActionItems).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get a review's action items", Response: []models.ActionItem{},
+	})
+	actionItems.Describe(api.HandleFunc("/weeks/{id}/action-items", actionItemHandler.GetWeekActionItems).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get a week's action items and open ones carried over", Response: []models.ActionItem{},
+	})
+	actionItems.Describe(api.HandleFunc("/action-items", actionItemHandler.GetActionItems).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get action items",
+		Params: []apidoc.Param{
+			{Name: "owner_id", Description: "Minister the items are assigned to"},
+			{Name: "status", Description: "Comma-separated statuses: open, in_progress, done or cancelled"},
+		},
+		Response: []models.ActionItem{},
+	})
+	actionItems.Describe(api.HandleFunc("/action-items/overdue", actionItemHandler.GetOverdue).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get open action items past their due date", Response: []models.ActionItem{},
+	})
+	actionItems.Describe(api.HandleFunc("/action-items/{id}", actionItemHandler.GetActionItem).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get action item by ID", Response: models.ActionItem{},
+	})
+	actionItems.Describe(api.HandleFunc("/action-items/{id}", actionItemHandler.UpdateActionItem).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Update action item, its owner, due date or status", Request: models.UpdateActionItemRequest{}, Response: models.ActionItem{},
+	})
+	actionItems.Describe(api.HandleFunc("/action-items/{id}", actionItemHandler.DeleteActionItem).Methods("DELETE", "OPTIONS"), apidoc.Operation{
+		Summary: "Delete action item",
+	})
+	actionItems.Describe(api.HandleFunc("/action-items/{id}/comments", actionItemHandler.AddComment).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Comment on an action item", Request: models.AddActionItemCommentRequest{}, Status: http.StatusCreated, Response: models.ActionItem{},
+	})
 
 	// People routes
-	api.HandleFunc("/people", peopleHandler.CreatePeople).Methods("POST", "OPTIONS")
-	api.HandleFunc("/people", peopleHandler.GetAllPeople).Methods("GET", "OPTIONS")
-	api.HandleFunc("/people/type/{type}", peopleHandler.GetPeopleByType).Methods("GET", "OPTIONS")
-	api.HandleFunc("/people/{id}", peopleHandler.GetPeople).Methods("GET", "OPTIONS")
-	api.HandleFunc("/people/{id}", peopleHandler.UpdatePeople).Methods("PUT", "OPTIONS")
-	api.HandleFunc("/people/{id}", peopleHandler.DeletePeople).Methods("DELETE", "OPTIONS")
-	api.HandleFunc("/people/deleted", peopleHandler.GetDeletedPeople).Methods("GET", "OPTIONS")
-	api.HandleFunc("/people/{id}/permanent", peopleHandler.HardDeletePeople).Methods("DELETE", "OPTIONS")
-	api.HandleFunc("/people/{id}/restore", peopleHandler.RestorePeople).Methods("PUT", "OPTIONS")
+	people := docs.Group("People")
+	people.Describe(api.HandleFunc("/people", peopleHandler.CreatePeople).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Create person", Access: apidoc.Redacted, Request: models.CreatePeopleRequest{}, Status: http.StatusCreated, Response: models.People{},
+	})
+	people.Describe(api.HandleFunc("/people", peopleHandler.GetAllPeople).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get people", Access: apidoc.Redacted,
+		Params:   []apidoc.Param{peopleStatus, {Name: "phone", Description: "Find the people with this phone number (restricted)"}},
+		Response: []models.People{},
+	})
+	people.Describe(api.HandleFunc("/people/type/{type}", peopleHandler.GetPeopleByType).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get people by type", Access: apidoc.Redacted,
+		Params:   []apidoc.Param{{Name: "type", Enum: []string{"minister", "children"}}, peopleStatus},
+		Response: []models.People{},
+	})
+	people.Describe(api.HandleFunc("/people/{id}", peopleHandler.GetPeople).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get person by ID", Access: apidoc.Redacted, Response: models.People{},
+	})
+	people.Describe(api.HandleFunc("/people/{id}", peopleHandler.UpdatePeople).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Update person", Access: apidoc.Redacted, Request: models.UpdatePeopleRequest{}, Response: models.People{},
+		Description: "Changing roles needs a minister holding a restricted access role.",
+	})
+	people.Describe(api.HandleFunc("/people/{id}", peopleHandler.DeletePeople).Methods("DELETE", "OPTIONS"), apidoc.Operation{
+		Summary: "Soft delete person",
+	})
+	people.Describe(api.HandleFunc("/people/deleted", peopleHandler.GetDeletedPeople).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get deleted people", Access: apidoc.Redacted, Response: []models.People{},
+	})
+	people.Describe(api.HandleFunc("/people/{id}/status", peopleHandler.ChangeStatus).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Change person's status with a reason", Access: apidoc.Restricted, Request: models.ChangeStatusRequest{}, Response: models.People{},
+	})
+	people.Describe(api.HandleFunc("/people/{id}/permanent", peopleHandler.HardDeletePeople).Methods("DELETE", "OPTIONS"), apidoc.Operation{
+		Summary: "Permanently delete person",
+	})
+	people.Describe(api.HandleFunc("/people/{id}/restore", peopleHandler.RestorePeople).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Restore deleted person", Access: apidoc.Redacted, Response: models.People{},
+	})
+
+	// Age group routes
+	ageGroups := docs.Group("Age groups")
+	ageGroups.Describe(api.HandleFunc("/age-groups", ageGroupHandler.CreateAgeGroup).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Create age group", Access: apidoc.Restricted, Request: models.CreateAgeGroupRequest{}, Status: http.StatusCreated, Response: models.AgeGroup{},
+	})
+	ageGroups.Describe(api.HandleFunc("/age-groups", ageGroupHandler.GetAgeGroups).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get all age groups", Response: []models.AgeGroup{},
+	})
+	ageGroups.Describe(api.HandleFunc("/age-groups/{id}", ageGroupHandler.GetAgeGroup).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get age group by ID", Response: models.AgeGroup{},
+	})
+	ageGroups.Describe(api.HandleFunc("/age-groups/{id}", ageGroupHandler.UpdateAgeGroup).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Update age group (renames carry over to people)", Access: apidoc.Restricted, Request: models.UpdateAgeGroupRequest{}, Response: models.AgeGroup{},
+	})
+	ageGroups.Describe(api.HandleFunc("/age-groups/{id}", ageGroupHandler.DeleteAgeGroup).Methods("DELETE", "OPTIONS"), apidoc.Operation{
+		Summary: "Delete unused age group", Access: apidoc.Restricted,
+	})
+	ageGroups.Describe(api.HandleFunc("/promotions/preview", ageGroupHandler.PreviewPromotion).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary:  "Preview the yearly age group promotion",
+		Params:   []apidoc.Param{{Name: "as_of", Description: "Promotion date (default the next one)"}},
+		Response: models.PromotionPlan{},
+	})
+	ageGroups.Describe(api.HandleFunc("/promotions", ageGroupHandler.ApplyPromotion).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Apply the yearly age group promotion", Request: models.ApplyPromotionRequest{}, OptionalBody: true, Response: models.PromotionPlan{},
+	})
+
+	// Role routes
+	roles := docs.Group("Roles")
+	roles.Describe(api.HandleFunc("/roles", roleHandler.CreateRole).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Create role", Access: apidoc.Restricted, Request: models.CreateRoleRequest{}, Status: http.StatusCreated, Response: models.Role{},
+	})
+	roles.Describe(api.HandleFunc("/roles", roleHandler.GetRoles).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get all roles", Response: []models.Role{},
+	})
+	roles.Describe(api.HandleFunc("/roles/{id}", roleHandler.GetRole).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get role by ID", Response: models.Role{},
+	})
+	roles.Describe(api.HandleFunc("/roles/{id}", roleHandler.UpdateRole).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Update role (renames carry over to people)", Access: apidoc.Restricted, Request: models.UpdateRoleRequest{}, Response: models.Role{},
+	})
+	roles.Describe(api.HandleFunc("/roles/{id}", roleHandler.DeleteRole).Methods("DELETE", "OPTIONS"), apidoc.Operation{
+		Summary: "Delete unused role", Access: apidoc.Restricted,
+	})
+
+	// Medical routes
+	medical := docs.Group("Medical")
+	medical.Describe(api.HandleFunc("/people/{id}/medical", medicalHandler.GetMedicalInfo).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get child's medical record", Access: apidoc.Restricted, Response: models.MedicalInfo{},
+	})
+	medical.Describe(api.HandleFunc("/people/{id}/medical", medicalHandler.UpsertMedicalInfo).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Save child's medical record", Access: apidoc.Restricted, Request: models.UpsertMedicalInfoRequest{}, Response: models.MedicalInfo{},
+	})
+	medical.Describe(api.HandleFunc("/people/{id}/alerts", medicalHandler.GetAlertFlags).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get child's medical alert flags", Access: apidoc.Redacted, Response: []models.AlertFlag{},
+	})
+
+	// Compliance routes
+	compliance := docs.Group("Compliance")
+	compliance.Describe(api.HandleFunc("/people/{id}/compliance", complianceHandler.CreateItem).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Record a minister's safeguarding compliance item", Request: models.CreateComplianceItemRequest{}, Status: http.StatusCreated, Response: models.ComplianceItem{},
+	})
+	compliance.Describe(api.HandleFunc("/people/{id}/compliance", complianceHandler.GetMinisterCompliance).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get a minister's compliance items and what is missing", Response: models.MinisterCompliance{},
+	})
+	compliance.Describe(api.HandleFunc("/compliance/expiring", complianceHandler.GetExpiring).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary:  "Get compliance items flagged as expiring",
+		Response: []models.ComplianceItem{}, Meta: map[string]interface{}{"policy": models.CompliancePolicy{}},
+	})
+	compliance.Describe(api.HandleFunc("/compliance/flag-expiring", complianceHandler.FlagExpiring).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Flag compliance items expiring soon", Response: []models.ComplianceItem{},
+	})
+	compliance.Describe(api.HandleFunc("/compliance/{id}", complianceHandler.UpdateItem).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Update compliance item", Request: models.UpdateComplianceItemRequest{}, Response: models.ComplianceItem{},
+	})
+	compliance.Describe(api.HandleFunc("/compliance/{id}", complianceHandler.DeleteItem).Methods("DELETE", "OPTIONS"), apidoc.Operation{
+		Summary: "Delete compliance item",
+	})
+
+	// Incident routes
+	incidents := docs.Group("Incidents")
+	incidents.Describe(api.HandleFunc("/incidents", incidentHandler.CreateIncident).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Report an incident involving children", Request: models.CreateIncidentRequest{}, Status: http.StatusCreated, Response: models.Incident{},
+	})
+	incidents.Describe(api.HandleFunc("/incidents", incidentHandler.GetIncidents).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get incidents", Access: apidoc.Restricted,
+		Params: []apidoc.Param{
+			{Name: "week_id", Description: "Week the incidents happened in"},
+			{Name: "child_id", Description: "Child involved in the incidents"},
+			{Name: "follow_up_status", Enum: []string{"none", "required", "completed"}},
+		},
+		Response: []models.Incident{},
+	})
+	incidents.Describe(api.HandleFunc("/incidents/{id}", incidentHandler.GetIncident).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get incident by ID", Access: apidoc.Restricted, Response: models.Incident{},
+	})
+	incidents.Describe(api.HandleFunc("/incidents/{id}", incidentHandler.UpdateIncident).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Update incident, guardian notification and follow-up", Access: apidoc.Restricted, Request: models.UpdateIncidentRequest{}, Response: models.Incident{},
+	})
+
+	// Privacy routes
+	privacy := docs.Group("Privacy")
+	privacy.Describe(api.HandleFunc("/people/{id}/export", privacyHandler.ExportPerson).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Export everything held about a person as JSON or ZIP", Access: apidoc.Restricted,
+		Params:   []apidoc.Param{{Name: "format", Enum: []string{"json", "zip"}, Description: "zip to download the export, as does Accept: application/zip"}},
+		Response: models.SubjectAccessExport{}, Files: []string{"application/zip"},
+	})
+	privacy.Describe(api.HandleFunc("/people/{id}/erase", privacyHandler.ErasePerson).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Erase a person everywhere and leave a tombstone", Access: apidoc.Restricted, Request: models.EraseRequest{}, Response: models.ErasureTombstone{},
+	})
+	privacy.Describe(api.HandleFunc("/erasures", privacyHandler.GetErasures).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get tombstones of erased people", Access: apidoc.Restricted, Response: []models.ErasureTombstone{},
+	})
+
+	// Audit routes
+	audit := docs.Group("Audit")
+	audit.Describe(api.HandleFunc("/audit", auditHandler.GetEntries).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get audit trail", Access: apidoc.Restricted,
+		Params: []apidoc.Param{
+			{Name: "entity_type", Description: "Kind of record, e.g. people"},
+			{Name: "entity_id", Description: "Record the entries are about"},
+		},
+		Response: []models.AuditEntry{},
+	})
+
+	// Admin routes
+	admin := docs.Group("Admin")
+	admin.Describe(api.HandleFunc("/admin/encryption/rotate", encryptionHandler.RotateKeys).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Re-encrypt data with the current key", Access: apidoc.Restricted, Response: map[string]int{},
+	})
+	admin.Describe(api.HandleFunc("/admin/catalogs/migrate", catalogHandler.MigratePeople).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Normalize people's age groups and roles to the catalogs", Access: apidoc.Restricted,
+		Params:   []apidoc.Param{{Name: "dry_run", Type: "boolean", Description: "true to report the changes without saving them"}},
+		Response: models.CatalogMigrationReport{},
+	})
+
+	// Attendance routes
+	attendance := docs.Group("Attendance")
+	attendance.Describe(api.HandleFunc("/attendance/check-in", attendanceHandler.CheckIn).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Check a child in to a service", Request: models.CheckInRequest{}, Status: http.StatusCreated, Response: models.Attendance{},
+	})
+	attendance.Describe(api.HandleFunc("/attendance/{id}/check-out", attendanceHandler.CheckOut).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Check a child out with the security code", Request: models.CheckOutRequest{}, Response: models.Attendance{},
+	})
+	attendance.Describe(api.HandleFunc("/attendance/{id}/unlock", attendanceHandler.UnlockCheckOut).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Unlock a check-out locked by wrong security codes", Access: apidoc.Restricted, Response: models.Attendance{},
+	})
+	attendance.Describe(api.HandleFunc("/weeks/{weekId}/checked-in", attendanceHandler.GetCheckedInByWeek).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get children currently checked in per service", Response: []models.CheckedInService{},
+	})
+	attendance.Describe(api.HandleFunc("/weeks/{weekId}/attendance", attendanceHandler.GetAttendanceByWeek).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get all attendance for a week", Response: []models.Attendance{},
+	})
+
+	// Label routes
+	labels := docs.Group("Labels")
+	layout := apidoc.Param{Name: "layout", Description: "Label layout (default " + services.DefaultLabelLayout + ")"}
+	labels.Describe(api.HandleFunc("/labels/layouts", labelHandler.GetLayouts).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary:  "Get supported label layouts",
+		Response: []services.LabelLayout{}, Meta: map[string]interface{}{"default": ""},
+	})
+	labels.Describe(api.HandleFunc("/attendance/{id}/labels", labelHandler.GetAttendanceLabels).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Print child tag and pickup stub (PDF)", Access: apidoc.Restricted,
+		Params: []apidoc.Param{layout}, Files: []string{"application/pdf"},
+	})
+	labels.Describe(api.HandleFunc("/weeks/{weekId}/labels", labelHandler.GetWeekLabels).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Print labels for all checked-in children (PDF)", Access: apidoc.Restricted,
+		Params: []apidoc.Param{
+			{Name: "service_name", Description: "Only print the children of this service"},
+			{Name: "service_time"},
+			layout,
+		},
+		Files: []string{"application/pdf"},
+	})
+
+	// Report routes
+	reports := docs.Group("Reports")
+	reports.Describe(api.HandleFunc("/reports/attendance/weekly", reportHandler.GetWeeklyAttendance).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Weekly attendance with trends (JSON or CSV)", Params: reportParams,
+		Response: []models.WeeklyAttendanceReport{}, Files: []string{"text/csv"},
+	})
+	reports.Describe(api.HandleFunc("/reports/attendance/services", reportHandler.GetServiceAttendance).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Attendance per service with trends (JSON or CSV)", Params: reportParams,
+		Response: []models.ServiceAttendanceReport{}, Files: []string{"text/csv"},
+	})
+	reports.Describe(api.HandleFunc("/reports/attendance/age-groups", reportHandler.GetAgeGroupAttendance).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Attendance per age group with trends (JSON or CSV)", Params: reportParams,
+		Response: []models.AgeGroupAttendanceReport{}, Files: []string{"text/csv"},
+	})
+	reports.Describe(api.HandleFunc("/reports/reviews/services", reportHandler.GetServiceRatings).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Average review ratings per service (JSON or CSV)", Params: reportParams,
+		Response: []models.ServiceRatingReport{}, Files: []string{"text/csv"},
+	})
+	reports.Describe(api.HandleFunc("/reports/reviews/services/weekly", reportHandler.GetWeeklyServiceRatings).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Average review ratings per service each week (JSON or CSV)", Params: reportParams,
+		Response: []models.ServiceRatingReport{}, Files: []string{"text/csv"},
+	})
+
+	// Follow-up routes
+	followUps := docs.Group("Follow-ups")
+	followUps.Describe(api.HandleFunc("/follow-ups", followUpHandler.GetFollowUps).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Get absentee follow-ups", Access: apidoc.Redacted,
+		Params:   []apidoc.Param{{Name: "status", Enum: []string{models.FollowUpStatusOpen, models.FollowUpStatusContacted}}},
+		Response: []models.FollowUp{},
+	})
+	followUps.Describe(api.HandleFunc("/follow-ups/detect", followUpHandler.DetectAbsentees).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Detect absent regular children", Access: apidoc.Redacted,
+		Params: []apidoc.Param{
+			{Name: "n", Type: "integer", Description: "Weeks a child must have attended to count as regular"},
+			{Name: "m", Type: "integer", Description: "Weeks examined before the missed weeks"},
+			{Name: "k", Type: "integer", Description: "Most recent weeks the child must have missed"},
+		},
+		Response: []models.FollowUp{}, Meta: map[string]interface{}{"criteria": models.AbsenteeCriteria{}},
+	})
+	followUps.Describe(api.HandleFunc("/follow-ups/{id}/contacted", followUpHandler.MarkContacted).Methods("PUT", "OPTIONS"), apidoc.Operation{
+		Summary: "Mark follow-up as contacted", Access: apidoc.Redacted, Request: models.MarkContactedRequest{}, Response: models.FollowUp{},
+	})
+
+	// Search routes
+	search := docs.Group("Search")
+	search.Describe(api.HandleFunc("/search", searchHandler.Search).Methods("GET", "OPTIONS"), apidoc.Operation{
+		Summary: "Search people, reviews and weeks", Access: apidoc.Redacted,
+		Params: []apidoc.Param{
+			{Name: "q", Description: "Words to search for", Required: true},
+			{Name: "type", Description: "Comma-separated result types: " + strings.Join(services.SearchTypes, ", ")},
+			{Name: "limit", Type: "integer", Description: fmt.Sprintf("Most results to return, up to %d (default %d)", services.MaxSearchLimit, services.DefaultSearchLimit)},
+		},
+		Response: []models.SearchResult{},
+	})
 
 	// AI routes
-	api.HandleFunc("/ai/summarize", aiHandler.GenerateSummary).Methods("POST", "OPTIONS")
+	ai := docs.Group("AI")
+	ai.Describe(api.HandleFunc("/ai/summarize", aiHandler.GenerateSummary).Methods("POST", "OPTIONS"), apidoc.Operation{
+		Summary: "Summarize a review's answers", Request: handlers.AIRequest{}, Response: handlers.AIResponse{},
+	})
 
 	// Start server
 	// Get port from environment variable
@@ -160,31 +787,9 @@ func main() {
 	port = ":" + port
 	fmt.Printf("Server starting on port %s\n", port)
 	fmt.Println("Available endpoints:")
-	fmt.Println("  GET /health - Health check")
-	fmt.Println("  GET /api - Welcome message")
-	fmt.Println("  GET /api/v1/status - API status")
-	fmt.Println("  POST /api/v1/weeks - Create week")
-	fmt.Println("  GET /api/v1/weeks - Get all weeks")
-	fmt.Println("  GET /api/v1/weeks/{id} - Get week by ID")
-	fmt.Println("  DELETE /api/v1/weeks/{id} - Delete week")
-	fmt.Println("  POST /api/v1/reviews - Create review")
-	fmt.Println("  GET /api/v1/reviews - Get all reviews")
-	fmt.Println("  GET /api/v1/reviews/{id} - Get review by ID")
-	fmt.Println("  PUT /api/v1/reviews/{id} - Update review")
-	fmt.Println("  DELETE /api/v1/reviews/{id} - Soft delete review")
-	fmt.Println("  GET /api/v1/weeks/{weekId}/reviews - Get reviews by week")
-	fmt.Println("  GET /api/v1/weeks/{weekId}/deleted-reviews - Get deleted reviews by week")
-	fmt.Println("  DELETE /api/v1/reviews/{id}/permanent - Permanently delete review")
-	fmt.Println("  PUT /api/v1/reviews/{id}/restore - Restore deleted review")
-	fmt.Println("  POST /api/v1/people - Create person")
-	fmt.Println("  GET /api/v1/people - Get all people")
-	fmt.Println("  GET /api/v1/people/type/{type} - Get people by type (minister/children)")
-	fmt.Println("  GET /api/v1/people/{id} - Get person by ID")
-	fmt.Println("  PUT /api/v1/people/{id} - Update person")
-	fmt.Println("  DELETE /api/v1/people/{id} - Soft delete person")
-	fmt.Println("  GET /api/v1/people/deleted - Get deleted people")
-	fmt.Println("  DELETE /api/v1/people/{id}/permanent - Permanently delete person")
-	fmt.Println("  PUT /api/v1/people/{id}/restore - Restore deleted person")
+	if err := docs.PrintRoutes(os.Stdout, r); err != nil {
+		log.Fatal("Failed to list routes:", err)
+	}
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -192,6 +797,12 @@ func main() {
 		Handler: r,
 	}
 
+	// Start background jobs
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go followUpService.RunDetectionJob(jobCtx, 24*time.Hour, absenteeCriteria)
+	go complianceService.RunExpiryJob(jobCtx, 24*time.Hour)
+
 	// Start server in a goroutine
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -204,6 +815,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	fmt.Println("\nShutting down server...")
+	stopJobs()
 
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Action item statuses
+const (
+	ActionItemStatusOpen       = "open"
+	ActionItemStatusInProgress = "in_progress"
+	ActionItemStatusDone       = "done"
+	ActionItemStatusCancelled  = "cancelled"
+)
+
+// ActionItem is an action agreed in a review that someone has to follow up on
+type ActionItem struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	ReviewID    primitive.ObjectID  `bson:"review_id" json:"review_id"`
+	WeekID      primitive.ObjectID  `bson:"week_id" json:"week_id"` // Week of the review
+	Title       string              `bson:"title" json:"title"`
+	Description string              `bson:"description,omitempty" json:"description,omitempty"`
+	OwnerID     string              `bson:"owner_id" json:"owner_id"` // Minister ID
+	DueDate     time.Time           `bson:"due_date" json:"due_date"`
+	Status      string              `bson:"status" json:"status"` // "open", "in_progress", "done" or "cancelled"
+	CompletedAt *time.Time          `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	Comments    []ActionItemComment `bson:"comments" json:"comments"`
+	// Overdue is set when the item is still open past its due date
+	Overdue bool `bson:"-" json:"overdue"`
+	// CarriedOver is set when the item is shown on a week after the one it was raised in
+	CarriedOver bool      `bson:"-" json:"carried_over,omitempty"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// ActionItemComment is a progress note on an action item
+type ActionItemComment struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	AuthorID  string             `bson:"author_id" json:"author_id"` // Minister ID
+	Text      string             `bson:"text" json:"text"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// CreateActionItemRequest represents the request payload for adding an action item to a review
+type CreateActionItemRequest struct {
+	Title       string    `json:"title" binding:"required,max=200"`
+	Description string    `json:"description,omitempty" binding:"max=2000"`
+	OwnerID     string    `json:"owner_id" binding:"required"`
+	DueDate     time.Time `json:"due_date" binding:"required"`
+}
+
+// UpdateActionItemRequest represents the request payload for updating an action item
+type UpdateActionItemRequest struct {
+	Title       *string    `json:"title,omitempty" binding:"notblank,max=200"`
+	Description *string    `json:"description,omitempty" binding:"max=2000"`
+	OwnerID     *string    `json:"owner_id,omitempty" binding:"notblank"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Status      *string    `json:"status,omitempty" binding:"omitempty,oneof=open in_progress done cancelled"`
+}
+
+// AddActionItemCommentRequest represents the request payload for commenting on an action item
+type AddActionItemCommentRequest struct {
+	AuthorID string `json:"author_id" binding:"required"`
+	Text     string `json:"text" binding:"required,max=2000"`
+}
+
+// ActionItemFilter narrows the action items listed
+type ActionItemFilter struct {
+	OwnerID string
+	Status  []string
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AgeGroup is a class children are placed in by age or school grade. Bounds are
+// inclusive and measured on the most recent promotion date; a group uses either
+// age bounds or grade bounds, not both.
+type AgeGroup struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	MinAge    *int               `bson:"min_age,omitempty" json:"min_age,omitempty"` // Years
+	MaxAge    *int               `bson:"max_age,omitempty" json:"max_age,omitempty"`
+	MinGrade  *int               `bson:"min_grade,omitempty" json:"min_grade,omitempty"` // 0 is kindergarten
+	MaxGrade  *int               `bson:"max_grade,omitempty" json:"max_grade,omitempty"`
+	SortOrder int                `bson:"sort_order" json:"sort_order"` // Youngest first; decides between overlapping groups
+	// MaxChildrenPerAdult overrides the default child-to-minister ratio for the group
+	MaxChildrenPerAdult *int      `bson:"max_children_per_adult,omitempty" json:"max_children_per_adult,omitempty"`
+	CreatedAt           time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt           time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateAgeGroupRequest represents the request payload for creating an age group
+type CreateAgeGroupRequest struct {
+	Name                string `json:"name" binding:"required,max=100"`
+	MinAge              *int   `json:"min_age,omitempty" binding:"min=0"`
+	MaxAge              *int   `json:"max_age,omitempty" binding:"min=0"`
+	MinGrade            *int   `json:"min_grade,omitempty" binding:"min=0"`
+	MaxGrade            *int   `json:"max_grade,omitempty" binding:"min=0"`
+	SortOrder           int    `json:"sort_order"`
+	MaxChildrenPerAdult *int   `json:"max_children_per_adult,omitempty" binding:"min=1"`
+}
+
+// UpdateAgeGroupRequest represents the request payload for updating an age group.
+// When any bound is given all four are replaced, so a group can switch between age
+// and grade bounds.
+type UpdateAgeGroupRequest struct {
+	Name                *string `json:"name,omitempty" binding:"notblank,max=100"`
+	MinAge              *int    `json:"min_age,omitempty" binding:"min=0"`
+	MaxAge              *int    `json:"max_age,omitempty" binding:"min=0"`
+	MinGrade            *int    `json:"min_grade,omitempty" binding:"min=0"`
+	MaxGrade            *int    `json:"max_grade,omitempty" binding:"min=0"`
+	SortOrder           *int    `json:"sort_order,omitempty"`
+	MaxChildrenPerAdult *int    `json:"max_children_per_adult,omitempty" binding:"min=0"` // 0 clears the group's own ratio
+}
+
+// PromotionMove describes a child whose age group changes in a promotion
+type PromotionMove struct {
+	ChildID   primitive.ObjectID `json:"child_id"`
+	FirstName string             `json:"first_name"`
+	LastName  string             `json:"last_name"`
+	From      []string           `json:"from"`
+	To        []string           `json:"to"`
+	Graduates bool               `json:"graduates"` // Aged out of every group and becomes graduated
+}
+
+// PromotionPlan lists the age group moves of a yearly promotion
+type PromotionPlan struct {
+	AsOf      time.Time       `json:"as_of"` // Promotion date ages and grades are measured on
+	Moves     []PromotionMove `json:"moves"`
+	Unchanged int             `json:"unchanged"`
+	Skipped   int             `json:"skipped"` // Children without a date of birth
+	Applied   bool            `json:"applied"`
+}
+
+// ApplyPromotionRequest represents the request payload for applying a promotion
+type ApplyPromotionRequest struct {
+	AsOf      *time.Time `json:"as_of,omitempty"` // Defaults to the next promotion date
+	AppliedBy string     `json:"applied_by,omitempty"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Attendance represents a child's check-in to a service within a week
+type Attendance struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WeekID       primitive.ObjectID `bson:"week_id" json:"week_id"`
+	ServiceName  string             `bson:"service_name" json:"service_name"`
+	ServiceTime  string             `bson:"service_time" json:"service_time"`
+	ChildID      primitive.ObjectID `bson:"child_id" json:"child_id"`
+	SecurityCode string             `bson:"security_code" json:"security_code,omitempty"` // Only returned at check-in
+	CheckedInAt  time.Time          `bson:"checked_in_at" json:"checked_in_at"`
+	CheckedInBy  string             `bson:"checked_in_by,omitempty" json:"checked_in_by,omitempty"` // Minister ID
+	CheckedOutAt *time.Time         `bson:"checked_out_at,omitempty" json:"checked_out_at,omitempty"`
+	Open         bool               `bson:"open,omitempty" json:"-"`                              // Set until check-out; a unique index allows one open check-in per child and week
+	CollectedBy  string             `bson:"collected_by,omitempty" json:"collected_by,omitempty"` // Name of the person who picked the child up
+	CodeAttempts int                `bson:"failed_code_attempts,omitempty" json:"-"`              // Wrong pickup codes tried since check-in or the last unlock
+	Alerts       []AlertFlag        `bson:"-" json:"alerts,omitempty"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// CheckedInService groups the children currently checked in to one service
+type CheckedInService struct {
+	ServiceName string       `json:"service_name"`
+	ServiceTime string       `json:"service_time"`
+	Children    []Attendance `json:"children"`
+}
+
+// CheckInRequest represents the request payload for checking a child in to a service
+type CheckInRequest struct {
+	WeekID      string `json:"week_id" binding:"required"`
+	ServiceName string `json:"service_name" binding:"required"`
+	ServiceTime string `json:"service_time" binding:"required"`
+	ChildID     string `json:"child_id" binding:"required"`
+	CheckedInBy string `json:"checked_in_by,omitempty"`
+}
+
+// CheckOutRequest represents the request payload for checking a child out of a service
+type CheckOutRequest struct {
+	SecurityCode string `json:"security_code" binding:"required"`
+	CollectedBy  string `json:"collected_by" binding:"required,max=100"`
+}
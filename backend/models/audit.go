@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditEntry records who accessed or changed restricted data
+type AuditEntry struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Action     string             `bson:"action" json:"action"`           // e.g. "view", "update"
+	EntityType string             `bson:"entity_type" json:"entity_type"` // e.g. "medical_info"
+	EntityID   primitive.ObjectID `bson:"entity_id" json:"entity_id"`
+	ActorID    string             `bson:"actor_id" json:"actor_id"` // Minister ID of the caller
+	Details    string             `bson:"details,omitempty" json:"details,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Role is a minister role from the managed catalog, e.g. "Leader" or "First Aider"
+type Role struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateRoleRequest represents the request payload for creating a role
+type CreateRoleRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description,omitempty" binding:"max=500"`
+}
+
+// UpdateRoleRequest represents the request payload for updating a role
+type UpdateRoleRequest struct {
+	Name        *string `json:"name,omitempty" binding:"notblank,max=100"`
+	Description *string `json:"description,omitempty" binding:"max=500"`
+}
+
+// CatalogMigrationReport describes how stored age groups and roles were matched to the catalogs
+type CatalogMigrationReport struct {
+	DryRun        bool             `json:"dry_run"`
+	PeopleScanned int              `json:"people_scanned"`
+	PeopleUpdated int              `json:"people_updated"` // Would be updated, on a dry run
+	Renamed       []CatalogRename  `json:"renamed"`
+	Unknown       []CatalogUnknown `json:"unknown"` // Values to add to a catalog before running again
+}
+
+// CatalogRename counts the people whose value was rewritten to its catalog name
+type CatalogRename struct {
+	Field  string `json:"field"` // "age_group" or "roles"
+	From   string `json:"from"`
+	To     string `json:"to"`
+	People int    `json:"people"`
+}
+
+// CatalogUnknown counts the people holding a value no catalog entry matches
+type CatalogUnknown struct {
+	Field  string `json:"field"`
+	Value  string `json:"value"`
+	People int    `json:"people"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Safeguarding compliance types every minister working with children is expected to hold
+const (
+	ComplianceTypeBackgroundCheck     = "background_check"
+	ComplianceTypeChildSafetyTraining = "child_safety_training"
+)
+
+// Compliance item statuses, derived from the expiry date
+const (
+	ComplianceStatusValid    = "valid"
+	ComplianceStatusExpiring = "expiring" // Expires within the warning window
+	ComplianceStatusExpired  = "expired"
+)
+
+// Roster enforcement modes for ministers who are not compliant
+const (
+	ComplianceEnforcementWarn  = "warn"  // Assign anyway and report what is missing
+	ComplianceEnforcementBlock = "block" // Refuse the assignment
+)
+
+// ComplianceGap lists the required safeguarding items a rostered minister does not hold
+type ComplianceGap struct {
+	MinisterID string   `json:"minister_id"`
+	Missing    []string `json:"missing"`
+}
+
+// ComplianceItem is a safeguarding credential held by a minister, such as a background check
+type ComplianceItem struct {
+	ID              primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	MinisterID      primitive.ObjectID  `bson:"minister_id" json:"minister_id"`
+	Type            string              `bson:"type" json:"type"` // e.g. "background_check"
+	IssuedAt        time.Time           `bson:"issued_at" json:"issued_at"`
+	ExpiresAt       time.Time           `bson:"expires_at" json:"expires_at"`
+	DocumentRef     string              `bson:"document_ref,omitempty" json:"document_ref,omitempty"` // Certificate number or file reference
+	ExpiryFlaggedAt *time.Time          `bson:"expiry_flagged_at,omitempty" json:"expiry_flagged_at,omitempty"`
+	SupersededBy    *primitive.ObjectID `bson:"superseded_by,omitempty" json:"superseded_by,omitempty"` // Renewal of the same type
+	Status          string              `bson:"-" json:"status"`
+	Minister        *ComplianceMinister `bson:"-" json:"minister,omitempty"`
+	CreatedAt       time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// ComplianceMinister holds the details needed to chase a minister about an expiring item
+type ComplianceMinister struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Phone     string `json:"phone,omitempty"`
+	Email     string `json:"email,omitempty"`
+}
+
+// MinisterCompliance summarizes whether a minister holds every required item
+type MinisterCompliance struct {
+	MinisterID primitive.ObjectID `json:"minister_id"`
+	Compliant  bool               `json:"compliant"`
+	Missing    []string           `json:"missing"` // Required types without a current item
+	Items      []ComplianceItem   `json:"items"`
+}
+
+// CompliancePolicy defines which items ministers must hold and how rostering treats those who don't
+type CompliancePolicy struct {
+	RequiredTypes     []string `json:"required_types"`
+	Enforcement       string   `json:"enforcement"`         // "warn" or "block"
+	ExpiryWarningDays int      `json:"expiry_warning_days"` // Items expiring this soon are flagged
+}
+
+// CreateComplianceItemRequest represents the request payload for recording a compliance item
+type CreateComplianceItemRequest struct {
+	Type        string    `json:"type" binding:"required,max=100"`
+	IssuedAt    time.Time `json:"issued_at" binding:"required"`
+	ExpiresAt   time.Time `json:"expires_at" binding:"required"`
+	DocumentRef string    `json:"document_ref,omitempty" binding:"max=500"`
+}
+
+// UpdateComplianceItemRequest represents the request payload for correcting a compliance item
+type UpdateComplianceItemRequest struct {
+	IssuedAt    *time.Time `json:"issued_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	DocumentRef *string    `json:"document_ref,omitempty" binding:"max=500"`
+}
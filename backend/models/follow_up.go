@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Follow-up statuses
+const (
+	FollowUpStatusOpen      = "open"
+	FollowUpStatusContacted = "contacted"
+)
+
+// FollowUp represents a regular child who has stopped attending and needs a call
+type FollowUp struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ChildID        primitive.ObjectID `bson:"child_id" json:"child_id"`
+	AttendedWeeks  int                `bson:"attended_weeks" json:"attended_weeks"` // Weeks attended in the lookback window
+	MissedWeeks    int                `bson:"missed_weeks" json:"missed_weeks"`     // Most recent weeks missed in a row
+	LastAttendedAt *time.Time         `bson:"last_attended_at,omitempty" json:"last_attended_at,omitempty"`
+	Status         string             `bson:"status" json:"status"`                                 // "open" or "contacted"
+	ContactedBy    string             `bson:"contacted_by,omitempty" json:"contacted_by,omitempty"` // Minister ID
+	ContactedAt    *time.Time         `bson:"contacted_at,omitempty" json:"contacted_at,omitempty"`
+	Note           string             `bson:"note,omitempty" json:"note,omitempty"`
+	Contact        *GuardianContact   `bson:"-" json:"contact,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// GuardianContact holds the details a minister needs to reach a child's family
+type GuardianContact struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Phone     string `json:"phone,omitempty"`
+	Email     string `json:"email,omitempty"`
+}
+
+// AbsenteeCriteria defines who counts as a regular child who has stopped coming:
+// attended at least MinAttended of the LookbackWeeks weeks before the latest
+// MissedWeeks weeks, and none of those latest weeks
+type AbsenteeCriteria struct {
+	MinAttended   int `json:"min_attended"`   // N
+	LookbackWeeks int `json:"lookback_weeks"` // M
+	MissedWeeks   int `json:"missed_weeks"`   // K
+}
+
+// MarkContactedRequest represents the request payload for marking a follow-up as contacted
+type MarkContactedRequest struct {
+	ContactedBy string `json:"contacted_by" binding:"required"`
+	Note        string `json:"note,omitempty" binding:"max=2000"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Incident types
+const (
+	IncidentTypeInjury       = "injury"
+	IncidentTypeIllness      = "illness"
+	IncidentTypeBehavior     = "behavior"
+	IncidentTypeSafeguarding = "safeguarding"
+	IncidentTypeOther        = "other"
+)
+
+// Incident follow-up statuses
+const (
+	IncidentFollowUpNone      = "none"      // Nothing further to do
+	IncidentFollowUpRequired  = "required"  // Someone still needs to act
+	IncidentFollowUpCompleted = "completed" // Follow-up has been done
+)
+
+// Incident is a report of an injury, illness or behavior issue involving children during a service
+type Incident struct {
+	ID                 primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	WeekID             primitive.ObjectID   `bson:"week_id" json:"week_id"`
+	ServiceName        string               `bson:"service_name" json:"service_name"`
+	ServiceTime        string               `bson:"service_time" json:"service_time"`
+	ChildIDs           []primitive.ObjectID `bson:"child_ids" json:"child_ids"`
+	ReportedBy         string               `bson:"reported_by,omitempty" json:"reported_by,omitempty"` // Minister ID of the caller
+	Type               string               `bson:"type" json:"type"`                                   // e.g. "injury" or "behavior"
+	OccurredAt         time.Time            `bson:"occurred_at" json:"occurred_at"`
+	Description        IncidentDescription  `bson:"description" json:"description"`
+	ActionsTaken       string               `bson:"actions_taken,omitempty" json:"actions_taken,omitempty"`
+	GuardianNotified   bool                 `bson:"guardian_notified" json:"guardian_notified"`
+	GuardianNotifiedAt *time.Time           `bson:"guardian_notified_at,omitempty" json:"guardian_notified_at,omitempty"`
+	FollowUpStatus     string               `bson:"follow_up_status" json:"follow_up_status"` // "none", "required" or "completed"
+	FollowUpNote       string               `bson:"follow_up_note,omitempty" json:"follow_up_note,omitempty"`
+	UpdatedBy          string               `bson:"updated_by,omitempty" json:"updated_by,omitempty"` // Minister ID
+	CreatedAt          time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt          time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// IncidentDescription is the structured account of what happened
+type IncidentDescription struct {
+	Summary   string   `bson:"summary" json:"summary" binding:"required,max=5000"`
+	Location  string   `bson:"location,omitempty" json:"location,omitempty" binding:"max=200"` // e.g. "Playground"
+	Injuries  string   `bson:"injuries,omitempty" json:"injuries,omitempty" binding:"max=2000"`
+	Cause     string   `bson:"cause,omitempty" json:"cause,omitempty" binding:"max=2000"`
+	Witnesses []string `bson:"witnesses,omitempty" json:"witnesses,omitempty"` // Names of people who saw it
+}
+
+// CreateIncidentRequest represents the request payload for reporting an incident
+type CreateIncidentRequest struct {
+	WeekID           string              `json:"week_id" binding:"required"`
+	ServiceName      string              `json:"service_name" binding:"required"`
+	ServiceTime      string              `json:"service_time" binding:"required"`
+	ReportedBy       string              `json:"reported_by" binding:"required"` // Minister ID
+	ChildIDs         []string            `json:"child_ids" binding:"required"`
+	Type             string              `json:"type" binding:"required,oneof=injury illness behavior safeguarding other"`
+	OccurredAt       *time.Time          `json:"occurred_at,omitempty"` // Defaults to now
+	Description      IncidentDescription `json:"description" binding:"required"`
+	ActionsTaken     string              `json:"actions_taken,omitempty" binding:"max=5000"`
+	GuardianNotified bool                `json:"guardian_notified"`
+	FollowUpStatus   string              `json:"follow_up_status,omitempty" binding:"omitempty,oneof=none required completed"` // Defaults to none
+	FollowUpNote     string              `json:"follow_up_note,omitempty" binding:"max=2000"`
+}
+
+// UpdateIncidentRequest represents the request payload for updating an incident report
+type UpdateIncidentRequest struct {
+	Type             *string              `json:"type,omitempty" binding:"omitempty,oneof=injury illness behavior safeguarding other"`
+	Description      *IncidentDescription `json:"description,omitempty"`
+	ActionsTaken     *string              `json:"actions_taken,omitempty" binding:"max=5000"`
+	GuardianNotified *bool                `json:"guardian_notified,omitempty"`
+	FollowUpStatus   *string              `json:"follow_up_status,omitempty" binding:"omitempty,oneof=none required completed"`
+	FollowUpNote     *string              `json:"follow_up_note,omitempty" binding:"max=2000"`
+}
+
+// IncidentFilter narrows the incidents listed
+type IncidentFilter struct {
+	WeekID         *primitive.ObjectID
+	ChildID        *primitive.ObjectID
+	FollowUpStatus string
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Allergy severities, from least to most serious
+const (
+	SeverityMild            = "mild"
+	SeverityModerate        = "moderate"
+	SeveritySevere          = "severe"
+	SeverityLifeThreatening = "life_threatening"
+)
+
+// Alert flag types
+const (
+	AlertTypeAllergy       = "allergy"
+	AlertTypeMedication    = "medication"
+	AlertTypeCondition     = "condition"
+	AlertTypeSpecialNeeds  = "special_needs"
+	AlertTypeEmergencyPlan = "emergency_plan"
+)
+
+// Allergy represents a single allergy and how serious a reaction is
+type Allergy struct {
+	Allergen string `bson:"allergen" json:"allergen" binding:"required,max=100"`
+	Severity string `bson:"severity" json:"severity" binding:"required,oneof=mild moderate severe life_threatening"` // "mild", "moderate", "severe" or "life_threatening"
+	Reaction string `bson:"reaction,omitempty" json:"reaction,omitempty" binding:"max=500"`
+}
+
+// Medication represents a medication a child takes or may need during a service
+type Medication struct {
+	Name         string `bson:"name" json:"name" binding:"required,max=100"`
+	Dosage       string `bson:"dosage,omitempty" json:"dosage,omitempty" binding:"max=100"`
+	Instructions string `bson:"instructions,omitempty" json:"instructions,omitempty" binding:"max=1000"`
+}
+
+// MedicalInfo represents the structured medical record of a child
+type MedicalInfo struct {
+	ID                  primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PersonID            primitive.ObjectID `bson:"person_id" json:"person_id"`
+	Allergies           []Allergy          `bson:"allergies" json:"allergies"`
+	Medications         []Medication       `bson:"medications" json:"medications"`
+	Conditions          []string           `bson:"conditions" json:"conditions"`
+	SpecialNeeds        []string           `bson:"special_needs" json:"special_needs"`
+	EmergencyActionPlan string             `bson:"emergency_action_plan,omitempty" json:"emergency_action_plan,omitempty"`
+	UpdatedBy           string             `bson:"updated_by" json:"updated_by"` // Minister ID
+	CreatedAt           time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt           time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// AlertFlag is a short warning derived from a child's medical record. Flags are
+// shown to everyone serving with the child, but only callers authorized for the
+// record see what the label names; others get a generic label such as "Allergy".
+type AlertFlag struct {
+	Type     string `json:"type"`
+	Label    string `json:"label"`
+	Severity string `json:"severity,omitempty"`
+}
+
+// ChildAlert lists the alert flags of one child attending a service
+type ChildAlert struct {
+	ChildID   primitive.ObjectID `json:"child_id"`
+	FirstName string             `json:"first_name"`
+	LastName  string             `json:"last_name"`
+	Flags     []AlertFlag        `json:"flags"`
+}
+
+// UpsertMedicalInfoRequest represents the request payload for saving a child's medical record
+type UpsertMedicalInfoRequest struct {
+	Allergies           []Allergy    `json:"allergies"`
+	Medications         []Medication `json:"medications"`
+	Conditions          []string     `json:"conditions"`
+	SpecialNeeds        []string     `json:"special_needs"`
+	EmergencyActionPlan string       `json:"emergency_action_plan,omitempty" binding:"max=5000"`
+}
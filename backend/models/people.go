@@ -6,42 +6,80 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// People statuses. A person's status decides which lists they appear on; deletion is
+// only for records entered by mistake.
+const (
+	PeopleStatusProspect  = "prospect"  // Visiting, not yet a regular
+	PeopleStatusActive    = "active"    // On the active lists
+	PeopleStatusInactive  = "inactive"  // Temporarily away, e.g. a minister on sabbatical
+	PeopleStatusGraduated = "graduated" // Moved up out of the children's ministry
+	PeopleStatusAlumni    = "alumni"    // No longer involved
+)
+
 // People represents a person in the church (minister or child)
 type People struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	FirstName string             `bson:"first_name" json:"first_name"`
-	LastName  string             `bson:"last_name" json:"last_name"`
-	Type      string             `bson:"type" json:"type"` // "minister" or "children"
-	AgeGroup  []string           `bson:"age_group,omitempty" json:"age_group,omitempty"`
-	Roles     []string           `bson:"roles,omitempty" json:"roles,omitempty"`
-	Phone     string             `bson:"phone,omitempty" json:"phone,omitempty"`
-	Email     string             `bson:"email,omitempty" json:"email,omitempty"`
-	Notes     string             `bson:"notes,omitempty" json:"notes,omitempty"`
-	Deleted   bool               `bson:"deleted" json:"deleted"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FirstName     string             `bson:"first_name" json:"first_name"`
+	LastName      string             `bson:"last_name" json:"last_name"`
+	Type          string             `bson:"type" json:"type"` // "minister" or "children"
+	AgeGroup      []string           `bson:"age_group,omitempty" json:"age_group,omitempty"`
+	DateOfBirth   *time.Time         `bson:"date_of_birth,omitempty" json:"date_of_birth,omitempty"` // Children with one have their age group assigned automatically
+	Roles         []string           `bson:"roles,omitempty" json:"roles,omitempty"`
+	Household     string             `bson:"household,omitempty" json:"household,omitempty"` // Ministers sharing a household count as one adult for the two-adult rule
+	Phone         string             `bson:"phone,omitempty" json:"phone,omitempty"`
+	Email         string             `bson:"email,omitempty" json:"email,omitempty"`
+	Notes         string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	PhoneIndex    string             `bson:"phone_index,omitempty" json:"-"` // Blind index so encrypted phones can be searched
+	Redacted      bool               `bson:"-" json:"redacted,omitempty"`    // Protected fields were withheld from the caller
+	Alerts        []AlertFlag        `bson:"-" json:"alerts,omitempty"`      // Medical alert flags, children only
+	Status        string             `bson:"status,omitempty" json:"status"` // Records without a status are active
+	StatusHistory []StatusChange     `bson:"status_history,omitempty" json:"status_history,omitempty"`
+	Deleted       bool               `bson:"deleted" json:"deleted"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// StatusChange records a transition of a person's status
+type StatusChange struct {
+	From      string    `bson:"from,omitempty" json:"from,omitempty"` // Empty for the status a person was created with
+	To        string    `bson:"to" json:"to"`
+	Reason    string    `bson:"reason,omitempty" json:"reason,omitempty"`
+	ChangedBy string    `bson:"changed_by,omitempty" json:"changed_by,omitempty"` // Minister ID
+	ChangedAt time.Time `bson:"changed_at" json:"changed_at"`
 }
 
 // CreatePeopleRequest represents the request payload for creating a person
 type CreatePeopleRequest struct {
-	FirstName string   `json:"first_name" binding:"required"`
-	LastName  string   `json:"last_name" binding:"required"`
-	Type      string   `json:"type" binding:"required,oneof=minister children"`
-	AgeGroup  []string `json:"age_group" binding:"required"`
-	Roles     []string `json:"roles" binding:"required"`
-	Phone     string   `json:"phone" binding:"required"`
-	Email     string   `json:"email,omitempty"`
-	Notes     string   `json:"notes,omitempty"`
+	FirstName   string     `json:"first_name" binding:"required,max=100"`
+	LastName    string     `json:"last_name" binding:"required,max=100"`
+	Type        string     `json:"type" binding:"required,oneof=minister children"`
+	AgeGroup    []string   `json:"age_group"` // Required for children without a date of birth, ignored for those with one
+	DateOfBirth *time.Time `json:"date_of_birth,omitempty"`
+	Roles       []string   `json:"roles"` // Required for ministers
+	Household   string     `json:"household,omitempty" binding:"max=100"`
+	Phone       string     `json:"phone" binding:"required,phone"`
+	Email       string     `json:"email,omitempty" binding:"email,max=254"`
+	Notes       string     `json:"notes,omitempty" binding:"max=2000"`
+	Status      string     `json:"status,omitempty" binding:"omitempty,oneof=prospect active inactive graduated alumni"` // Defaults to active
 }
 
 // UpdatePeopleRequest represents the request payload for updating a person
 type UpdatePeopleRequest struct {
-	FirstName *string   `json:"first_name,omitempty"`
-	LastName  *string   `json:"last_name,omitempty"`
-	Type      *string   `json:"type,omitempty" binding:"omitempty,oneof=minister children"`
-	AgeGroup  []string  `json:"age_group,omitempty"`
-	Roles     []string  `json:"roles,omitempty"`
-	Phone     *string   `json:"phone,omitempty"`
-	Email     *string   `json:"email,omitempty"`
-	Notes     *string   `json:"notes,omitempty"`
-}
\ No newline at end of file
+	FirstName   *string    `json:"first_name,omitempty" binding:"notblank,max=100"`
+	LastName    *string    `json:"last_name,omitempty" binding:"notblank,max=100"`
+	Type        *string    `json:"type,omitempty" binding:"omitempty,oneof=minister children"`
+	AgeGroup    []string   `json:"age_group,omitempty"` // Ignored for children with a date of birth
+	DateOfBirth *time.Time `json:"date_of_birth,omitempty"`
+	Roles       []string   `json:"roles,omitempty"`
+	Household   *string    `json:"household,omitempty" binding:"max=100"`
+	Phone       *string    `json:"phone,omitempty" binding:"notblank,phone"`
+	Email       *string    `json:"email,omitempty" binding:"email,max=254"`
+	Notes       *string    `json:"notes,omitempty" binding:"max=2000"`
+}
+
+// ChangeStatusRequest represents the request payload for changing a person's status
+type ChangeStatusRequest struct {
+	Status    string `json:"status" binding:"required,oneof=prospect active inactive graduated alumni"`
+	Reason    string `json:"reason" binding:"required,max=500"`
+	ChangedBy string `json:"changed_by,omitempty"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SubjectAccessExport bundles everything held about a person for a subject-access request
+type SubjectAccessExport struct {
+	GeneratedAt        time.Time           `json:"generated_at"`
+	Person             People              `json:"person"`
+	MedicalInfo        *MedicalInfo        `json:"medical_info,omitempty"`
+	ComplianceItems    []ComplianceItem    `json:"compliance_items"` // Safeguarding items of a minister
+	Attendance         []Attendance        `json:"attendance"`       // Check-ins of the child, or check-ins made by the minister
+	GuardianLinks      []GuardianLink      `json:"guardian_links"`   // Adults who collected the child
+	FollowUps          []FollowUp          `json:"follow_ups"`       // Follow-ups about the child, or made by the minister
+	Incidents          []Incident          `json:"incidents"`        // Incidents involving the child, or reported by the minister
+	ActionItems        []ActionItem        `json:"action_items"`     // Action items the minister owns or commented on
+	ReviewComments     []*ReviewComment    `json:"review_comments"`  // Review comments the minister wrote or was mentioned in
+	ServiceAssignments []ServiceAssignment `json:"service_assignments"`
+	ReviewMentions     []ReviewMention     `json:"review_mentions"`
+	AuditEntries       []AuditEntry        `json:"audit_entries"` // Entries about the person or made by them
+}
+
+// GuardianLink records an adult who collected a child at check-out
+type GuardianLink struct {
+	CollectedBy     string    `json:"collected_by"`
+	Times           int       `json:"times"`
+	LastCollectedAt time.Time `json:"last_collected_at"`
+}
+
+// ServiceAssignment records a service a minister was in charge of
+type ServiceAssignment struct {
+	WeekID      primitive.ObjectID `json:"week_id"`
+	WeekStart   time.Time          `json:"week_start"`
+	ServiceName string             `json:"service_name"`
+	ServiceTime string             `json:"service_time"`
+}
+
+// ReviewMention records a review field that mentions a person by name
+type ReviewMention struct {
+	ReviewID primitive.ObjectID `json:"review_id"`
+	WeekID   primitive.ObjectID `json:"week_id"`
+	Field    string             `json:"field"` // e.g. "what_went_well"
+	Text     string             `json:"text"`  // Plain text of the field
+}
+
+// ErasureTombstone is kept in place of a person who has been erased so the erasure can be proven
+type ErasureTombstone struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PersonID   primitive.ObjectID `bson:"person_id" json:"person_id"`
+	PersonType string             `bson:"person_type" json:"person_type"`
+	Reason     string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	ErasedBy   string             `bson:"erased_by" json:"erased_by"` // Minister ID of the caller
+	Removed    ErasureSummary     `bson:"removed" json:"removed"`
+	Pending    bool               `bson:"pending,omitempty" json:"pending,omitempty"` // The erasure started but has not finished; erasing again completes it
+	ErasedAt   time.Time          `bson:"erased_at" json:"erased_at"`
+}
+
+// ErasureSummary counts the records removed or anonymized by an erasure
+type ErasureSummary struct {
+	Attendance         int64 `bson:"attendance" json:"attendance"`                   // Anonymized
+	FollowUps          int64 `bson:"follow_ups" json:"follow_ups"`                   // Removed, or anonymized when made by the minister
+	MedicalInfo        int64 `bson:"medical_info" json:"medical_info"`               // Removed
+	ComplianceItems    int64 `bson:"compliance_items" json:"compliance_items"`       // Removed
+	Incidents          int64 `bson:"incidents" json:"incidents"`                     // Anonymized
+	ActionItems        int64 `bson:"action_items" json:"action_items"`               // Anonymized
+	ReviewComments     int64 `bson:"review_comments" json:"review_comments"`         // Anonymized
+	ServiceAssignments int64 `bson:"service_assignments" json:"service_assignments"` // Weeks the minister was removed from the roster of
+	ReviewMentions     int64 `bson:"review_mentions" json:"review_mentions"`         // Reviews with the name removed
+	Reviews            int64 `bson:"reviews" json:"reviews"`                         // Reviews the minister wrote, approved or moved through the workflow, kept without them
+}
+
+// EraseRequest represents the request payload for erasing a person
+type EraseRequest struct {
+	Reason string `json:"reason,omitempty" binding:"max=500"`
+}
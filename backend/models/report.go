@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AttendanceTrend compares a report's total with the earlier weeks of the same series.
+// Weeks without attendance count as 0.
+type AttendanceTrend struct {
+	RollingAverage float64  `json:"rolling_average"` // Average total of the last weeks, up to the rolling window
+	Change         *int     `json:"change"`          // Difference from the previous week, nil for the first week
+	ChangePercent  *float64 `json:"change_percent"`  // Change relative to the previous week, nil when it had no attendance
+	PreviousTotal  *int     `json:"previous_total"`  // Total of the previous week, nil for the first week
+}
+
+// WeeklyAttendanceReport represents attendance totals and trends for one week
+type WeeklyAttendanceReport struct {
+	WeekID          primitive.ObjectID `bson:"_id" json:"week_id"`
+	StartTime       time.Time          `bson:"start_time" json:"start_time"`
+	EndTime         time.Time          `bson:"end_time" json:"end_time"`
+	Total           int                `bson:"total" json:"total"`
+	FirstTime       int                `bson:"first_time" json:"first_time"`
+	AttendanceTrend `bson:"-"`
+}
+
+// ServiceAttendanceReport represents attendance for one service of a week, with trends
+// over the earlier weeks the service was held
+type ServiceAttendanceReport struct {
+	WeekID          primitive.ObjectID `bson:"week_id" json:"week_id"`
+	StartTime       time.Time          `bson:"start_time" json:"start_time"`
+	ServiceName     string             `bson:"service_name" json:"service_name"`
+	ServiceTime     string             `bson:"service_time" json:"service_time"`
+	Total           int                `bson:"total" json:"total"`
+	FirstTime       int                `bson:"first_time" json:"first_time"`
+	AttendanceTrend `bson:"-"`
+}
+
+// AgeGroupAttendanceReport represents attendance for one age group in a week, with trends
+// over the earlier weeks since the age group was first attended
+type AgeGroupAttendanceReport struct {
+	WeekID          primitive.ObjectID `bson:"week_id" json:"week_id"`
+	StartTime       time.Time          `bson:"start_time" json:"start_time"`
+	AgeGroup        string             `bson:"age_group" json:"age_group"`
+	Total           int                `bson:"total" json:"total"`
+	FirstTime       int                `bson:"first_time" json:"first_time"`
+	AttendanceTrend `bson:"-"`
+}
+
+// ServiceRatingReport averages the review ratings of one service, for one week or over a date range.
+// Averages are nil when no review rated that area.
+type ServiceRatingReport struct {
+	WeekID      *primitive.ObjectID `bson:"week_id,omitempty" json:"week_id,omitempty"`       // Set for weekly reports
+	StartTime   *time.Time          `bson:"start_time,omitempty" json:"start_time,omitempty"` // Set for weekly reports
+	ServiceName string              `bson:"service_name" json:"service_name"`                 // Empty for reviews of the whole week
+	ServiceTime string              `bson:"service_time" json:"service_time"`
+	Reviews     int                 `bson:"reviews" json:"reviews"`
+	Engagement  *float64            `bson:"engagement" json:"engagement"`
+	Preparation *float64            `bson:"preparation" json:"preparation"`
+	Punctuality *float64            `bson:"punctuality" json:"punctuality"`
+	Safety      *float64            `bson:"safety" json:"safety"`
+	Overall     *float64            `bson:"-" json:"overall"` // Average of the areas rated
+}
+
+// AttendanceReportFilter limits a report to weeks starting within a date range
+type AttendanceReportFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Window int // Number of weeks in the rolling average
+}
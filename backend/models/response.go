@@ -0,0 +1,25 @@
+package models
+
+// APIResponse is the envelope of every JSON response. Successful responses set Data and,
+// for actions such as deletes, Message; failed ones set Error.
+type APIResponse struct {
+	Success bool                   `json:"success"`
+	Data    interface{}            `json:"data,omitempty"`
+	Message string                 `json:"message,omitempty"`
+	Meta    map[string]interface{} `json:"meta,omitempty"` // Context for the data, such as the policy a report was checked against
+	Error   *APIError              `json:"error,omitempty"`
+}
+
+// APIError describes why a request failed
+type APIError struct {
+	Code    string       `json:"code"` // Machine-readable, e.g. "not_found" or "validation_failed"
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"` // Request fields at fault, for validation and reference errors
+}
+
+// FieldError names a request field that broke a rule
+type FieldError struct {
+	Field   string `json:"field"` // Path of the field, e.g. "phone" or "services[0].name"
+	Rule    string `json:"rule"`  // e.g. "required", "max" or "reference"
+	Message string `json:"message"`
+}
@@ -6,32 +6,86 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// Review represents a weekly church review
+// Review statuses
+const (
+	ReviewStatusDraft            = "draft"             // Being written
+	ReviewStatusSubmitted        = "submitted"         // Waiting for approval
+	ReviewStatusChangesRequested = "changes_requested" // Sent back to the author
+	ReviewStatusApproved         = "approved"          // Shared, and read-only until reopened
+)
+
+// Review represents a weekly church review, of the whole week or of one of its services.
+// Answers follow the template version the review was written against. The default
+// template's answers are also kept in WhatWentWell, CanImprove and ActionPlans.
 type Review struct {
-	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	WeekID       primitive.ObjectID `bson:"week_id" json:"week_id"`
-	WhatWentWell string             `bson:"what_went_well" json:"what_went_well"`
-	CanImprove   string             `bson:"can_improve" json:"can_improve"`
-	ActionPlans  string             `bson:"action_plans" json:"action_plans"`
-	Summary      string             `bson:"summary" json:"summary"`
-	Deleted      bool               `bson:"deleted" json:"deleted"`
-	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+	ID              primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	WeekID          primitive.ObjectID   `bson:"week_id" json:"week_id"`
+	ServiceName     string               `bson:"service_name,omitempty" json:"service_name,omitempty"` // Empty for a review of the whole week
+	ServiceTime     string               `bson:"service_time,omitempty" json:"service_time,omitempty"`
+	TemplateID      primitive.ObjectID   `bson:"template_id,omitempty" json:"template_id"`
+	TemplateVersion int                  `bson:"template_version,omitempty" json:"template_version"`
+	Answers         []ReviewAnswer       `bson:"answers" json:"answers"`
+	WhatWentWell    string               `bson:"what_went_well" json:"what_went_well"`
+	CanImprove      string               `bson:"can_improve" json:"can_improve"`
+	ActionPlans     string               `bson:"action_plans" json:"action_plans"`
+	Summary         string               `bson:"summary" json:"summary"`
+	Ratings         *ReviewRatings       `bson:"ratings,omitempty" json:"ratings,omitempty"`
+	Status          string               `bson:"status,omitempty" json:"status"` // Reviews written before the workflow have none and count as approved
+	StatusHistory   []ReviewStatusChange `bson:"status_history,omitempty" json:"status_history,omitempty"`
+	SubmittedAt     *time.Time           `bson:"submitted_at,omitempty" json:"submitted_at,omitempty"`
+	ApprovedAt      *time.Time           `bson:"approved_at,omitempty" json:"approved_at,omitempty"`
+	ApprovedBy      string               `bson:"approved_by,omitempty" json:"approved_by,omitempty"` // Minister ID
+	AuthorID        string               `bson:"author_id,omitempty" json:"author_id,omitempty"`     // Minister who created the review, when they identified themselves
+	Deleted         bool                 `bson:"deleted" json:"deleted"`
+	CreatedAt       time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// ReviewRatings are the structured scores of a review, each from 1 to 5. Unrated areas are left out.
+type ReviewRatings struct {
+	Engagement  *int `bson:"engagement,omitempty" json:"engagement,omitempty" binding:"min=1,max=5"`
+	Preparation *int `bson:"preparation,omitempty" json:"preparation,omitempty" binding:"min=1,max=5"`
+	Punctuality *int `bson:"punctuality,omitempty" json:"punctuality,omitempty" binding:"min=1,max=5"`
+	Safety      *int `bson:"safety,omitempty" json:"safety,omitempty" binding:"min=1,max=5"`
+}
+
+// ReviewStatusChange records a step of a review through the approval workflow
+type ReviewStatusChange struct {
+	From      string    `bson:"from,omitempty" json:"from,omitempty"` // Empty for the status a review was created with
+	To        string    `bson:"to" json:"to"`
+	Comment   string    `bson:"comment,omitempty" json:"comment,omitempty"`       // e.g. the changes a reviewer asked for
+	ChangedBy string    `bson:"changed_by,omitempty" json:"changed_by,omitempty"` // Minister ID
+	ChangedAt time.Time `bson:"changed_at" json:"changed_at"`
+}
+
+// ChangeReviewStatusRequest represents the request payload for moving a review through the workflow
+type ChangeReviewStatusRequest struct {
+	Status  string `json:"status" binding:"required,oneof=draft submitted changes_requested approved"`
+	Comment string `json:"comment,omitempty" binding:"max=2000"` // Required when requesting changes or reopening
 }
 
 // CreateReviewRequest represents the request payload for creating a review
 type CreateReviewRequest struct {
-	WeekID       string `json:"week_id" binding:"required"`
-	WhatWentWell string `json:"what_went_well" binding:"required"`
-	CanImprove   string `json:"can_improve" binding:"required"`
-	ActionPlans  string `json:"action_plans" binding:"required"`
-	Summary      string `json:"summary"`
+	WeekID       string         `json:"week_id" binding:"required"`
+	ServiceName  string         `json:"service_name,omitempty"` // With service_time, reviews one service of the week
+	ServiceTime  string         `json:"service_time,omitempty"`
+	TemplateID   string         `json:"template_id,omitempty"` // Defaults to the built-in template
+	Answers      []ReviewAnswer `json:"answers,omitempty"`
+	WhatWentWell string         `json:"what_went_well,omitempty" binding:"max=20000"` // Answers of the built-in template
+	CanImprove   string         `json:"can_improve,omitempty" binding:"max=20000"`
+	ActionPlans  string         `json:"action_plans,omitempty" binding:"max=20000"`
+	Summary      string         `json:"summary" binding:"max=20000"`
+	Ratings      *ReviewRatings `json:"ratings,omitempty"`
 }
 
 // UpdateReviewRequest represents the request payload for updating a review
 type UpdateReviewRequest struct {
-	WhatWentWell *string `json:"what_went_well,omitempty"`
-	CanImprove   *string `json:"can_improve,omitempty"`
-	ActionPlans  *string `json:"action_plans,omitempty"`
-	Summary      *string `json:"summary,omitempty"`
-}
\ No newline at end of file
+	ServiceName  *string        `json:"service_name,omitempty"` // Empty name and time make it a review of the whole week
+	ServiceTime  *string        `json:"service_time,omitempty"`
+	Answers      []ReviewAnswer `json:"answers,omitempty"` // Replaces all the review's answers
+	WhatWentWell *string        `json:"what_went_well,omitempty" binding:"max=20000"`
+	CanImprove   *string        `json:"can_improve,omitempty" binding:"max=20000"`
+	ActionPlans  *string        `json:"action_plans,omitempty" binding:"max=20000"`
+	Summary      *string        `json:"summary,omitempty" binding:"max=20000"`
+	Ratings      *ReviewRatings `json:"ratings,omitempty"` // Replaces all the review's ratings
+}
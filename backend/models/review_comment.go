@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReviewComment is a team member's response to a review, or a reply to another comment
+type ReviewComment struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	ReviewID  primitive.ObjectID  `bson:"review_id" json:"review_id"`
+	ParentID  *primitive.ObjectID `bson:"parent_id,omitempty" json:"parent_id,omitempty"` // Comment replied to; empty for a new thread
+	AuthorID  string              `bson:"author_id" json:"author_id"`                     // Minister ID
+	Text      string              `bson:"text" json:"text"`
+	Mentions  []string            `bson:"mentions" json:"mentions"` // Minister IDs @-mentioned in the text
+	EditedAt  *time.Time          `bson:"edited_at,omitempty" json:"edited_at,omitempty"`
+	Deleted   bool                `bson:"deleted" json:"deleted"`
+	DeletedAt *time.Time          `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	// Replies holds the answers to the comment, oldest first, when comments are listed as threads
+	Replies   []*ReviewComment `bson:"-" json:"replies,omitempty"`
+	CreatedAt time.Time        `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time        `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateReviewCommentRequest represents the request payload for commenting on a review
+type CreateReviewCommentRequest struct {
+	AuthorID string `json:"author_id" binding:"required"`
+	Text     string `json:"text" binding:"required,max=5000"`
+	ParentID string `json:"parent_id,omitempty"` // Set to reply to a comment of the same review
+}
+
+// UpdateReviewCommentRequest represents the request payload for editing a review comment
+type UpdateReviewCommentRequest struct {
+	Text string `json:"text" binding:"required,max=5000"`
+}
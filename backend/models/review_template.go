@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Review template question kinds
+const (
+	QuestionKindLongText    = "long_text"
+	QuestionKindRating      = "rating"
+	QuestionKindYesNo       = "yes_no"
+	QuestionKindMultiChoice = "multi_choice"
+)
+
+// ReviewTemplate is a set of ordered questions a ministry team answers in its reviews.
+// Every edit creates a new version; reviews keep the version they were written against.
+type ReviewTemplate struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Version     int                `bson:"version" json:"version"` // Starts at 1
+	Questions   []TemplateQuestion `bson:"questions" json:"questions"`
+	BuiltIn     bool               `bson:"built_in" json:"built_in"` // The default template, which cannot be deleted
+	Deleted     bool               `bson:"deleted" json:"deleted"`   // Deleted templates stay readable but take no new reviews
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// TemplateQuestion is one question of a review template
+type TemplateQuestion struct {
+	ID            string   `bson:"id" json:"id"` // Stable across versions so answers can be compared
+	Prompt        string   `bson:"prompt" json:"prompt" binding:"required,max=500"`
+	Kind          string   `bson:"kind" json:"kind" binding:"required,oneof=long_text rating yes_no multi_choice"` // "long_text", "rating", "yes_no" or "multi_choice"
+	Required      bool     `bson:"required" json:"required"`
+	Options       []string `bson:"options,omitempty" json:"options,omitempty" binding:"max=20"` // Choices of a multi-choice question
+	AllowMultiple bool     `bson:"allow_multiple,omitempty" json:"allow_multiple,omitempty"`    // Whether several choices may be picked
+}
+
+// ReviewTemplateVersion is a snapshot of a template as it was at one version
+type ReviewTemplateVersion struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TemplateID  primitive.ObjectID `bson:"template_id" json:"template_id"`
+	Version     int                `bson:"version" json:"version"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Questions   []TemplateQuestion `bson:"questions" json:"questions"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// ReviewAnswer is the answer to one template question. Only the field matching the
+// question's kind is set.
+type ReviewAnswer struct {
+	QuestionID string   `bson:"question_id" json:"question_id" binding:"required"`
+	Text       string   `bson:"text,omitempty" json:"text,omitempty" binding:"max=20000"`       // long_text
+	Rating     *int     `bson:"rating,omitempty" json:"rating,omitempty" binding:"min=1,max=5"` // rating, from 1 to 5
+	Yes        *bool    `bson:"yes,omitempty" json:"yes,omitempty"`                             // yes_no
+	Choices    []string `bson:"choices,omitempty" json:"choices,omitempty"`                     // multi_choice
+}
+
+// CreateReviewTemplateRequest represents the request payload for creating a review template
+type CreateReviewTemplateRequest struct {
+	Name        string             `json:"name" binding:"required,max=100"`
+	Description string             `json:"description,omitempty" binding:"max=500"`
+	Questions   []TemplateQuestion `json:"questions" binding:"required"` // Question IDs are generated when left out
+}
+
+// UpdateReviewTemplateRequest represents the request payload for editing a review template.
+// Questions replace the template's questions; keep a question's ID to keep it the same question.
+type UpdateReviewTemplateRequest struct {
+	Name        *string            `json:"name,omitempty" binding:"notblank,max=100"`
+	Description *string            `json:"description,omitempty" binding:"max=500"`
+	Questions   []TemplateQuestion `json:"questions,omitempty"`
+}
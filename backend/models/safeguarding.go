@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Safeguarding rules a service roster is checked against
+const (
+	SafeguardingRuleTwoAdults = "two_adults" // Enough unrelated adults in the room
+	SafeguardingRuleRatio     = "ratio"      // Enough adults for the children in the room
+)
+
+// Sources of the child count a service is checked with
+const (
+	ChildCountActual   = "actual"   // Children checked in to the service
+	ChildCountExpected = "expected" // The service's expected_children, before anyone has checked in
+)
+
+// SafeguardingPolicy holds the rules every service roster must meet. Age groups may
+// set a stricter ratio of their own with max_children_per_adult.
+type SafeguardingPolicy struct {
+	MinAdults               int `json:"min_adults"`                 // Unrelated adults required in every room
+	DefaultChildrenPerAdult int `json:"default_children_per_adult"` // Ratio for age groups without their own
+}
+
+// SafeguardingViolation describes a rule a service roster breaks
+type SafeguardingViolation struct {
+	Rule     string `json:"rule"` // "two_adults" or "ratio"
+	Message  string `json:"message"`
+	Required int    `json:"required"`
+	Actual   int    `json:"actual"`
+}
+
+// ServiceSafeguarding is the result of checking one service roster
+type ServiceSafeguarding struct {
+	ServiceName     string                  `json:"service_name"`
+	ServiceTime     string                  `json:"service_time"`
+	Adults          int                     `json:"adults"`           // Ministers rostered, SIC included
+	UnrelatedAdults int                     `json:"unrelated_adults"` // Adults from different households
+	Children        int                     `json:"children"`
+	ChildrenSource  string                  `json:"children_source"` // "actual" or "expected"
+	RequiredAdults  int                     `json:"required_adults"` // Adults needed for the children by ratio
+	Violations      []SafeguardingViolation `json:"violations"`
+}
+
+// SafeguardingReport lists the safeguarding checks of every service in a week
+type SafeguardingReport struct {
+	WeekID     primitive.ObjectID    `json:"week_id"`
+	StartTime  time.Time             `json:"start_time"`
+	Services   []ServiceSafeguarding `json:"services"`
+	Violations int                   `json:"violations"` // Total across services
+}
@@ -0,0 +1,27 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Search result types
+const (
+	SearchTypePerson = "person"
+	SearchTypeReview = "review"
+	SearchTypeWeek   = "week"
+)
+
+// SearchResult is a person, review or week matching a search, with the matching text highlighted
+type SearchResult struct {
+	Type     string              `json:"type"` // "person", "review" or "week"
+	ID       primitive.ObjectID  `json:"id"`
+	Title    string              `json:"title"`
+	WeekID   *primitive.ObjectID `json:"week_id,omitempty"` // Week a review belongs to
+	Score    float64             `json:"score"`
+	Snippets []SearchSnippet     `json:"snippets"`
+}
+
+// SearchSnippet is an excerpt of a matching field. Text is HTML-escaped with the
+// matched words wrapped in <mark>.
+type SearchSnippet struct {
+	Field string `json:"field"` // e.g. "notes" or "what_went_well"
+	Text  string `json:"text"`
+}
@@ -8,9 +8,20 @@ import (
 
 // Service represents a church service within a week
 type Service struct {
-	Name string `bson:"name" json:"name"`
-	Time string `bson:"time" json:"time"`
+	Name string `bson:"name" json:"name" binding:"required,max=100"`
+	Time string `bson:"time" json:"time" binding:"required,max=20"`
 	SIC  string `bson:"sic" json:"sic"` // Service in Charge (Minister ID)
+	// Ministers lists the IDs of the other ministers serving in the room
+	Ministers []string `bson:"ministers,omitempty" json:"ministers,omitempty"`
+	// ExpectedChildren is used for the ratio check until children check in
+	ExpectedChildren int `bson:"expected_children,omitempty" json:"expected_children,omitempty" binding:"min=0"`
+	// Alerts lists children with medical alert flags checked in to this service
+	Alerts []ChildAlert `bson:"-" json:"alerts,omitempty"`
+	// MissingCompliance lists the rostered ministers, SIC first, who do not hold every
+	// required safeguarding item for this week
+	MissingCompliance []ComplianceGap `bson:"-" json:"missing_compliance,omitempty"`
+	// Violations lists the safeguarding rules the roster breaks
+	Violations []SafeguardingViolation `bson:"-" json:"violations,omitempty"`
 }
 
 // Week represents a church week entity
@@ -21,6 +32,8 @@ type Week struct {
 	Services  []Service          `bson:"services" json:"services"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	// ActionItems lists the week's action items and open ones carried over from earlier weeks
+	ActionItems []ActionItem `bson:"-" json:"action_items,omitempty"`
 }
 
 // CreateWeekRequest represents the request payload for creating a week
@@ -33,4 +46,11 @@ type CreateWeekRequest struct {
 // UpdateWeekServicesRequest represents the request payload for updating week services
 type UpdateWeekServicesRequest struct {
 	Services []Service `json:"services" binding:"required"`
+}
+
+// CloneWeekRequest represents the request payload for cloning a week to a new date range
+type CloneWeekRequest struct {
+	StartTime       time.Time `json:"start_time" binding:"required"`
+	EndTime         time.Time `json:"end_time" binding:"required"`
+	KeepAssignments bool      `json:"keep_assignments"` // Keep the SIC and ministers of each service instead of clearing them
 }
\ No newline at end of file
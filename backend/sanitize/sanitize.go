@@ -0,0 +1,148 @@
+// Package sanitize cleans the rich text written in the review editor and returned by
+// the AI summarizer. HTML keeps an allowlist of formatting tags and safe links and drops
+// everything else; PlainText renders the same text without markup for search and exports.
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// droppedTags are removed together with their content
+var droppedTags = []string{
+	"script", "style", "iframe", "object", "applet",
+	"noscript", "noembed", "noframes", "template", "textarea",
+	"select", "svg", "math", "title", "head", "xmp", "plaintext",
+}
+
+// blockTags start a new line in plain text
+var blockTags = map[string]bool{
+	"p": true, "div": true, "br": true, "hr": true, "pre": true, "blockquote": true,
+	"ul": true, "ol": true, "li": true, "tr": true, "table": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+var (
+	horizontalSpace = regexp.MustCompile(`[ \t\f\v\r]+`)
+	blankLines      = regexp.MustCompile(`\n{3,}`)
+	dropped         = make(map[string]bool)
+	policy          = newPolicy()
+)
+
+func init() {
+	for _, tag := range droppedTags {
+		dropped[tag] = true
+	}
+}
+
+// newPolicy returns the allowlist HTML applies: formatting tags without attributes, and
+// links with http, https, mailto, tel or relative URLs
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements(
+		"p", "br", "hr",
+		"strong", "b", "em", "i", "u", "s", "strike", "mark",
+		"code", "pre", "blockquote",
+		"ul", "ol", "li",
+		"h1", "h2", "h3", "h4", "h5", "h6",
+	)
+	p.AllowAttrs("start").Matching(regexp.MustCompile(`^[0-9]+$`)).OnElements("ol")
+
+	// A link whose URL is dropped keeps its text and the rest of its attributes
+	p.AllowNoAttrs().OnElements("a")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowAttrs("title").OnElements("a")
+	p.AllowAttrs("target").Matching(regexp.MustCompile(`^_blank$`)).OnElements("a")
+	p.AllowURLSchemes("http", "https", "mailto", "tel")
+	p.AllowRelativeURLs(true)
+	p.RequireParseableURLs(true)
+	// Links get rel="noreferrer", and those opened in a new tab also "noopener", keeping
+	// the opened page from reaching back into ours
+	p.RequireNoReferrerOnLinks(true)
+
+	p.SkipElementsContent(droppedTags...)
+	return p
+}
+
+// HTML returns input with only allowlisted tags and attributes. Scripts, styles and
+// embedded content are removed with their content, event handlers and styles are
+// dropped, links are kept only with http, https, mailto, tel or relative URLs, and
+// unclosed tags are closed.
+func HTML(input string) string {
+	// Parsing first closes and nests tags the way a browser would, so the allowlist
+	// sees the same elements the reader's browser will
+	nodes, err := parse(input)
+	if err != nil {
+		return ""
+	}
+	var balanced strings.Builder
+	for _, node := range nodes {
+		if err := html.Render(&balanced, node); err != nil {
+			return ""
+		}
+	}
+	return strings.TrimSpace(policy.Sanitize(balanced.String()))
+}
+
+// PlainText renders rich text as plain text: tags are removed, entities decoded,
+// paragraphs and line breaks become new lines and list items start with "- ".
+// Content HTML would drop, such as scripts, is left out.
+func PlainText(input string) string {
+	nodes, err := parse(input)
+	if err != nil {
+		return ""
+	}
+	var out strings.Builder
+	for _, node := range nodes {
+		writeText(&out, node)
+	}
+
+	lines := strings.Split(out.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(horizontalSpace.ReplaceAllString(line, " "))
+	}
+	text := strings.Join(lines, "\n")
+	return strings.TrimSpace(blankLines.ReplaceAllString(text, "\n\n"))
+}
+
+// parse parses input as the content of a document body
+func parse(input string) ([]*html.Node, error) {
+	return html.ParseFragment(strings.NewReader(input), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+}
+
+// writeText writes the text of node and its children, with new lines for block tags
+func writeText(out *strings.Builder, node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		out.WriteString(node.Data)
+		return
+	case html.ElementNode:
+		if dropped[node.Data] {
+			return
+		}
+	default:
+		return
+	}
+
+	switch {
+	case node.Data == "li":
+		// The next item or the end of the list ends the line
+		out.WriteString("\n- ")
+	case blockTags[node.Data]:
+		out.WriteString("\n")
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		writeText(out, child)
+	}
+	if blockTags[node.Data] && node.Data != "li" && node.FirstChild != nil {
+		out.WriteString("\n")
+	}
+}
@@ -0,0 +1,104 @@
+package sanitize
+
+import "testing"
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"formatting kept", `<p>Hello <strong>world</strong></p>`, `<p>Hello <strong>world</strong></p>`},
+		{"text escaped", `1 < 2 & 3 > 2`, `1 &lt; 2 &amp; 3 &gt; 2`},
+		{"unknown tag keeps content", `<div><span>kept</span></div>`, `kept`},
+
+		// Links
+		{"http link", `<a href="https://example.com">x</a>`, `<a href="https://example.com" rel="noreferrer">x</a>`},
+		{"relative link", `<a href="/reviews/1">x</a>`, `<a href="/reviews/1" rel="noreferrer">x</a>`},
+		{"javascript link", `<a href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{"javascript upper case", `<a href="JaVaScRiPt:alert(1)">x</a>`, `<a>x</a>`},
+		{"javascript named entity", `<a href="javascript&colon;alert(1)">x</a>`, `<a>x</a>`},
+		{"javascript numeric entities", `<a href="&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;alert(1)">x</a>`, `<a>x</a>`},
+		{"javascript hex entities", `<a href="&#x6A;avascript&#x3A;alert(1)">x</a>`, `<a>x</a>`},
+		{"javascript tab inside scheme", "<a href=\"java\tscript:alert(1)\">x</a>", `<a>x</a>`},
+		{"javascript newline entity inside scheme", `<a href="java&#10;script:alert(1)">x</a>`, `<a>x</a>`},
+		{"javascript leading whitespace", `<a href="  javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{"javascript unquoted", `<a href=javascript:alert(1)>x</a>`, `<a>x</a>`},
+		{"vbscript link", `<a href="vbscript:msgbox(1)">x</a>`, `<a>x</a>`},
+		{"data link", `<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`, `<a>x</a>`},
+		{"data link with entity", `<a href="data&#58;text/html,<script>alert(1)</script>">x</a>`, `<a>x</a>`},
+		{"data image", `<img src="data:image/svg+xml,<svg onload=alert(1)>">`, ``},
+		{"colon before path", `<a href="javascript%3Aalert(1):x">x</a>`, `<a>x</a>`},
+		{"target blank gets rel", `<a href="/x" target="_blank">x</a>`, `<a href="/x" target="_blank" rel="noreferrer noopener">x</a>`},
+
+		// Event handlers and other attributes
+		{"onclick", `<p onclick="alert(1)">x</p>`, `<p>x</p>`},
+		{"onmouseover on link", `<a href="/x" onmouseover="alert(1)">x</a>`, `<a href="/x" rel="noreferrer">x</a>`},
+		{"upper case handler", `<b OnClick="alert(1)">x</b>`, `<b>x</b>`},
+		{"unquoted handler", `<em onfocus=alert(1) autofocus>x</em>`, `<em>x</em>`},
+		{"handler after slash", `<strong/onclick="alert(1)">x</strong>`, `<strong>x</strong>`},
+		{"handler in quoted value", `<a title="x onclick=alert(1)">x</a>`, `<a title="x onclick=alert(1)">x</a>`},
+		{"img onerror", `<img src=x onerror="alert(1)">`, ``},
+		{"style attribute", `<p style="background:url(javascript:alert(1))">x</p>`, `<p>x</p>`},
+
+		// Dropped elements
+		{"script", `<p>a</p><script>alert(1)</script><p>b</p>`, `<p>a</p><p>b</p>`},
+		{"script upper case", `a<SCRIPT>alert(1)</SCRIPT>b`, `ab`},
+		{"script with markup inside", `a<script>document.write("<p>x</p>")</script>b`, `ab`},
+		{"script end tag in string", `a<script>var s = "</p>";</script>b`, `ab`},
+		{"style", `a<style>body { display: none }</style>b`, `ab`},
+		{"style with tags inside", `a<style>p::after { content: "<b>" }</style>b`, `ab`},
+		{"unclosed script", `a<script>alert(1)`, `a`},
+		{"unclosed style", `a<style>body { display: none }`, `a`},
+		{"iframe", `a<iframe src="https://evil.example"></iframe>b`, `ab`},
+		{"svg", `a<svg><script>alert(1)</script></svg>b`, `ab`},
+		{"comment", `a<!-- <script>alert(1)</script> -->b`, `ab`},
+
+		// Unclosed and malformed tags
+		{"unclosed inline", `<p><strong>bold`, `<p><strong>bold</strong></p>`},
+		{"unclosed list items", `<ul><li>one<li>two</ul>`, `<ul><li>one</li><li>two</li></ul>`},
+		{"unclosed paragraphs", `<p>one<p>two`, `<p>one</p><p>two</p>`},
+		{"misnested", `<b><i>x</b></i>`, `<b><i>x</i></b>`},
+		{"stray end tag", `x</p></strong>`, `x<p></p>`}, // A stray </p> opens an empty paragraph, as in a browser
+		{"unterminated tag", `a<p onclick="alert(1)"`, `a`},
+		{"unterminated quote", `a<a href="javascript:alert(1)>x</a>`, `a`},
+		{"lone less than", `a < b`, `a &lt; b`},
+		{"ordered list start", `<ol start="3"><li>x</li></ol>`, `<ol start="3"><li>x</li></ol>`},
+		{"ordered list bad start", `<ol start="3;x"><li>x</li></ol>`, `<ol><li>x</li></ol>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTML(tt.input); got != tt.want {
+				t.Errorf("HTML(%q)\n got  %q\n want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"paragraphs", `<p>one</p><p>two</p>`, "one\n\ntwo"},
+		{"line break", `one<br>two`, "one\ntwo"},
+		{"list", `<ul><li>one</li><li>two</li></ul>`, "- one\n- two"},
+		{"entities decoded", `Tom &amp; Jerry &lt;3`, "Tom & Jerry <3"},
+		{"spaces collapsed", "a  \t b", "a b"},
+		{"script dropped", `a<script>alert(1)</script>b`, "ab"},
+		{"style dropped", `a<style>p { color: red }</style>b`, "ab"},
+		{"unclosed script", `a<script>alert(1)`, "a"},
+		{"unclosed tags", `<p><strong>bold`, "bold"},
+		{"attributes dropped", `<a href="javascript:alert(1)" onclick="x">link</a>`, "link"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlainText(tt.input); got != tt.want {
+				t.Errorf("PlainText(%q)\n got  %q\n want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
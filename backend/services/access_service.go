@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"eaglekidz-backend/models"
+)
+
+// DefaultRestrictedAccessRoles are the minister roles allowed to see restricted data
+// when none are configured
+var DefaultRestrictedAccessRoles = []string{"Leader", "First Aider"}
+
+// AccessService decides which ministers may see restricted data such as medical records
+type AccessService struct {
+	peopleService *PeopleService
+	roles         map[string]bool
+}
+
+// NewAccessService creates an access service granting restricted access to ministers
+// holding any of the given roles. Roles are compared case-insensitively.
+func NewAccessService(peopleService *PeopleService, roles []string) *AccessService {
+	allowed := make(map[string]bool)
+	for _, role := range roles {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if role != "" {
+			allowed[role] = true
+		}
+	}
+
+	return &AccessService{
+		peopleService: peopleService,
+		roles:         allowed,
+	}
+}
+
+// AuthorizeRestricted returns the calling minister if they may access restricted data
+func (s *AccessService) AuthorizeRestricted(ctx context.Context, callerID string) (*models.People, error) {
+	if callerID == "" {
+		return nil, NewError(ErrUnauthenticated, CodeUnauthenticated, "caller not identified")
+	}
+
+	caller, err := s.peopleService.GetPeopleByID(ctx, callerID)
+	if err != nil || caller.Type != "minister" {
+		return nil, forbidden("caller is not authorized")
+	}
+	// Tokens outlive a minister's service, so only active ministers keep their access
+	if caller.Status != "" && caller.Status != models.PeopleStatusActive {
+		return nil, forbidden("caller is not authorized")
+	}
+
+	for _, role := range caller.Roles {
+		if s.roles[strings.ToLower(role)] {
+			return caller, nil
+		}
+	}
+
+	return nil, forbidden("caller is not authorized")
+}
+
+type authorizedCallerKey struct{}
+
+// WithAuthorizedCaller returns a context marking the caller as allowed to see restricted data.
+// Services only decrypt protected fields for contexts carrying an authorized caller.
+func WithAuthorizedCaller(ctx context.Context, caller *models.People) context.Context {
+	return context.WithValue(ctx, authorizedCallerKey{}, caller)
+}
+
+// AuthorizedCaller returns the authorized caller carried by ctx, if any
+func AuthorizedCaller(ctx context.Context) (*models.People, bool) {
+	caller, ok := ctx.Value(authorizedCallerKey{}).(*models.People)
+	return caller, ok && caller != nil
+}
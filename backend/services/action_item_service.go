@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ActionItemsCollection = "action_items"
+
+// actionItemStatuses maps every action item status to whether the item is still open
+var actionItemStatuses = map[string]bool{
+	models.ActionItemStatusOpen:       true,
+	models.ActionItemStatusInProgress: true,
+	models.ActionItemStatusDone:       false,
+	models.ActionItemStatusCancelled:  false,
+}
+
+// openActionItemStatuses are the statuses of items that still need doing
+var openActionItemStatuses = []string{models.ActionItemStatusOpen, models.ActionItemStatusInProgress}
+
+// ActionItemService tracks the actions agreed in reviews until they are done
+type ActionItemService struct {
+	collection    *mongo.Collection
+	weeks         *mongo.Collection
+	reviewService *ReviewService
+	peopleService *PeopleService
+}
+
+func NewActionItemService(reviewService *ReviewService, peopleService *PeopleService) *ActionItemService {
+	return &ActionItemService{
+		collection:    database.GetCollection(ActionItemsCollection),
+		weeks:         database.GetCollection(WeeksCollection),
+		reviewService: reviewService,
+		peopleService: peopleService,
+	}
+}
+
+// CreateActionItem adds an action item to a review. The item belongs to the review's week.
+func (s *ActionItemService) CreateActionItem(ctx context.Context, reviewID string, req models.CreateActionItemRequest) (*models.ActionItem, error) {
+	review, err := s.reviewService.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.Deleted {
+		return nil, notFound("review not found")
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, invalid(CodeMissingField, "action item title is required")
+	}
+	if req.DueDate.IsZero() {
+		return nil, invalid(CodeMissingField, "due date is required")
+	}
+	owner, err := s.peopleService.ReferencedMinister(ctx, "owner_id", req.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	item := &models.ActionItem{
+		ID:          primitive.NewObjectID(),
+		ReviewID:    review.ID,
+		WeekID:      review.WeekID,
+		Title:       title,
+		Description: strings.TrimSpace(req.Description),
+		OwnerID:     owner.ID.Hex(),
+		DueDate:     req.DueDate,
+		Status:      models.ActionItemStatusOpen,
+		Comments:    []models.ActionItemComment{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if _, err := s.collection.InsertOne(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to create action item: %v", err)
+	}
+
+	annotateActionItem(item, now)
+	return item, nil
+}
+
+// GetActionItem retrieves an action item by its ID
+func (s *ActionItemService) GetActionItem(ctx context.Context, id string) (*models.ActionItem, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, invalidID("action item", err)
+	}
+
+	var item models.ActionItem
+	if err := s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&item); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("action item not found")
+		}
+		return nil, fmt.Errorf("failed to get action item: %v", err)
+	}
+
+	annotateActionItem(&item, time.Now())
+	return &item, nil
+}
+
+// GetActionItems lists action items, soonest due first
+func (s *ActionItemService) GetActionItems(ctx context.Context, filter models.ActionItemFilter) ([]models.ActionItem, error) {
+	query := bson.M{}
+	if filter.OwnerID != "" {
+		query["owner_id"] = filter.OwnerID
+	}
+	if len(filter.Status) > 0 {
+		for _, status := range filter.Status {
+			if _, ok := actionItemStatuses[status]; !ok {
+				return nil, invalid(CodeInvalidValue, "action item status must be open, in_progress, done or cancelled")
+			}
+		}
+		query["status"] = bson.M{"$in": filter.Status}
+	}
+	return s.find(ctx, query)
+}
+
+// GetReviewActionItems lists the action items of a review, soonest due first
+func (s *ActionItemService) GetReviewActionItems(ctx context.Context, reviewID string) ([]models.ActionItem, error) {
+	review, err := s.reviewService.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	return s.find(ctx, bson.M{"review_id": review.ID})
+}
+
+// GetWeekActionItems lists the action items raised in a week together with the items
+// from earlier weeks that are still open, which carry over until they are done
+func (s *ActionItemService) GetWeekActionItems(ctx context.Context, week *models.Week) ([]models.ActionItem, error) {
+	cursor, err := s.weeks.Find(ctx, bson.M{"start_time": bson.M{"$lt": week.StartTime}},
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weeks: %v", err)
+	}
+	var earlier []models.Week
+	if err := cursor.All(ctx, &earlier); err != nil {
+		return nil, fmt.Errorf("failed to decode weeks: %v", err)
+	}
+
+	query := bson.M{"week_id": week.ID}
+	if len(earlier) > 0 {
+		earlierIDs := make([]primitive.ObjectID, len(earlier))
+		for i, w := range earlier {
+			earlierIDs[i] = w.ID
+		}
+		query = bson.M{"$or": bson.A{
+			query,
+			bson.M{"week_id": bson.M{"$in": earlierIDs}, "status": bson.M{"$in": openActionItemStatuses}},
+		}}
+	}
+
+	items, err := s.find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].CarriedOver = items[i].WeekID != week.ID
+	}
+	return items, nil
+}
+
+// GetOverdue lists the open action items whose due date has passed, most overdue first
+func (s *ActionItemService) GetOverdue(ctx context.Context) ([]models.ActionItem, error) {
+	return s.find(ctx, bson.M{
+		"status":   bson.M{"$in": openActionItemStatuses},
+		"due_date": bson.M{"$lt": time.Now()},
+	})
+}
+
+// UpdateActionItem updates an action item. Marking it done records when it was completed.
+func (s *ActionItemService) UpdateActionItem(ctx context.Context, id string, req models.UpdateActionItemRequest) (*models.ActionItem, error) {
+	item, err := s.GetActionItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	set := bson.M{"updated_at": now}
+	unset := bson.M{}
+
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			return nil, invalid(CodeMissingField, "action item title is required")
+		}
+		set["title"] = title
+	}
+	if req.Description != nil {
+		set["description"] = strings.TrimSpace(*req.Description)
+	}
+	if req.OwnerID != nil {
+		owner, err := s.peopleService.ReferencedMinister(ctx, "owner_id", *req.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		set["owner_id"] = owner.ID.Hex()
+	}
+	if req.DueDate != nil {
+		if req.DueDate.IsZero() {
+			return nil, invalid(CodeMissingField, "due date is required")
+		}
+		set["due_date"] = *req.DueDate
+	}
+	if req.Status != nil && *req.Status != item.Status {
+		if _, ok := actionItemStatuses[*req.Status]; !ok {
+			return nil, invalid(CodeInvalidValue, "action item status must be open, in_progress, done or cancelled")
+		}
+		set["status"] = *req.Status
+		if *req.Status == models.ActionItemStatusDone {
+			set["completed_at"] = now
+		} else {
+			unset["completed_at"] = ""
+		}
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if _, err := s.collection.UpdateOne(ctx, bson.M{"_id": item.ID}, update); err != nil {
+		return nil, fmt.Errorf("failed to update action item: %v", err)
+	}
+
+	return s.GetActionItem(ctx, id)
+}
+
+// AddComment adds a progress note to an action item
+func (s *ActionItemService) AddComment(ctx context.Context, id string, req models.AddActionItemCommentRequest) (*models.ActionItem, error) {
+	item, err := s.GetActionItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, invalid(CodeMissingField, "comment text is required")
+	}
+	author, err := s.peopleService.ReferencedMinister(ctx, "author_id", req.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	comment := models.ActionItemComment{
+		ID:        primitive.NewObjectID(),
+		AuthorID:  author.ID.Hex(),
+		Text:      text,
+		CreatedAt: now,
+	}
+	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": item.ID}, bson.M{
+		"$push": bson.M{"comments": comment},
+		"$set":  bson.M{"updated_at": now},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add comment: %v", err)
+	}
+
+	return s.GetActionItem(ctx, id)
+}
+
+// DeleteActionItem permanently deletes an action item raised by mistake
+func (s *ActionItemService) DeleteActionItem(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return invalidID("action item", err)
+	}
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete action item: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return notFound("action item not found")
+	}
+	return nil
+}
+
+// GetMinisterActionItems lists the action items a minister owns or has commented on,
+// for subject-access exports
+func (s *ActionItemService) GetMinisterActionItems(ctx context.Context, ministerID primitive.ObjectID) ([]models.ActionItem, error) {
+	return s.find(ctx, actionItemsOf(ministerID))
+}
+
+// AnonymizeActionItems removes a person as owner and comment author of action items and
+// replaces their name in the text with ErasedName. Items keep their history so
+// reviews can still follow up on them. It returns how many items were changed.
+func (s *ActionItemService) AnonymizeActionItems(ctx context.Context, ministerID primitive.ObjectID, pattern *regexp.Regexp) (int64, error) {
+	hexID := ministerID.Hex()
+	query := actionItemsOf(ministerID)
+	redact := func(text string) string { return text }
+	if pattern != nil {
+		regex := mentionRegex(pattern)
+		query = bson.M{"$or": bson.A{
+			query,
+			bson.M{"title": regex},
+			bson.M{"description": regex},
+			bson.M{"comments.text": regex},
+		}}
+		redact = func(text string) string { return pattern.ReplaceAllString(text, ErasedName) }
+	}
+	items, err := s.find(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	var changed int64
+	for _, item := range items {
+		if item.OwnerID == hexID {
+			item.OwnerID = ""
+		}
+		item.Title = redact(item.Title)
+		item.Description = redact(item.Description)
+		for i, comment := range item.Comments {
+			if comment.AuthorID == hexID {
+				item.Comments[i].AuthorID = ""
+			}
+			item.Comments[i].Text = redact(comment.Text)
+		}
+		_, err := s.collection.UpdateOne(ctx, bson.M{"_id": item.ID}, bson.M{"$set": bson.M{
+			"owner_id":    item.OwnerID,
+			"title":       item.Title,
+			"description": item.Description,
+			"comments":    item.Comments,
+			"updated_at":  time.Now(),
+		}})
+		if err != nil {
+			return changed, fmt.Errorf("failed to anonymize action item: %v", err)
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+func (s *ActionItemService) find(ctx context.Context, filter bson.M) ([]models.ActionItem, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}, {Key: "created_at", Value: 1}})
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get action items: %v", err)
+	}
+
+	items := []models.ActionItem{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, fmt.Errorf("failed to decode action items: %v", err)
+	}
+
+	now := time.Now()
+	for i := range items {
+		annotateActionItem(&items[i], now)
+	}
+	return items, nil
+}
+
+// actionItemsOf matches the action items a minister owns or has commented on
+func actionItemsOf(ministerID primitive.ObjectID) bson.M {
+	hexID := ministerID.Hex()
+	return bson.M{"$or": bson.A{
+		bson.M{"owner_id": hexID},
+		bson.M{"comments.author_id": hexID},
+	}}
+}
+
+// annotateActionItem works out whether an item is overdue at now
+func annotateActionItem(item *models.ActionItem, now time.Time) {
+	if item.Comments == nil {
+		item.Comments = []models.ActionItemComment{}
+	}
+	item.Overdue = actionItemStatuses[item.Status] && item.DueDate.Before(now)
+}
@@ -0,0 +1,462 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const AgeGroupsCollection = "age_groups"
+
+// GradeOffset is the age on the promotion date at which children start kindergarten (grade 0)
+const GradeOffset = 5
+
+// PromotionDay is the day each year when children move up to their next age group
+type PromotionDay struct {
+	Month time.Month
+	Day   int
+}
+
+// DefaultPromotionDay follows the start of the school year
+var DefaultPromotionDay = PromotionDay{Month: time.September, Day: 1}
+
+// ParsePromotionDay parses a promotion day given as MM-DD
+func ParsePromotionDay(value string) (PromotionDay, error) {
+	parsed, err := time.Parse("01-02", strings.TrimSpace(value))
+	if err != nil {
+		return PromotionDay{}, fmt.Errorf("promotion date must be formatted as MM-DD")
+	}
+	return PromotionDay{Month: parsed.Month(), Day: parsed.Day()}, nil
+}
+
+// Current returns the most recent promotion date on or before t
+func (d PromotionDay) Current(t time.Time) time.Time {
+	date := time.Date(t.Year(), d.Month, d.Day, 0, 0, 0, 0, time.UTC)
+	if date.After(dateOf(t)) {
+		date = date.AddDate(-1, 0, 0)
+	}
+	return date
+}
+
+// Next returns the first promotion date on or after t
+func (d PromotionDay) Next(t time.Time) time.Time {
+	date := time.Date(t.Year(), d.Month, d.Day, 0, 0, 0, 0, time.UTC)
+	if date.Before(dateOf(t)) {
+		date = date.AddDate(1, 0, 0)
+	}
+	return date
+}
+
+type AgeGroupService struct {
+	collection   *mongo.Collection
+	people       *mongo.Collection
+	promotionDay PromotionDay
+}
+
+func NewAgeGroupService(promotionDay PromotionDay) *AgeGroupService {
+	return &AgeGroupService{
+		collection:   database.GetCollection(AgeGroupsCollection),
+		people:       database.GetCollection(PeopleCollection),
+		promotionDay: promotionDay,
+	}
+}
+
+// PromotionDay returns the configured promotion day
+func (s *AgeGroupService) PromotionDay() PromotionDay {
+	return s.promotionDay
+}
+
+// CreateAgeGroup creates a new age group
+func (s *AgeGroupService) CreateAgeGroup(ctx context.Context, req models.CreateAgeGroupRequest) (*models.AgeGroup, error) {
+	now := time.Now()
+	group := models.AgeGroup{
+		ID:                  primitive.NewObjectID(),
+		Name:                strings.TrimSpace(req.Name),
+		MinAge:              req.MinAge,
+		MaxAge:              req.MaxAge,
+		MinGrade:            req.MinGrade,
+		MaxGrade:            req.MaxGrade,
+		SortOrder:           req.SortOrder,
+		MaxChildrenPerAdult: req.MaxChildrenPerAdult,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+
+	if err := validateAgeGroup(&group); err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(ctx, group.Name, primitive.NilObjectID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.collection.InsertOne(ctx, group); err != nil {
+		return nil, fmt.Errorf("failed to create age group: %v", err)
+	}
+
+	return &group, nil
+}
+
+// GetAgeGroups retrieves all age groups, youngest first
+func (s *AgeGroupService) GetAgeGroups(ctx context.Context) ([]models.AgeGroup, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "sort_order", Value: 1}, {Key: "name", Value: 1}})
+	cursor, err := s.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get age groups: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	groups := []models.AgeGroup{}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, fmt.Errorf("failed to decode age groups: %v", err)
+	}
+
+	return groups, nil
+}
+
+// GetAgeGroupByID retrieves an age group by its ID
+func (s *AgeGroupService) GetAgeGroupByID(ctx context.Context, id string) (*models.AgeGroup, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, invalidID("age group", err)
+	}
+
+	var group models.AgeGroup
+	err = s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&group)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("age group not found")
+		}
+		return nil, fmt.Errorf("failed to get age group: %v", err)
+	}
+
+	return &group, nil
+}
+
+// UpdateAgeGroup updates an age group. A new name is carried over to everyone in the group.
+func (s *AgeGroupService) UpdateAgeGroup(ctx context.Context, id string, req models.UpdateAgeGroupRequest) (*models.AgeGroup, error) {
+	group, err := s.GetAgeGroupByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	oldName := group.Name
+	if req.Name != nil {
+		group.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.MinAge != nil || req.MaxAge != nil || req.MinGrade != nil || req.MaxGrade != nil {
+		group.MinAge, group.MaxAge = req.MinAge, req.MaxAge
+		group.MinGrade, group.MaxGrade = req.MinGrade, req.MaxGrade
+	}
+	if req.SortOrder != nil {
+		group.SortOrder = *req.SortOrder
+	}
+	if req.MaxChildrenPerAdult != nil {
+		group.MaxChildrenPerAdult = req.MaxChildrenPerAdult
+		if *req.MaxChildrenPerAdult == 0 {
+			group.MaxChildrenPerAdult = nil
+		}
+	}
+	group.UpdatedAt = time.Now()
+
+	if err := validateAgeGroup(group); err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(ctx, group.Name, group.ID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": group.ID}, group); err != nil {
+		return nil, fmt.Errorf("failed to update age group: %v", err)
+	}
+
+	if group.Name != oldName {
+		if err := renameInPeople(ctx, s.people, "age_group", oldName, group.Name); err != nil {
+			return nil, fmt.Errorf("failed to rename age group on people: %v", err)
+		}
+	}
+
+	return group, nil
+}
+
+// DeleteAgeGroup deletes an age group that no one is assigned to
+func (s *AgeGroupService) DeleteAgeGroup(ctx context.Context, id string) error {
+	group, err := s.GetAgeGroupByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.people.CountDocuments(ctx, bson.M{"age_group": group.Name})
+	if err != nil {
+		return fmt.Errorf("failed to check age group usage: %v", err)
+	}
+	if count > 0 {
+		return conflict(CodeInUse, "age group is in use")
+	}
+
+	if _, err := s.collection.DeleteOne(ctx, bson.M{"_id": group.ID}); err != nil {
+		return fmt.Errorf("failed to delete age group: %v", err)
+	}
+
+	return nil
+}
+
+// AssignAgeGroup returns the age group of a child born on dateOfBirth as of the most
+// recent promotion date, or no group when none fits
+func (s *AgeGroupService) AssignAgeGroup(ctx context.Context, dateOfBirth time.Time) ([]string, error) {
+	groups, err := s.GetAgeGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if group := groupFor(groups, dateOfBirth, s.promotionDay.Current(time.Now())); group != nil {
+		return []string{group.Name}, nil
+	}
+	return []string{}, nil
+}
+
+// PreviewPromotion works out the age group moves of a promotion on asOf without applying them.
+// Children who are graduated or alumni are left out.
+func (s *AgeGroupService) PreviewPromotion(ctx context.Context, asOf time.Time) (*models.PromotionPlan, error) {
+	asOf = dateOf(asOf)
+	groups, err := s.GetAgeGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := statusMatch([]string{models.PeopleStatusProspect, models.PeopleStatusActive, models.PeopleStatusInactive})
+	filter["deleted"] = false
+	filter["type"] = "children"
+	cursor, err := s.people.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get children: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var children []models.People
+	if err := cursor.All(ctx, &children); err != nil {
+		return nil, fmt.Errorf("failed to decode children: %v", err)
+	}
+
+	plan := &models.PromotionPlan{AsOf: asOf, Moves: []models.PromotionMove{}}
+	for _, child := range children {
+		if child.DateOfBirth == nil {
+			plan.Skipped++
+			continue
+		}
+
+		to := []string{}
+		if group := groupFor(groups, *child.DateOfBirth, asOf); group != nil {
+			to = []string{group.Name}
+		}
+		// Only active children can graduate; others just lose their group
+		graduates := len(to) == 0 && agedOut(groups, *child.DateOfBirth, asOf) &&
+			(child.Status == "" || child.Status == models.PeopleStatusActive)
+
+		from := child.AgeGroup
+		if from == nil {
+			from = []string{}
+		}
+		if !graduates && sameNames(from, to) {
+			plan.Unchanged++
+			continue
+		}
+
+		plan.Moves = append(plan.Moves, models.PromotionMove{
+			ChildID:   child.ID,
+			FirstName: child.FirstName,
+			LastName:  child.LastName,
+			From:      from,
+			To:        to,
+			Graduates: graduates,
+		})
+	}
+
+	sort.Slice(plan.Moves, func(i, j int) bool {
+		a, b := plan.Moves[i], plan.Moves[j]
+		if a.LastName != b.LastName {
+			return a.LastName < b.LastName
+		}
+		return a.FirstName < b.FirstName
+	})
+
+	return plan, nil
+}
+
+// ApplyPromotion moves children to their age group as of asOf and graduates those who
+// have aged out of every group. Applying the same promotion again changes nothing.
+func (s *AgeGroupService) ApplyPromotion(ctx context.Context, asOf time.Time, appliedBy string) (*models.PromotionPlan, error) {
+	plan, err := s.PreviewPromotion(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, move := range plan.Moves {
+		filter := bson.M{"_id": move.ChildID, "deleted": false}
+		update := bson.M{
+			"$set": bson.M{"age_group": move.To, "updated_at": now},
+		}
+
+		if move.Graduates {
+			filter["status"] = statusMatch([]string{models.PeopleStatusActive})["status"]
+			update["$set"].(bson.M)["status"] = models.PeopleStatusGraduated
+			update["$push"] = bson.M{"status_history": models.StatusChange{
+				From:      models.PeopleStatusActive,
+				To:        models.PeopleStatusGraduated,
+				Reason:    fmt.Sprintf("Aged out of every age group in the %s promotion", plan.AsOf.Format("2006-01-02")),
+				ChangedBy: appliedBy,
+				ChangedAt: now,
+			}}
+		}
+
+		if _, err := s.people.UpdateOne(ctx, filter, update); err != nil {
+			return nil, fmt.Errorf("failed to promote child: %v", err)
+		}
+	}
+
+	plan.Applied = true
+	return plan, nil
+}
+
+// CanonicalAgeGroups returns the catalog names of the given age groups, rejecting any
+// the catalog does not hold. Spelling variants such as "all-star" match "All Star".
+func (s *AgeGroupService) CanonicalAgeGroups(ctx context.Context, names []string) ([]string, error) {
+	if names == nil {
+		return nil, nil
+	}
+	c, err := s.catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.canonical(names, "age group")
+}
+
+func (s *AgeGroupService) catalog(ctx context.Context) (catalog, error) {
+	groups, err := s.GetAgeGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(groups))
+	for i, group := range groups {
+		names[i] = group.Name
+	}
+	return newCatalog(names), nil
+}
+
+// checkNameAvailable returns an error when another age group already uses name or a spelling variant of it
+func (s *AgeGroupService) checkNameAvailable(ctx context.Context, name string, id primitive.ObjectID) error {
+	taken, err := catalogNameTaken(ctx, s.collection, name, id)
+	if err != nil {
+		return fmt.Errorf("failed to check age group name: %v", err)
+	}
+	if taken {
+		return conflict(CodeAlreadyExists, "age group already exists")
+	}
+	return nil
+}
+
+// validateAgeGroup checks that a group has a name and one consistent set of bounds
+func validateAgeGroup(group *models.AgeGroup) error {
+	if catalogKey(group.Name) == "" {
+		return invalid(CodeMissingField, "age group name is required")
+	}
+
+	hasAge := group.MinAge != nil || group.MaxAge != nil
+	hasGrade := group.MinGrade != nil || group.MaxGrade != nil
+	if hasAge == hasGrade {
+		return invalid(CodeInvalidValue, "age group must have either age or grade bounds")
+	}
+
+	for _, bounds := range [][2]*int{{group.MinAge, group.MaxAge}, {group.MinGrade, group.MaxGrade}} {
+		min, max := bounds[0], bounds[1]
+		if (min != nil && *min < 0) || (max != nil && *max < 0) {
+			return invalid(CodeInvalidValue, "age group bounds cannot be negative")
+		}
+		if min != nil && max != nil && *min > *max {
+			return invalid(CodeInvalidValue, "age group minimum cannot exceed its maximum")
+		}
+	}
+
+	if group.MaxChildrenPerAdult != nil && *group.MaxChildrenPerAdult < 1 {
+		return invalid(CodeInvalidValue, "age group ratio must allow at least one child per adult")
+	}
+	return nil
+}
+
+// groupFor returns the first group, in sort order, a child born on dateOfBirth fits as of asOf
+func groupFor(groups []models.AgeGroup, dateOfBirth, asOf time.Time) *models.AgeGroup {
+	for i := range groups {
+		value, min, max := groupBounds(&groups[i], dateOfBirth, asOf)
+		if (min == nil || value >= *min) && (max == nil || value <= *max) {
+			return &groups[i]
+		}
+	}
+	return nil
+}
+
+// agedOut reports whether a child born on dateOfBirth is past the upper bound of every group as of asOf
+func agedOut(groups []models.AgeGroup, dateOfBirth, asOf time.Time) bool {
+	if len(groups) == 0 {
+		return false
+	}
+	for i := range groups {
+		value, _, max := groupBounds(&groups[i], dateOfBirth, asOf)
+		if max == nil || value <= *max {
+			return false
+		}
+	}
+	return true
+}
+
+// groupBounds returns the child's age or grade, whichever the group is bounded by, with the group's bounds
+func groupBounds(group *models.AgeGroup, dateOfBirth, asOf time.Time) (int, *int, *int) {
+	age := ageOn(dateOfBirth, asOf)
+	if group.MinGrade != nil || group.MaxGrade != nil {
+		return age - GradeOffset, group.MinGrade, group.MaxGrade
+	}
+	return age, group.MinAge, group.MaxAge
+}
+
+// ageOn returns the age in whole years on date of someone born on dateOfBirth
+func ageOn(dateOfBirth, date time.Time) int {
+	dateOfBirth, date = dateOf(dateOfBirth), dateOf(date)
+	age := date.Year() - dateOfBirth.Year()
+	if date.Month() < dateOfBirth.Month() || (date.Month() == dateOfBirth.Month() && date.Day() < dateOfBirth.Day()) {
+		age--
+	}
+	return age
+}
+
+// dateOf truncates t to its calendar date in UTC
+func dateOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// sameNames reports whether two name lists hold the same names, ignoring order
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int)
+	for _, name := range a {
+		seen[name]++
+	}
+	for _, name := range b {
+		if seen[name] == 0 {
+			return false
+		}
+		seen[name]--
+	}
+	return true
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const AttendanceCollection = "attendance"
+
+// securityCodeAlphabet leaves out characters that are easy to misread on a sticker (0/O, 1/I/L)
+const securityCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+const securityCodeLength = 4
+
+// MaxSecurityCodeAttempts is how many wrong pickup codes may be tried for a check-in
+// before its check-out is locked until a restricted minister unlocks it
+const MaxSecurityCodeAttempts = 5
+
+// openCheckInIndex is the unique index allowing each child one open check-in per week
+const openCheckInIndex = "open_check_in"
+
+// AuditEntityAttendance is the audit entity type for check-ins, recorded when their pickup
+// codes are printed
+const AuditEntityAttendance = "attendance"
+
+type AttendanceService struct {
+	collection     *mongo.Collection
+	weekService    *WeekService
+	peopleService  *PeopleService
+	medicalService *MedicalService
+	auditService   *AuditService
+}
+
+func NewAttendanceService(weekService *WeekService, peopleService *PeopleService, medicalService *MedicalService, auditService *AuditService) *AttendanceService {
+	return &AttendanceService{
+		collection:     database.GetCollection(AttendanceCollection),
+		weekService:    weekService,
+		peopleService:  peopleService,
+		medicalService: medicalService,
+		auditService:   auditService,
+	}
+}
+
+// EnsureIndexes creates the index that keeps a child from being checked in twice to the
+// same week, marking check-ins made before it existed that are still open
+func (s *AttendanceService) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.UpdateMany(ctx, bson.M{
+		"checked_out_at": bson.M{"$exists": false},
+		"open":           bson.M{"$exists": false},
+	}, bson.M{"$set": bson.M{"open": true}})
+	if err != nil {
+		return fmt.Errorf("failed to mark open check-ins: %v", err)
+	}
+
+	// Partial indexes cannot match a missing field, so open check-ins carry "open" instead
+	_, err = s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "child_id", Value: 1}, {Key: "week_id", Value: 1}},
+		Options: options.Index().
+			SetName(openCheckInIndex).
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"open": true}),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s index: %v", openCheckInIndex, err)
+	}
+	return nil
+}
+
+// CheckIn checks a child in to a service of a week and issues a pickup security code
+func (s *AttendanceService) CheckIn(ctx context.Context, req models.CheckInRequest) (*models.Attendance, error) {
+	week, err := s.weekService.ReferencedWeek(ctx, "week_id", req.WeekID)
+	if err != nil {
+		return nil, err
+	}
+	if err := referencedService(week, req.ServiceName, req.ServiceTime); err != nil {
+		return nil, err
+	}
+
+	child, err := s.peopleService.ReferencedChild(ctx, "child_id", req.ChildID)
+	if err != nil {
+		return nil, err
+	}
+	if req.CheckedInBy != "" {
+		if _, err := s.peopleService.ReferencedMinister(ctx, "checked_in_by", req.CheckedInBy); err != nil {
+			return nil, err
+		}
+	}
+
+	// A child can only be in one room at a time. The open check-in index enforces it when
+	// two check-ins race; counting first gives the usual answer without a failed insert.
+	count, err := s.collection.CountDocuments(ctx, bson.M{
+		"week_id":        week.ID,
+		"child_id":       child.ID,
+		"checked_out_at": bson.M{"$exists": false},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing check-in: %v", err)
+	}
+	if count > 0 {
+		return nil, conflict(CodeAlreadyCheckedIn, "child is already checked in")
+	}
+
+	code, err := generateSecurityCode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate security code: %v", err)
+	}
+
+	now := time.Now()
+	attendance := &models.Attendance{
+		ID:           primitive.NewObjectID(),
+		WeekID:       week.ID,
+		ServiceName:  req.ServiceName,
+		ServiceTime:  req.ServiceTime,
+		ChildID:      child.ID,
+		SecurityCode: code,
+		CheckedInAt:  now,
+		CheckedInBy:  req.CheckedInBy,
+		Open:         true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	_, err = s.collection.InsertOne(ctx, attendance)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, conflict(CodeAlreadyCheckedIn, "child is already checked in")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check in child: %v", err)
+	}
+
+	flags, err := s.medicalService.GetAlertFlags(ctx, []primitive.ObjectID{child.ID})
+	if err != nil {
+		return nil, err
+	}
+	attendance.Alerts = flags[child.ID]
+
+	return attendance, nil
+}
+
+// CheckOut checks a child out after verifying the pickup security code
+func (s *AttendanceService) CheckOut(ctx context.Context, id string, req models.CheckOutRequest) (*models.Attendance, error) {
+	attendance, err := s.getAttendance(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if attendance.CheckedOutAt != nil {
+		return nil, conflict(CodeAlreadyCheckedOut, "child is already checked out")
+	}
+	if attendance.CodeAttempts >= MaxSecurityCodeAttempts {
+		return nil, checkOutLocked()
+	}
+
+	if subtle.ConstantTimeCompare([]byte(attendance.SecurityCode), []byte(req.SecurityCode)) != 1 {
+		return nil, s.failCodeAttempt(ctx, attendance.ID)
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"checked_out_at": now,
+			"collected_by":   req.CollectedBy,
+			"updated_at":     now,
+		},
+		"$unset": bson.M{"open": ""},
+	}
+
+	// Guard on checked_out_at so two concurrent check-outs cannot both succeed, and on the
+	// attempts so a guess made while others locked the check-in does not get through
+	result, err := s.collection.UpdateOne(ctx, bson.M{
+		"_id":                  attendance.ID,
+		"checked_out_at":       bson.M{"$exists": false},
+		"failed_code_attempts": bson.M{"$not": bson.M{"$gte": MaxSecurityCodeAttempts}},
+	}, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check out child: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, conflict(CodeAlreadyCheckedOut, "child is already checked out")
+	}
+
+	return s.GetAttendanceByID(ctx, id)
+}
+
+// failCodeAttempt counts a wrong pickup code against a check-in and returns the error
+// to report, locking the check-out once MaxSecurityCodeAttempts have been tried
+func (s *AttendanceService) failCodeAttempt(ctx context.Context, id primitive.ObjectID) error {
+	var attendance models.Attendance
+	err := s.collection.FindOneAndUpdate(ctx, bson.M{
+		"_id":                  id,
+		"checked_out_at":       bson.M{"$exists": false},
+		"failed_code_attempts": bson.M{"$not": bson.M{"$gte": MaxSecurityCodeAttempts}},
+	}, bson.M{
+		"$inc": bson.M{"failed_code_attempts": 1},
+		"$set": bson.M{"updated_at": time.Now()},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&attendance)
+	if err == mongo.ErrNoDocuments {
+		// Locked, or checked out, by another request since it was read
+		return checkOutLocked()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record security code attempt: %v", err)
+	}
+
+	left := MaxSecurityCodeAttempts - attendance.CodeAttempts
+	if left <= 0 {
+		return checkOutLocked()
+	}
+	attempts := "attempts"
+	if left == 1 {
+		attempts = "attempt"
+	}
+	return NewError(ErrForbidden, CodeInvalidCode, "invalid security code, %d %s left", left, attempts)
+}
+
+func checkOutLocked() error {
+	return NewError(ErrForbidden, CodeCheckOutLocked, "too many invalid security codes; a minister with restricted access must unlock the check-out")
+}
+
+// UnlockCheckOut clears the wrong pickup codes tried for a check-in so it can be checked
+// out again, for an authorized caller, and audits the unlock
+func (s *AttendanceService) UnlockCheckOut(ctx context.Context, id string) (*models.Attendance, error) {
+	caller, ok := AuthorizedCaller(ctx)
+	if !ok {
+		return nil, forbidden("caller is not authorized")
+	}
+
+	attendance, err := s.getAttendance(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if attendance.CheckedOutAt != nil {
+		return nil, conflict(CodeAlreadyCheckedOut, "child is already checked out")
+	}
+
+	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": attendance.ID}, bson.M{
+		"$set":   bson.M{"updated_at": time.Now()},
+		"$unset": bson.M{"failed_code_attempts": ""},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock check-out: %v", err)
+	}
+
+	if err := s.auditService.Record(ctx, AuditActionUpdate, AuditEntityAttendance, attendance.ID, caller.ID.Hex(), "check-out unlocked"); err != nil {
+		return nil, err
+	}
+
+	return s.GetAttendanceByID(ctx, id)
+}
+
+// GetAttendanceByID retrieves an attendance record by its ID, without its security code
+func (s *AttendanceService) GetAttendanceByID(ctx context.Context, id string) (*models.Attendance, error) {
+	attendance, err := s.getAttendance(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pickup codes are only handed out at check-in
+	attendance.SecurityCode = ""
+	return attendance, nil
+}
+
+// getAttendance retrieves an attendance record by its ID, including its security code
+func (s *AttendanceService) getAttendance(ctx context.Context, id string) (*models.Attendance, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, invalidID("attendance", err)
+	}
+
+	var attendance models.Attendance
+	err = s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&attendance)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("attendance not found")
+		}
+		return nil, fmt.Errorf("failed to get attendance: %v", err)
+	}
+
+	return &attendance, nil
+}
+
+// GetCheckedInByWeek retrieves the children currently checked in to each service of a week
+func (s *AttendanceService) GetCheckedInByWeek(ctx context.Context, weekID string) ([]models.CheckedInService, error) {
+	week, err := s.weekService.GetWeekByID(ctx, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.getOpenCheckIns(ctx, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	childIDs := make([]primitive.ObjectID, len(records))
+	for i, record := range records {
+		childIDs[i] = record.ChildID
+	}
+	flags, err := s.medicalService.GetAlertFlags(ctx, childIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the week's service order and list empty services too
+	grouped := make([]models.CheckedInService, len(week.Services))
+	for i, service := range week.Services {
+		grouped[i] = models.CheckedInService{
+			ServiceName: service.Name,
+			ServiceTime: service.Time,
+			Children:    []models.Attendance{},
+		}
+	}
+	for _, record := range records {
+		record.Alerts = flags[record.ChildID]
+		for i := range grouped {
+			if grouped[i].ServiceName == record.ServiceName && grouped[i].ServiceTime == record.ServiceTime {
+				grouped[i].Children = append(grouped[i].Children, record)
+				break
+			}
+		}
+	}
+
+	return grouped, nil
+}
+
+// getOpenCheckIns retrieves the attendance records of a week that have not been checked out
+func (s *AttendanceService) getOpenCheckIns(ctx context.Context, weekID string) ([]models.Attendance, error) {
+	weekObjID, err := primitive.ObjectIDFromHex(weekID)
+	if err != nil {
+		return nil, invalidID("week", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "checked_in_at", Value: 1}})
+	cursor, err := s.collection.Find(ctx, bson.M{
+		"week_id":        weekObjID,
+		"checked_out_at": bson.M{"$exists": false},
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checked-in children: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var records []models.Attendance
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode attendance: %v", err)
+	}
+
+	// Pickup codes are only handed out at check-in
+	for i := range records {
+		records[i].SecurityCode = ""
+	}
+
+	return records, nil
+}
+
+// pickupCodes returns the security codes of the given open check-ins, keyed by attendance ID,
+// so their labels can be reprinted. Only authorized callers may see them, and every code
+// handed out is written to the audit trail.
+func (s *AttendanceService) pickupCodes(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	caller, ok := AuthorizedCaller(ctx)
+	if !ok {
+		return nil, forbidden("caller is not authorized")
+	}
+
+	opts := options.Find().SetProjection(bson.M{"security_code": 1})
+	cursor, err := s.collection.Find(ctx, bson.M{
+		"_id":            bson.M{"$in": ids},
+		"checked_out_at": bson.M{"$exists": false},
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get security codes: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var records []models.Attendance
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode attendance: %v", err)
+	}
+
+	codes := make(map[primitive.ObjectID]string, len(records))
+	for _, record := range records {
+		if err := s.auditService.Record(ctx, AuditActionView, AuditEntityAttendance, record.ID, caller.ID.Hex(), "pickup code printed"); err != nil {
+			return nil, err
+		}
+		codes[record.ID] = record.SecurityCode
+	}
+
+	return codes, nil
+}
+
+// GetAttendanceByWeek retrieves every attendance record of a week, including checked-out children
+func (s *AttendanceService) GetAttendanceByWeek(ctx context.Context, weekID string) ([]models.Attendance, error) {
+	weekObjID, err := primitive.ObjectIDFromHex(weekID)
+	if err != nil {
+		return nil, invalidID("week", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "checked_in_at", Value: 1}})
+	cursor, err := s.collection.Find(ctx, bson.M{"week_id": weekObjID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	records := []models.Attendance{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode attendance: %v", err)
+	}
+
+	// Pickup codes are only handed out at check-in
+	for i := range records {
+		records[i].SecurityCode = ""
+	}
+
+	return records, nil
+}
+
+// weekHasService reports whether the week contains a service with the given name and time
+func weekHasService(week *models.Week, name, serviceTime string) bool {
+	for _, service := range week.Services {
+		if service.Name == name && service.Time == serviceTime {
+			return true
+		}
+	}
+	return false
+}
+
+// generateSecurityCode returns a random pickup code drawn from securityCodeAlphabet
+func generateSecurityCode() (string, error) {
+	code := make([]byte, securityCodeLength)
+	max := big.NewInt(int64(len(securityCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = securityCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
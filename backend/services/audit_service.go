@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const AuditCollection = "audit_log"
+
+// Audit actions
+const (
+	AuditActionView   = "view"
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionExport = "export"
+	AuditActionErase  = "erase"
+)
+
+// Audit entity types not owned by a single service
+const (
+	AuditEntityPeople     = "people"
+	AuditEntityEncryption = "encryption" // Encryption key rotations
+)
+
+type AuditService struct {
+	collection *mongo.Collection
+}
+
+func NewAuditService() *AuditService {
+	return &AuditService{
+		collection: database.GetCollection(AuditCollection),
+	}
+}
+
+// Record appends an entry to the audit trail
+func (s *AuditService) Record(ctx context.Context, action, entityType string, entityID primitive.ObjectID, actorID, details string) error {
+	entry := models.AuditEntry{
+		ID:         primitive.NewObjectID(),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		ActorID:    actorID,
+		Details:    details,
+		CreatedAt:  time.Now(),
+	}
+
+	_, err := s.collection.InsertOne(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %v", err)
+	}
+
+	return nil
+}
+
+// GetEntries retrieves audit entries, newest first, optionally filtered by entity
+func (s *AuditService) GetEntries(ctx context.Context, entityType, entityID string) ([]models.AuditEntry, error) {
+	filter := bson.M{}
+	if entityType != "" {
+		filter["entity_type"] = entityType
+	}
+	if entityID != "" {
+		objID, err := primitive.ObjectIDFromHex(entityID)
+		if err != nil {
+			return nil, invalidID("entity", err)
+		}
+		filter["entity_id"] = objID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.AuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode audit entries: %v", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// catalogKey reduces a catalog name to the letters and digits that identify it, so
+// "All Star", "Allstar" and "all-star" are the same entry
+func catalogKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// catalog maps catalog keys to the canonical names of a catalog's entries
+type catalog map[string]string
+
+func newCatalog(names []string) catalog {
+	c := make(catalog, len(names))
+	for _, name := range names {
+		c[catalogKey(name)] = name
+	}
+	return c
+}
+
+// canonical returns the catalog names of values, without duplicates. kind names the
+// catalog in the error returned for a value it does not hold.
+func (c catalog) canonical(values []string, kind string) ([]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+
+	names := []string{}
+	seen := make(map[string]bool)
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		name, ok := c[catalogKey(value)]
+		if !ok {
+			return nil, invalid(CodeInvalidValue, "unknown %s %q", kind, value)
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// catalogNameTaken reports whether an entry of collection other than id already has a
+// name with the same catalog key
+func catalogNameTaken(ctx context.Context, collection *mongo.Collection, name string, id primitive.ObjectID) (bool, error) {
+	opts := options.Find().SetProjection(bson.M{"name": 1})
+	cursor, err := collection.Find(ctx, bson.M{"_id": bson.M{"$ne": id}}, opts)
+	if err != nil {
+		return false, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []struct {
+		Name string `bson:"name"`
+	}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return false, err
+	}
+
+	key := catalogKey(name)
+	for _, entry := range entries {
+		if catalogKey(entry.Name) == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// renameInPeople replaces a catalog name in the field of every person that holds it
+func renameInPeople(ctx context.Context, people *mongo.Collection, field, from, to string) error {
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"value": from}},
+	})
+	_, err := people.UpdateMany(ctx, bson.M{field: from}, bson.M{
+		"$set": bson.M{field + ".$[value]": to},
+	}, opts)
+	return err
+}
+
+// CatalogService brings the age groups and roles stored on people in line with their catalogs
+type CatalogService struct {
+	people          *mongo.Collection
+	ageGroupService *AgeGroupService
+	roleService     *RoleService
+}
+
+func NewCatalogService(ageGroupService *AgeGroupService, roleService *RoleService) *CatalogService {
+	return &CatalogService{
+		people:          database.GetCollection(PeopleCollection),
+		ageGroupService: ageGroupService,
+		roleService:     roleService,
+	}
+}
+
+// MigratePeople rewrites the age groups and roles of every person to their catalog
+// names, merging spelling variants. Values missing from the catalogs are left as they
+// are and reported so they can be added and the migration run again. A dry run only
+// reports what would change.
+func (s *CatalogService) MigratePeople(ctx context.Context, dryRun bool) (*models.CatalogMigrationReport, error) {
+	ageGroups, err := s.ageGroupService.catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.roleService.catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	projection := options.Find().SetProjection(bson.M{"age_group": 1, "roles": 1})
+	cursor, err := s.people.Find(ctx, bson.M{}, projection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get people: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	report := &models.CatalogMigrationReport{
+		DryRun:  dryRun,
+		Renamed: []models.CatalogRename{},
+		Unknown: []models.CatalogUnknown{},
+	}
+	renamed := make(map[models.CatalogRename]int)
+	unknown := make(map[models.CatalogUnknown]int)
+
+	for cursor.Next(ctx) {
+		var people models.People
+		if err := cursor.Decode(&people); err != nil {
+			return nil, fmt.Errorf("failed to decode people: %v", err)
+		}
+		report.PeopleScanned++
+
+		update := bson.M{}
+		for _, field := range []struct {
+			name    string
+			values  []string
+			catalog catalog
+		}{
+			{"age_group", people.AgeGroup, ageGroups},
+			{"roles", people.Roles, roles},
+		} {
+			normalized, changed := normalizeValues(field.values, field.catalog, func(from, to string) {
+				if to == "" {
+					unknown[models.CatalogUnknown{Field: field.name, Value: from}]++
+				} else {
+					renamed[models.CatalogRename{Field: field.name, From: from, To: to}]++
+				}
+			})
+			if changed {
+				update[field.name] = normalized
+			}
+		}
+		if len(update) == 0 {
+			continue
+		}
+
+		report.PeopleUpdated++
+		if dryRun {
+			continue
+		}
+		if _, err := s.people.UpdateOne(ctx, bson.M{"_id": people.ID}, bson.M{"$set": update}); err != nil {
+			return nil, fmt.Errorf("failed to update people: %v", err)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read people: %v", err)
+	}
+
+	for rename, count := range renamed {
+		rename.People = count
+		report.Renamed = append(report.Renamed, rename)
+	}
+	for value, count := range unknown {
+		value.People = count
+		report.Unknown = append(report.Unknown, value)
+	}
+	sortMigrationReport(report)
+
+	return report, nil
+}
+
+// normalizeValues maps values to their catalog names, keeping unknown values and
+// dropping duplicates. report is called for every value that is renamed, with an
+// empty name for values the catalog does not hold.
+func normalizeValues(values []string, c catalog, report func(from, to string)) ([]string, bool) {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	changed := false
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			changed = true
+			continue
+		}
+		name, ok := c[catalogKey(value)]
+		if !ok {
+			report(value, "")
+			name = value
+		} else if name != value {
+			report(value, name)
+			changed = true
+		}
+		if seen[name] {
+			changed = true
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized, changed
+}
+
+func sortMigrationReport(report *models.CatalogMigrationReport) {
+	sort.Slice(report.Renamed, func(i, j int) bool {
+		a, b := report.Renamed[i], report.Renamed[j]
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.From < b.From
+	})
+	sort.Slice(report.Unknown, func(i, j int) bool {
+		a, b := report.Unknown[i], report.Unknown[j]
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Value < b.Value
+	})
+}
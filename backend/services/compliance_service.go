@@ -0,0 +1,455 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ComplianceCollection = "compliance_items"
+
+// DefaultCompliancePolicy requires a background check and child-safety training, warns
+// when rostering a minister without them and flags items 30 days before they expire
+var DefaultCompliancePolicy = models.CompliancePolicy{
+	RequiredTypes:     []string{models.ComplianceTypeBackgroundCheck, models.ComplianceTypeChildSafetyTraining},
+	Enforcement:       models.ComplianceEnforcementWarn,
+	ExpiryWarningDays: 30,
+}
+
+type ComplianceService struct {
+	collection    *mongo.Collection
+	peopleService *PeopleService
+	policy        models.CompliancePolicy
+}
+
+func NewComplianceService(peopleService *PeopleService, policy models.CompliancePolicy) *ComplianceService {
+	return &ComplianceService{
+		collection:    database.GetCollection(ComplianceCollection),
+		peopleService: peopleService,
+		policy:        policy,
+	}
+}
+
+// ValidateCompliancePolicy checks that the policy can be enforced
+func ValidateCompliancePolicy(policy models.CompliancePolicy) error {
+	if policy.Enforcement != models.ComplianceEnforcementWarn && policy.Enforcement != models.ComplianceEnforcementBlock {
+		return fmt.Errorf("enforcement must be 'warn' or 'block'")
+	}
+	if policy.ExpiryWarningDays < 1 {
+		return fmt.Errorf("expiry warning days must be at least 1")
+	}
+	for _, itemType := range policy.RequiredTypes {
+		if complianceType(itemType) == "" {
+			return fmt.Errorf("required compliance types cannot be empty")
+		}
+	}
+	return nil
+}
+
+// Policy returns the compliance policy in force
+func (s *ComplianceService) Policy() models.CompliancePolicy {
+	return s.policy
+}
+
+// CreateItem records a compliance item for a minister. Older items of the same type
+// that expire sooner are marked as superseded so they are no longer flagged.
+func (s *ComplianceService) CreateItem(ctx context.Context, ministerID string, req models.CreateComplianceItemRequest) (*models.ComplianceItem, error) {
+	minister, err := s.getMinister(ctx, ministerID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemType := complianceType(req.Type)
+	if itemType == "" {
+		return nil, invalid(CodeMissingField, "compliance type is required")
+	}
+	if err := validateComplianceDates(req.IssuedAt, req.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	item := &models.ComplianceItem{
+		ID:          primitive.NewObjectID(),
+		MinisterID:  minister.ID,
+		Type:        itemType,
+		IssuedAt:    req.IssuedAt,
+		ExpiresAt:   req.ExpiresAt,
+		DocumentRef: strings.TrimSpace(req.DocumentRef),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if _, err := s.collection.InsertOne(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to create compliance item: %v", err)
+	}
+
+	_, err = s.collection.UpdateMany(ctx, bson.M{
+		"minister_id":   minister.ID,
+		"type":          itemType,
+		"_id":           bson.M{"$ne": item.ID},
+		"expires_at":    bson.M{"$lt": item.ExpiresAt},
+		"superseded_by": bson.M{"$exists": false},
+	}, bson.M{"$set": bson.M{"superseded_by": item.ID, "updated_at": now}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to supersede compliance items: %v", err)
+	}
+
+	item.Status = s.status(*item, now)
+	return item, nil
+}
+
+// GetItemByID retrieves a compliance item by its ID
+func (s *ComplianceService) GetItemByID(ctx context.Context, id string) (*models.ComplianceItem, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, invalidID("compliance item", err)
+	}
+
+	var item models.ComplianceItem
+	if err := s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&item); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("compliance item not found")
+		}
+		return nil, fmt.Errorf("failed to get compliance item: %v", err)
+	}
+
+	item.Status = s.status(item, time.Now())
+	return &item, nil
+}
+
+// GetMinisterCompliance lists a minister's compliance items, latest expiry first,
+// and reports which required types they are missing today
+func (s *ComplianceService) GetMinisterCompliance(ctx context.Context, ministerID string) (*models.MinisterCompliance, error) {
+	minister, err := s.getMinister(ctx, ministerID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.find(ctx, bson.M{"minister_id": minister.ID}, options.Find().SetSort(bson.D{{Key: "expires_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	held := make(map[string]bool)
+	for _, item := range items {
+		if isCurrent(item, now) {
+			held[item.Type] = true
+		}
+	}
+	missing := s.missing(held)
+
+	return &models.MinisterCompliance{
+		MinisterID: minister.ID,
+		Compliant:  len(missing) == 0,
+		Missing:    missing,
+		Items:      items,
+	}, nil
+}
+
+// UpdateItem corrects the dates or document reference of a compliance item. A new
+// expiry date clears the expiry flag so the item is checked again.
+func (s *ComplianceService) UpdateItem(ctx context.Context, id string, req models.UpdateComplianceItemRequest) (*models.ComplianceItem, error) {
+	item, err := s.GetItemByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	update := bson.M{"$set": set}
+	issuedAt, expiresAt := item.IssuedAt, item.ExpiresAt
+	if req.IssuedAt != nil {
+		issuedAt = *req.IssuedAt
+		set["issued_at"] = issuedAt
+	}
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+		set["expires_at"] = expiresAt
+		if !expiresAt.Equal(item.ExpiresAt) {
+			update["$unset"] = bson.M{"expiry_flagged_at": ""}
+		}
+	}
+	if req.DocumentRef != nil {
+		set["document_ref"] = strings.TrimSpace(*req.DocumentRef)
+	}
+	if err := validateComplianceDates(issuedAt, expiresAt); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.collection.UpdateOne(ctx, bson.M{"_id": item.ID}, update); err != nil {
+		return nil, fmt.Errorf("failed to update compliance item: %v", err)
+	}
+
+	return s.GetItemByID(ctx, id)
+}
+
+// DeleteItem removes a compliance item recorded in error. Items it superseded count again.
+func (s *ComplianceService) DeleteItem(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return invalidID("compliance item", err)
+	}
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete compliance item: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return notFound("compliance item not found")
+	}
+
+	_, err = s.collection.UpdateMany(ctx, bson.M{"superseded_by": objID}, bson.M{
+		"$set":   bson.M{"updated_at": time.Now()},
+		"$unset": bson.M{"superseded_by": ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore superseded compliance items: %v", err)
+	}
+
+	return nil
+}
+
+// GetFlaggedItems lists items flagged as expiring that have not been renewed, soonest
+// expiry first, with the minister's contact details
+func (s *ComplianceService) GetFlaggedItems(ctx context.Context) ([]models.ComplianceItem, error) {
+	items, err := s.find(ctx, bson.M{
+		"expiry_flagged_at": bson.M{"$exists": true},
+		"superseded_by":     bson.M{"$exists": false},
+	}, options.Find().SetSort(bson.D{{Key: "expires_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		minister, err := s.peopleService.GetPeopleByID(ctx, items[i].MinisterID.Hex())
+		if err != nil {
+			continue
+		}
+		items[i].Minister = &models.ComplianceMinister{
+			FirstName: minister.FirstName,
+			LastName:  minister.LastName,
+			Phone:     minister.Phone,
+			Email:     minister.Email,
+		}
+	}
+
+	return items, nil
+}
+
+// FlagExpiring flags every current item that expires within the warning window and
+// has not been renewed, and returns the items it flagged
+func (s *ComplianceService) FlagExpiring(ctx context.Context) ([]models.ComplianceItem, error) {
+	now := time.Now()
+	items, err := s.find(ctx, bson.M{
+		"expires_at":        bson.M{"$gt": now, "$lte": now.AddDate(0, 0, s.policy.ExpiryWarningDays)},
+		"expiry_flagged_at": bson.M{"$exists": false},
+		"superseded_by":     bson.M{"$exists": false},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	ids := make([]primitive.ObjectID, len(items))
+	for i := range items {
+		ids[i] = items[i].ID
+		items[i].ExpiryFlaggedAt = &now
+	}
+	_, err = s.collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{
+		"$set": bson.M{"expiry_flagged_at": now, "updated_at": now},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to flag compliance items: %v", err)
+	}
+
+	return items, nil
+}
+
+// RunExpiryJob runs FlagExpiring immediately and then every interval until ctx is cancelled
+func (s *ComplianceService) RunExpiryJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		flagged, err := s.FlagExpiring(ctx)
+		if err != nil {
+			log.Printf("Compliance expiry check failed: %v", err)
+		} else if len(flagged) > 0 {
+			log.Printf("Compliance expiry check flagged %d items expiring within %d days", len(flagged), s.policy.ExpiryWarningDays)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckRoster reports, for each service, the rostered ministers (SIC and other ministers)
+// who do not hold every required type on the given date, with the types they are missing
+func (s *ComplianceService) CheckRoster(ctx context.Context, services []models.Service, asOf time.Time) ([][]models.ComplianceGap, error) {
+	var ministerIDs []primitive.ObjectID
+	for _, service := range services {
+		for _, id := range rosterIDs(service) {
+			if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+				ministerIDs = append(ministerIDs, objID)
+			}
+		}
+	}
+
+	held := make(map[string]map[string]bool)
+	if len(ministerIDs) > 0 {
+		items, err := s.find(ctx, bson.M{
+			"minister_id": bson.M{"$in": ministerIDs},
+			"issued_at":   bson.M{"$lte": asOf},
+			"expires_at":  bson.M{"$gt": asOf},
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			minister := item.MinisterID.Hex()
+			if held[minister] == nil {
+				held[minister] = make(map[string]bool)
+			}
+			held[minister][item.Type] = true
+		}
+	}
+
+	gaps := make([][]models.ComplianceGap, len(services))
+	for i, service := range services {
+		for _, id := range rosterIDs(service) {
+			if missing := s.missing(held[id]); len(missing) > 0 {
+				gaps[i] = append(gaps[i], models.ComplianceGap{MinisterID: id, Missing: missing})
+			}
+		}
+	}
+	return gaps, nil
+}
+
+// EnforceRoster checks every minister rostered on each service on the given date and records
+// who is missing what on the service. In block mode the first non-compliant minister is
+// returned as an error.
+func (s *ComplianceService) EnforceRoster(ctx context.Context, services []models.Service, asOf time.Time) error {
+	gaps, err := s.CheckRoster(ctx, services, asOf)
+	if err != nil {
+		return err
+	}
+
+	for i := range services {
+		services[i].MissingCompliance = gaps[i]
+		if len(gaps[i]) > 0 && s.policy.Enforcement == models.ComplianceEnforcementBlock {
+			role := "minister"
+			if gaps[i][0].MinisterID == strings.TrimSpace(services[i].SIC) {
+				role = "SIC"
+			}
+			return NewError(ErrUnprocessable, CodeNotCompliant, "non-compliant %s %s for %s %s: missing %s",
+				role, gaps[i][0].MinisterID, services[i].Name, services[i].Time, strings.Join(gaps[i][0].Missing, ", "))
+		}
+	}
+	return nil
+}
+
+// DeleteMinisterItems removes every compliance item of a minister
+func (s *ComplianceService) DeleteMinisterItems(ctx context.Context, ministerID primitive.ObjectID) (int64, error) {
+	result, err := s.collection.DeleteMany(ctx, bson.M{"minister_id": ministerID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete compliance items: %v", err)
+	}
+	return result.DeletedCount, nil
+}
+
+// GetMinisterItems retrieves every compliance item of a minister, oldest first
+func (s *ComplianceService) GetMinisterItems(ctx context.Context, ministerID primitive.ObjectID) ([]models.ComplianceItem, error) {
+	return s.find(ctx, bson.M{"minister_id": ministerID}, options.Find().SetSort(bson.D{{Key: "issued_at", Value: 1}}))
+}
+
+// find runs a query and fills in the status of each item
+func (s *ComplianceService) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.ComplianceItem, error) {
+	if opts == nil {
+		opts = options.Find()
+	}
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compliance items: %v", err)
+	}
+
+	items := []models.ComplianceItem{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, fmt.Errorf("failed to decode compliance items: %v", err)
+	}
+
+	now := time.Now()
+	for i := range items {
+		items[i].Status = s.status(items[i], now)
+	}
+	return items, nil
+}
+
+// status derives whether an item is valid, expiring within the warning window or expired
+func (s *ComplianceService) status(item models.ComplianceItem, now time.Time) string {
+	switch {
+	case !item.ExpiresAt.After(now):
+		return models.ComplianceStatusExpired
+	case !item.ExpiresAt.After(now.AddDate(0, 0, s.policy.ExpiryWarningDays)):
+		return models.ComplianceStatusExpiring
+	default:
+		return models.ComplianceStatusValid
+	}
+}
+
+// missing lists the required types not in held, in policy order
+func (s *ComplianceService) missing(held map[string]bool) []string {
+	missing := []string{}
+	for _, itemType := range s.policy.RequiredTypes {
+		if !held[complianceType(itemType)] {
+			missing = append(missing, complianceType(itemType))
+		}
+	}
+	return missing
+}
+
+// getMinister looks up a person and checks they are a minister
+func (s *ComplianceService) getMinister(ctx context.Context, ministerID string) (*models.People, error) {
+	person, err := s.peopleService.GetPeopleByID(ctx, ministerID)
+	if err != nil {
+		return nil, err
+	}
+	if person.Type != "minister" {
+		return nil, invalid(CodeInvalidValue, "compliance items can only be recorded for ministers")
+	}
+	return person, nil
+}
+
+// isCurrent reports whether an item has been issued and has not yet expired at t
+func isCurrent(item models.ComplianceItem, t time.Time) bool {
+	return !item.IssuedAt.After(t) && item.ExpiresAt.After(t)
+}
+
+// complianceType normalizes a compliance type, e.g. "Background Check" to "background_check"
+func complianceType(value string) string {
+	return strings.Join(strings.Fields(strings.ToLower(value)), "_")
+}
+
+func validateComplianceDates(issuedAt, expiresAt time.Time) error {
+	if issuedAt.IsZero() || expiresAt.IsZero() {
+		return invalid(CodeMissingField, "issued and expiry dates are required")
+	}
+	if !expiresAt.After(issuedAt) {
+		return invalid(CodeInvalidValue, "expiry date must be after the issued date")
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of service errors. Every *Error wraps one of them, so callers can check the kind
+// with errors.Is and handlers can pick the HTTP status without comparing messages.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrInvalid         = errors.New("invalid request")
+	ErrConflict        = errors.New("conflict")
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("forbidden")
+	ErrGone            = errors.New("gone")
+	ErrUnprocessable   = errors.New("unprocessable")
+	ErrUnavailable     = errors.New("unavailable")
+)
+
+// Machine-readable error codes returned to API clients
+const (
+	CodeNotFound           = "not_found"
+	CodeInvalidID          = "invalid_id"
+	CodeMissingField       = "missing_field"
+	CodeInvalidValue       = "invalid_value"
+	CodeInvalidBody        = "invalid_body"
+	CodeValidationFailed   = "validation_failed"
+	CodeInvalidReference   = "invalid_reference"
+	CodeAlreadyExists      = "already_exists"
+	CodeInUse              = "in_use"
+	CodeInvalidTransition  = "invalid_transition"
+	CodeEditConflict       = "edit_conflict"
+	CodeNotEditable        = "not_editable"
+	CodeAlreadyCheckedIn   = "already_checked_in"
+	CodeAlreadyCheckedOut  = "already_checked_out"
+	CodeInvalidCode        = "invalid_security_code"
+	CodeCheckOutLocked     = "check_out_locked"
+	CodeNotCompliant       = "not_compliant"
+	CodeNotConfigured      = "not_configured"
+	CodeUnauthenticated    = "unauthenticated"
+	CodeForbidden          = "forbidden"
+	CodeErased             = "erased"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeInternal           = "internal_error"
+	CodeServiceUnavailable = "service_unavailable"
+)
+
+// Error is an error a client can act on, such as a missing record or an invalid value.
+// Message is safe to show to users; Code identifies the error for programs.
+type Error struct {
+	Kind    error // One of the Err kinds above
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NewError returns an error of the given kind and code
+func NewError(kind error, code, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...interface{}) error {
+	return NewError(ErrNotFound, CodeNotFound, format, args...)
+}
+
+func invalid(code, format string, args ...interface{}) error {
+	return NewError(ErrInvalid, code, format, args...)
+}
+
+// invalidID reports an ID that is not a valid ObjectID, e.g. invalidID("review", err)
+func invalidID(entity string, err error) error {
+	return NewError(ErrInvalid, CodeInvalidID, "invalid %s ID: %v", entity, err)
+}
+
+func conflict(code, format string, args ...interface{}) error {
+	return NewError(ErrConflict, code, format, args...)
+}
+
+func forbidden(format string, args ...interface{}) error {
+	return NewError(ErrForbidden, CodeForbidden, format, args...)
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const FollowUpsCollection = "follow_ups"
+
+// DefaultAbsenteeCriteria flags children who came to 3 of 6 weeks and then missed 2
+var DefaultAbsenteeCriteria = models.AbsenteeCriteria{
+	MinAttended:   3,
+	LookbackWeeks: 6,
+	MissedWeeks:   2,
+}
+
+type FollowUpService struct {
+	collection    *mongo.Collection
+	weeks         *mongo.Collection
+	attendance    *mongo.Collection
+	peopleService *PeopleService
+}
+
+func NewFollowUpService(peopleService *PeopleService) *FollowUpService {
+	return &FollowUpService{
+		collection:    database.GetCollection(FollowUpsCollection),
+		weeks:         database.GetCollection(WeeksCollection),
+		attendance:    database.GetCollection(AttendanceCollection),
+		peopleService: peopleService,
+	}
+}
+
+// ValidateAbsenteeCriteria checks that the criteria describe a possible attendance pattern
+func ValidateAbsenteeCriteria(criteria models.AbsenteeCriteria) error {
+	if criteria.MinAttended < 1 || criteria.LookbackWeeks < 1 || criteria.MissedWeeks < 1 {
+		return fmt.Errorf("min attended, lookback weeks and missed weeks must be at least 1")
+	}
+	if criteria.MinAttended > criteria.LookbackWeeks {
+		return fmt.Errorf("min attended cannot exceed lookback weeks")
+	}
+	return nil
+}
+
+// DetectAbsentees opens a follow-up for every child matching the criteria who
+// does not already have an open one, and returns the follow-ups it created
+func (s *FollowUpService) DetectAbsentees(ctx context.Context, criteria models.AbsenteeCriteria) ([]models.FollowUp, error) {
+	if err := ValidateAbsenteeCriteria(criteria); err != nil {
+		return nil, err
+	}
+
+	// Most recent weeks that have already started, newest first
+	opts := options.Find().
+		SetSort(bson.D{{Key: "start_time", Value: -1}}).
+		SetLimit(int64(criteria.MissedWeeks + criteria.LookbackWeeks))
+	cursor, err := s.weeks.Find(ctx, bson.M{"start_time": bson.M{"$lte": time.Now()}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weeks: %v", err)
+	}
+	var weeks []models.Week
+	if err := cursor.All(ctx, &weeks); err != nil {
+		return nil, fmt.Errorf("failed to decode weeks: %v", err)
+	}
+
+	// Not enough history yet to tell a regular from a newcomer
+	if len(weeks) < criteria.MissedWeeks+criteria.MinAttended {
+		return []models.FollowUp{}, nil
+	}
+
+	recent := make(map[primitive.ObjectID]bool)
+	lookback := make(map[primitive.ObjectID]time.Time)
+	weekIDs := make([]primitive.ObjectID, len(weeks))
+	for i, week := range weeks {
+		weekIDs[i] = week.ID
+		if i < criteria.MissedWeeks {
+			recent[week.ID] = true
+		} else {
+			lookback[week.ID] = week.StartTime
+		}
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"week_id": bson.M{"$in": weekIDs}}},
+		bson.M{"$group": bson.M{
+			"_id":   "$child_id",
+			"weeks": bson.M{"$addToSet": "$week_id"},
+		}},
+		// Only active children are followed up; this also skips children who have
+		// moved up, been deleted or been erased
+		bson.M{"$lookup": bson.M{
+			"from":         PeopleCollection,
+			"localField":   "_id",
+			"foreignField": "_id",
+			"as":           "child",
+		}},
+		bson.M{"$match": bson.M{"child": bson.M{"$elemMatch": bson.M{
+			"deleted": false,
+			"status":  statusMatch([]string{models.PeopleStatusActive})["status"],
+		}}}},
+	}
+	attendanceCursor, err := s.attendance.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate attendance: %v", err)
+	}
+	var children []struct {
+		ChildID primitive.ObjectID   `bson:"_id"`
+		Weeks   []primitive.ObjectID `bson:"weeks"`
+	}
+	if err := attendanceCursor.All(ctx, &children); err != nil {
+		return nil, fmt.Errorf("failed to decode attendance: %v", err)
+	}
+
+	created := []models.FollowUp{}
+	for _, child := range children {
+		attended := 0
+		missedRecent := true
+		var lastAttended *time.Time
+		for _, weekID := range child.Weeks {
+			if recent[weekID] {
+				missedRecent = false
+				break
+			}
+			if start, ok := lookback[weekID]; ok {
+				attended++
+				if lastAttended == nil || start.After(*lastAttended) {
+					startCopy := start
+					lastAttended = &startCopy
+				}
+			}
+		}
+		if !missedRecent || attended < criteria.MinAttended {
+			continue
+		}
+
+		followUp, isNew, err := s.openFollowUp(ctx, child.ChildID, attended, criteria.MissedWeeks, lastAttended)
+		if err != nil {
+			return nil, err
+		}
+		if isNew {
+			created = append(created, *followUp)
+		}
+	}
+
+	s.attachContacts(ctx, created)
+	return created, nil
+}
+
+// openFollowUp inserts an open follow-up for the child unless one is already open
+func (s *FollowUpService) openFollowUp(ctx context.Context, childID primitive.ObjectID, attended, missed int, lastAttended *time.Time) (*models.FollowUp, bool, error) {
+	now := time.Now()
+	followUp := models.FollowUp{
+		ID:             primitive.NewObjectID(),
+		ChildID:        childID,
+		AttendedWeeks:  attended,
+		MissedWeeks:    missed,
+		LastAttendedAt: lastAttended,
+		Status:         models.FollowUpStatusOpen,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	filter := bson.M{"child_id": childID, "status": models.FollowUpStatusOpen}
+	update := bson.M{"$setOnInsert": followUp}
+	result, err := s.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create follow-up: %v", err)
+	}
+
+	return &followUp, result.UpsertedCount > 0, nil
+}
+
+// GetFollowUps retrieves follow-ups, optionally filtered by status, with contact details
+func (s *FollowUpService) GetFollowUps(ctx context.Context, status string) ([]models.FollowUp, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follow-ups: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	followUps := []models.FollowUp{}
+	if err := cursor.All(ctx, &followUps); err != nil {
+		return nil, fmt.Errorf("failed to decode follow-ups: %v", err)
+	}
+
+	s.attachContacts(ctx, followUps)
+	return followUps, nil
+}
+
+// GetFollowUpByID retrieves a follow-up by its ID
+func (s *FollowUpService) GetFollowUpByID(ctx context.Context, id string) (*models.FollowUp, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, invalidID("follow-up", err)
+	}
+
+	var followUp models.FollowUp
+	err = s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&followUp)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("follow-up not found")
+		}
+		return nil, fmt.Errorf("failed to get follow-up: %v", err)
+	}
+
+	followUps := []models.FollowUp{followUp}
+	s.attachContacts(ctx, followUps)
+	return &followUps[0], nil
+}
+
+// MarkContacted records that a minister has reached out to the child's family
+func (s *FollowUpService) MarkContacted(ctx context.Context, id string, req models.MarkContactedRequest) (*models.FollowUp, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, invalidID("follow-up", err)
+	}
+	if _, err := s.peopleService.ReferencedMinister(ctx, "contacted_by", req.ContactedBy); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"status":       models.FollowUpStatusContacted,
+			"contacted_by": req.ContactedBy,
+			"contacted_at": now,
+			"note":         req.Note,
+			"updated_at":   now,
+		},
+	}
+
+	result, err := s.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update follow-up: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, notFound("follow-up not found")
+	}
+
+	return s.GetFollowUpByID(ctx, id)
+}
+
+// RunDetectionJob runs DetectAbsentees immediately and then every interval until ctx is cancelled
+func (s *FollowUpService) RunDetectionJob(ctx context.Context, interval time.Duration, criteria models.AbsenteeCriteria) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		created, err := s.DetectAbsentees(ctx, criteria)
+		if err != nil {
+			log.Printf("Absentee detection failed: %v", err)
+		} else if len(created) > 0 {
+			log.Printf("Absentee detection opened %d follow-ups", len(created))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// attachContacts fills in the family contact details of each follow-up's child
+func (s *FollowUpService) attachContacts(ctx context.Context, followUps []models.FollowUp) {
+	for i := range followUps {
+		child, err := s.peopleService.GetPeopleByID(ctx, followUps[i].ChildID.Hex())
+		if err != nil {
+			continue
+		}
+		followUps[i].Contact = &models.GuardianContact{
+			FirstName: child.FirstName,
+			LastName:  child.LastName,
+			Phone:     child.Phone,
+			Email:     child.Email,
+		}
+	}
+}
@@ -0,0 +1,487 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/encryption"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const IncidentsCollection = "incidents"
+
+// AuditEntityIncident is the audit entity type for incident reports
+const AuditEntityIncident = "incident"
+
+var incidentTypes = map[string]bool{
+	models.IncidentTypeInjury:       true,
+	models.IncidentTypeIllness:      true,
+	models.IncidentTypeBehavior:     true,
+	models.IncidentTypeSafeguarding: true,
+	models.IncidentTypeOther:        true,
+}
+
+var incidentFollowUpStatuses = map[string]bool{
+	models.IncidentFollowUpNone:      true,
+	models.IncidentFollowUpRequired:  true,
+	models.IncidentFollowUpCompleted: true,
+}
+
+// incidentDocument is how an incident is stored. With encryption configured the
+// account of what happened is kept as encrypted JSON in Data, like medical records.
+type incidentDocument struct {
+	models.Incident `bson:",inline"`
+	Data            string `bson:"data,omitempty"`
+}
+
+// incidentDetails holds the free-text fields sealed into incidentDocument.Data
+type incidentDetails struct {
+	Description  models.IncidentDescription `json:"description"`
+	ActionsTaken string                     `json:"actions_taken,omitempty"`
+	FollowUpNote string                     `json:"follow_up_note,omitempty"`
+}
+
+// IncidentService records incident and accident reports. Every read and change is
+// restricted to authorized ministers and written to the audit trail.
+type IncidentService struct {
+	collection    *mongo.Collection
+	weekService   *WeekService
+	peopleService *PeopleService
+	auditService  *AuditService
+	keyring       *encryption.Keyring
+}
+
+func NewIncidentService(weekService *WeekService, peopleService *PeopleService, auditService *AuditService, keyring *encryption.Keyring) *IncidentService {
+	return &IncidentService{
+		collection:    database.GetCollection(IncidentsCollection),
+		weekService:   weekService,
+		peopleService: peopleService,
+		auditService:  auditService,
+		keyring:       keyring,
+	}
+}
+
+// CreateIncident records an incident reported by the minister in reported_by and audits
+// it. Any minister can report one, so it needs no authorized caller.
+func (s *IncidentService) CreateIncident(ctx context.Context, req models.CreateIncidentRequest) (*models.Incident, error) {
+	followUpStatus := req.FollowUpStatus
+	if followUpStatus == "" {
+		followUpStatus = models.IncidentFollowUpNone
+	}
+	if err := validateIncident(req.Type, followUpStatus, req.Description); err != nil {
+		return nil, err
+	}
+
+	week, err := s.weekService.ReferencedWeek(ctx, "week_id", req.WeekID)
+	if err != nil {
+		return nil, err
+	}
+	if err := referencedService(week, req.ServiceName, req.ServiceTime); err != nil {
+		return nil, err
+	}
+
+	reporter, err := s.peopleService.ReferencedMinister(ctx, "reported_by", req.ReportedBy)
+	if err != nil {
+		return nil, err
+	}
+	actorID := reporter.ID.Hex()
+
+	childIDs, err := s.getChildren(ctx, req.ChildIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	occurredAt := now
+	if req.OccurredAt != nil {
+		occurredAt = *req.OccurredAt
+	}
+	incident := models.Incident{
+		ID:               primitive.NewObjectID(),
+		WeekID:           week.ID,
+		ServiceName:      req.ServiceName,
+		ServiceTime:      req.ServiceTime,
+		ChildIDs:         childIDs,
+		ReportedBy:       actorID,
+		Type:             req.Type,
+		OccurredAt:       occurredAt,
+		Description:      req.Description,
+		ActionsTaken:     req.ActionsTaken,
+		GuardianNotified: req.GuardianNotified,
+		FollowUpStatus:   followUpStatus,
+		FollowUpNote:     req.FollowUpNote,
+		UpdatedBy:        actorID,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if incident.GuardianNotified {
+		incident.GuardianNotifiedAt = &now
+	}
+
+	doc, err := s.seal(incident)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.collection.InsertOne(ctx, doc); err != nil {
+		return nil, fmt.Errorf("failed to create incident: %v", err)
+	}
+
+	if err := s.auditService.Record(ctx, AuditActionCreate, AuditEntityIncident, incident.ID, actorID, ""); err != nil {
+		return nil, err
+	}
+
+	return &incident, nil
+}
+
+// GetIncident retrieves an incident for an authorized minister and audits the view
+func (s *IncidentService) GetIncident(ctx context.Context, id string) (*models.Incident, error) {
+	caller, ok := AuthorizedCaller(ctx)
+	if !ok {
+		return nil, forbidden("caller is not authorized")
+	}
+
+	incident, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(ctx, AuditActionView, AuditEntityIncident, incident.ID, caller.ID.Hex(), ""); err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+// GetIncidents lists incidents, most recent first, for an authorized minister and
+// audits the view of each incident returned
+func (s *IncidentService) GetIncidents(ctx context.Context, filter models.IncidentFilter) ([]models.Incident, error) {
+	caller, ok := AuthorizedCaller(ctx)
+	if !ok {
+		return nil, forbidden("caller is not authorized")
+	}
+
+	query := bson.M{}
+	if filter.WeekID != nil {
+		query["week_id"] = *filter.WeekID
+	}
+	if filter.ChildID != nil {
+		query["child_ids"] = *filter.ChildID
+	}
+	if filter.FollowUpStatus != "" {
+		query["follow_up_status"] = filter.FollowUpStatus
+	}
+
+	incidents, err := s.find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, incident := range incidents {
+		if err := s.auditService.Record(ctx, AuditActionView, AuditEntityIncident, incident.ID, caller.ID.Hex(), ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return incidents, nil
+}
+
+// UpdateIncident updates an incident report and audits the change. Marking the
+// guardian as notified records when it was done.
+func (s *IncidentService) UpdateIncident(ctx context.Context, id string, req models.UpdateIncidentRequest) (*models.Incident, error) {
+	caller, ok := AuthorizedCaller(ctx)
+	if !ok {
+		return nil, forbidden("caller is not authorized")
+	}
+	actorID := caller.ID.Hex()
+
+	incident, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if req.Type != nil {
+		incident.Type = *req.Type
+	}
+	if req.Description != nil {
+		incident.Description = *req.Description
+	}
+	if req.ActionsTaken != nil {
+		incident.ActionsTaken = *req.ActionsTaken
+	}
+	if req.GuardianNotified != nil && *req.GuardianNotified != incident.GuardianNotified {
+		incident.GuardianNotified = *req.GuardianNotified
+		incident.GuardianNotifiedAt = nil
+		if incident.GuardianNotified {
+			incident.GuardianNotifiedAt = &now
+		}
+	}
+	if req.FollowUpStatus != nil {
+		incident.FollowUpStatus = *req.FollowUpStatus
+	}
+	if req.FollowUpNote != nil {
+		incident.FollowUpNote = *req.FollowUpNote
+	}
+	if err := validateIncident(incident.Type, incident.FollowUpStatus, incident.Description); err != nil {
+		return nil, err
+	}
+	incident.UpdatedBy = actorID
+	incident.UpdatedAt = now
+
+	doc, err := s.seal(*incident)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": incident.ID}, doc); err != nil {
+		return nil, fmt.Errorf("failed to update incident: %v", err)
+	}
+
+	if err := s.auditService.Record(ctx, AuditActionUpdate, AuditEntityIncident, incident.ID, actorID, ""); err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+// GetIncidentRecords returns the incidents involving a child or reported by a minister
+// without auditing the read. Callers are responsible for recording why they were read.
+func (s *IncidentService) GetIncidentRecords(ctx context.Context, personID primitive.ObjectID) ([]models.Incident, error) {
+	return s.find(ctx, incidentsOf(personID))
+}
+
+// AnonymizeIncidents removes a person from the incidents they were involved in or
+// reported, replacing their name in the account with ErasedName. Incidents are kept
+// as safeguarding records. It returns how many incidents were changed.
+func (s *IncidentService) AnonymizeIncidents(ctx context.Context, personID primitive.ObjectID, pattern *regexp.Regexp) (int64, error) {
+	incidents, err := s.find(ctx, incidentsOf(personID))
+	if err != nil {
+		return 0, err
+	}
+
+	hexID := personID.Hex()
+	var changed int64
+	for _, incident := range incidents {
+		childIDs := []primitive.ObjectID{}
+		for _, childID := range incident.ChildIDs {
+			if childID != personID {
+				childIDs = append(childIDs, childID)
+			}
+		}
+		incident.ChildIDs = childIDs
+		if incident.ReportedBy == hexID {
+			incident.ReportedBy = ""
+		}
+		if incident.UpdatedBy == hexID {
+			incident.UpdatedBy = ""
+		}
+
+		redact := func(text string) string {
+			if pattern == nil {
+				return text
+			}
+			return pattern.ReplaceAllString(text, ErasedName)
+		}
+		incident.Description.Summary = redact(incident.Description.Summary)
+		incident.Description.Location = redact(incident.Description.Location)
+		incident.Description.Injuries = redact(incident.Description.Injuries)
+		incident.Description.Cause = redact(incident.Description.Cause)
+		for i, witness := range incident.Description.Witnesses {
+			incident.Description.Witnesses[i] = redact(witness)
+		}
+		incident.ActionsTaken = redact(incident.ActionsTaken)
+		incident.FollowUpNote = redact(incident.FollowUpNote)
+		incident.UpdatedAt = time.Now()
+
+		doc, err := s.seal(incident)
+		if err != nil {
+			return changed, err
+		}
+		if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": incident.ID}, doc); err != nil {
+			return changed, fmt.Errorf("failed to anonymize incident: %v", err)
+		}
+		changed++
+	}
+
+	return changed, nil
+}
+
+// RotateEncryption re-encrypts incidents stored as plaintext or under an old key
+// with the current key, and returns how many incidents were rewritten
+func (s *IncidentService) RotateEncryption(ctx context.Context) (int, error) {
+	if !s.keyring.Enabled() {
+		return 0, conflict(CodeNotConfigured, "encryption is not configured")
+	}
+
+	cursor, err := s.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get incidents: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	rotated := 0
+	for cursor.Next(ctx) {
+		var doc incidentDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return rotated, fmt.Errorf("failed to decode incident: %v", err)
+		}
+		if doc.Data != "" && !s.keyring.NeedsRotation(doc.Data) {
+			continue
+		}
+
+		incident, err := s.open(doc)
+		if err != nil {
+			return rotated, err
+		}
+		sealed, err := s.seal(incident)
+		if err != nil {
+			return rotated, err
+		}
+		if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": doc.ID}, sealed); err != nil {
+			return rotated, fmt.Errorf("failed to update incident: %v", err)
+		}
+		rotated++
+	}
+
+	return rotated, cursor.Err()
+}
+
+func (s *IncidentService) get(ctx context.Context, id string) (*models.Incident, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, invalidID("incident", err)
+	}
+
+	var doc incidentDocument
+	err = s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("incident not found")
+		}
+		return nil, fmt.Errorf("failed to get incident: %v", err)
+	}
+
+	incident, err := s.open(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+func (s *IncidentService) find(ctx context.Context, filter bson.M) ([]models.Incident, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: -1}})
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incidents: %v", err)
+	}
+
+	var docs []incidentDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode incidents: %v", err)
+	}
+
+	incidents := make([]models.Incident, len(docs))
+	for i, doc := range docs {
+		if incidents[i], err = s.open(doc); err != nil {
+			return nil, err
+		}
+	}
+	return incidents, nil
+}
+
+// getChildren checks that every ID is a child and returns them without duplicates
+func (s *IncidentService) getChildren(ctx context.Context, ids []string) ([]primitive.ObjectID, error) {
+	childIDs := []primitive.ObjectID{}
+	seen := make(map[primitive.ObjectID]bool)
+	for i, id := range ids {
+		child, err := s.peopleService.ReferencedChild(ctx, fmt.Sprintf("child_ids[%d]", i), id)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[child.ID] {
+			seen[child.ID] = true
+			childIDs = append(childIDs, child.ID)
+		}
+	}
+	if len(childIDs) == 0 {
+		return nil, invalid(CodeMissingField, "at least one child is required")
+	}
+	return childIDs, nil
+}
+
+// seal returns the document storing an incident, with its account encrypted when a keyring is configured
+func (s *IncidentService) seal(incident models.Incident) (incidentDocument, error) {
+	if !s.keyring.Enabled() {
+		return incidentDocument{Incident: incident}, nil
+	}
+
+	plaintext, err := json.Marshal(incidentDetails{
+		Description:  incident.Description,
+		ActionsTaken: incident.ActionsTaken,
+		FollowUpNote: incident.FollowUpNote,
+	})
+	if err != nil {
+		return incidentDocument{}, fmt.Errorf("failed to encode incident: %v", err)
+	}
+	sealed, err := s.keyring.Encrypt(string(plaintext))
+	if err != nil {
+		return incidentDocument{}, err
+	}
+
+	incident.Description = models.IncidentDescription{}
+	incident.ActionsTaken = ""
+	incident.FollowUpNote = ""
+	return incidentDocument{Incident: incident, Data: sealed}, nil
+}
+
+// open returns the incident held by a stored document, decrypting it if needed
+func (s *IncidentService) open(doc incidentDocument) (models.Incident, error) {
+	incident := doc.Incident
+	if doc.Data == "" {
+		return incident, nil
+	}
+
+	plaintext, err := s.keyring.Decrypt(doc.Data)
+	if err != nil {
+		return incident, err
+	}
+	var details incidentDetails
+	if err := json.Unmarshal([]byte(plaintext), &details); err != nil {
+		return incident, fmt.Errorf("failed to decode incident: %v", err)
+	}
+
+	incident.Description = details.Description
+	incident.ActionsTaken = details.ActionsTaken
+	incident.FollowUpNote = details.FollowUpNote
+	return incident, nil
+}
+
+// incidentsOf matches incidents involving a child or reported by a minister
+func incidentsOf(personID primitive.ObjectID) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"child_ids": personID},
+		bson.M{"reported_by": personID.Hex()},
+	}}
+}
+
+func validateIncident(incidentType, followUpStatus string, description models.IncidentDescription) error {
+	if !incidentTypes[incidentType] {
+		return invalid(CodeInvalidValue, "incident type must be one of injury, illness, behavior, safeguarding, other")
+	}
+	if !incidentFollowUpStatuses[followUpStatus] {
+		return invalid(CodeInvalidValue, "follow-up status must be one of none, required, completed")
+	}
+	if strings.TrimSpace(description.Summary) == "" {
+		return invalid(CodeMissingField, "incident summary is required")
+	}
+	return nil
+}
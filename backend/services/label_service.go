@@ -0,0 +1,360 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"eaglekidz-backend/models"
+
+	"github.com/jung-kurt/gofpdf"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LabelLayout describes the geometry of a label sheet or thermal roll in millimetres
+type LabelLayout struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	PageWidth   float64 `json:"page_width"`
+	PageHeight  float64 `json:"page_height"`
+	LabelWidth  float64 `json:"label_width"`
+	LabelHeight float64 `json:"label_height"`
+	Columns     int     `json:"columns"`
+	Rows        int     `json:"rows"`
+	MarginLeft  float64 `json:"margin_left"`
+	MarginTop   float64 `json:"margin_top"`
+	GapX        float64 `json:"gap_x"`
+	GapY        float64 `json:"gap_y"`
+}
+
+// DefaultLabelLayout is used when no layout is requested
+const DefaultLabelLayout = "thermal-62x29"
+
+// LabelLayouts lists the supported label stocks. Thermal rolls print one label per page.
+var LabelLayouts = map[string]LabelLayout{
+	"avery-5160": {
+		Name:        "avery-5160",
+		Description: "US Letter sheet, 30 labels of 2.625 x 1 in",
+		PageWidth:   215.9, PageHeight: 279.4,
+		LabelWidth: 66.675, LabelHeight: 25.4,
+		Columns: 3, Rows: 10,
+		MarginLeft: 4.7625, MarginTop: 12.7,
+		GapX: 3.175,
+	},
+	"avery-l7160": {
+		Name:        "avery-l7160",
+		Description: "A4 sheet, 21 labels of 63.5 x 38.1 mm",
+		PageWidth:   210, PageHeight: 297,
+		LabelWidth: 63.5, LabelHeight: 38.1,
+		Columns: 3, Rows: 7,
+		MarginLeft: 7.2, MarginTop: 15.15,
+		GapX: 2.5,
+	},
+	"thermal-62x29": {
+		Name:        "thermal-62x29",
+		Description: "62 mm thermal roll, 29 mm die-cut labels",
+		PageWidth:   62, PageHeight: 29,
+		LabelWidth: 62, LabelHeight: 29,
+		Columns: 1, Rows: 1,
+	},
+	"thermal-4x2": {
+		Name:        "thermal-4x2",
+		Description: "4 in thermal roll, 4 x 2 in labels",
+		PageWidth:   101.6, PageHeight: 50.8,
+		LabelWidth: 101.6, LabelHeight: 50.8,
+		Columns: 1, Rows: 1,
+	},
+}
+
+// labelPadding is the blank border kept inside every label
+const labelPadding = 2.0
+
+type LabelService struct {
+	attendanceService *AttendanceService
+	peopleService     *PeopleService
+	medicalService    *MedicalService
+}
+
+func NewLabelService(attendanceService *AttendanceService, peopleService *PeopleService, medicalService *MedicalService) *LabelService {
+	return &LabelService{
+		attendanceService: attendanceService,
+		peopleService:     peopleService,
+		medicalService:    medicalService,
+	}
+}
+
+// childLabel holds everything printed on a child tag and its pickup stub
+type childLabel struct {
+	Name         string
+	AgeGroup     string
+	Alerts       []string
+	SecurityCode string
+	Service      string
+}
+
+// GetLayout looks up a label layout by name, using the default when name is empty
+func GetLayout(name string) (LabelLayout, error) {
+	if name == "" {
+		name = DefaultLabelLayout
+	}
+	layout, ok := LabelLayouts[name]
+	if !ok {
+		return LabelLayout{}, invalid(CodeInvalidValue, "unknown label layout")
+	}
+	return layout, nil
+}
+
+// RenderAttendanceLabels renders the child tag and guardian pickup stub for one check-in.
+// The stub carries the pickup code, so ctx must carry an authorized caller.
+func (s *LabelService) RenderAttendanceLabels(ctx context.Context, attendanceID string, layoutName string) ([]byte, error) {
+	layout, err := GetLayout(layoutName)
+	if err != nil {
+		return nil, err
+	}
+
+	attendance, err := s.attendanceService.GetAttendanceByID(ctx, attendanceID)
+	if err != nil {
+		return nil, err
+	}
+	if attendance.CheckedOutAt != nil {
+		return nil, conflict(CodeAlreadyCheckedOut, "child is already checked out")
+	}
+
+	codes, err := s.attendanceService.pickupCodes(ctx, []primitive.ObjectID{attendance.ID})
+	if err != nil {
+		return nil, err
+	}
+	code, ok := codes[attendance.ID]
+	if !ok {
+		// Checked out since it was read
+		return nil, conflict(CodeAlreadyCheckedOut, "child is already checked out")
+	}
+
+	label, err := s.buildLabel(ctx, attendance, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderLabels(layout, []childLabel{label})
+}
+
+// RenderWeekLabels renders tags and stubs for every child currently checked in to a week,
+// optionally limited to one service. ctx must carry an authorized caller.
+func (s *LabelService) RenderWeekLabels(ctx context.Context, weekID, serviceName, serviceTime, layoutName string) ([]byte, error) {
+	layout, err := GetLayout(layoutName)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.attendanceService.getOpenCheckIns(ctx, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := []*models.Attendance{}
+	ids := []primitive.ObjectID{}
+	for i := range records {
+		if serviceName != "" && records[i].ServiceName != serviceName {
+			continue
+		}
+		if serviceTime != "" && records[i].ServiceTime != serviceTime {
+			continue
+		}
+		selected = append(selected, &records[i])
+		ids = append(ids, records[i].ID)
+	}
+	if len(selected) == 0 {
+		return nil, notFound("no children are checked in")
+	}
+
+	codes, err := s.attendanceService.pickupCodes(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := []childLabel{}
+	for _, attendance := range selected {
+		code, ok := codes[attendance.ID]
+		if !ok {
+			// Checked out since the week was read
+			continue
+		}
+		label, err := s.buildLabel(ctx, attendance, code)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+
+	if len(labels) == 0 {
+		return nil, notFound("no children are checked in")
+	}
+
+	sort.SliceStable(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return renderLabels(layout, labels)
+}
+
+func (s *LabelService) buildLabel(ctx context.Context, attendance *models.Attendance, securityCode string) (childLabel, error) {
+	child, err := s.peopleService.GetPeopleByID(ctx, attendance.ChildID.Hex())
+	if err != nil {
+		return childLabel{}, err
+	}
+
+	flags, err := s.medicalService.GetAlertFlags(ctx, []primitive.ObjectID{child.ID})
+	if err != nil {
+		return childLabel{}, err
+	}
+
+	return childLabel{
+		Name:         strings.TrimSpace(child.FirstName + " " + child.LastName),
+		AgeGroup:     strings.Join(child.AgeGroup, ", "),
+		Alerts:       labelAlerts(flags[child.ID]),
+		SecurityCode: securityCode,
+		Service:      strings.TrimSpace(attendance.ServiceTime + " " + attendance.ServiceName),
+	}, nil
+}
+
+// labelAlerts returns the warnings printed on a child's tag. Space is tight, so only
+// allergies and the emergency plan are printed; other flags are on the roster.
+func labelAlerts(flags []models.AlertFlag) []string {
+	var alerts []string
+	for _, flag := range flags {
+		switch flag.Type {
+		case models.AlertTypeAllergy:
+			label := strings.ToUpper(flag.Label)
+			if flag.Severity == models.SeveritySevere || flag.Severity == models.SeverityLifeThreatening {
+				label += "!"
+			}
+			alerts = append(alerts, label)
+		case models.AlertTypeEmergencyPlan:
+			alerts = append(alerts, "ACTION PLAN")
+		}
+	}
+	return alerts
+}
+
+// renderLabels lays out a child tag followed by its pickup stub for each label
+func renderLabels(layout LabelLayout, labels []childLabel) ([]byte, error) {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr: "mm",
+		Size:    gofpdf.SizeType{Wd: layout.PageWidth, Ht: layout.PageHeight},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetCellMargin(0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	perPage := layout.Columns * layout.Rows
+	slot := 0
+	draw := func(render func(x, y float64)) {
+		if slot%perPage == 0 {
+			pdf.AddPage()
+		}
+		position := slot % perPage
+		x := layout.MarginLeft + float64(position%layout.Columns)*(layout.LabelWidth+layout.GapX)
+		y := layout.MarginTop + float64(position/layout.Columns)*(layout.LabelHeight+layout.GapY)
+		render(x, y)
+		slot++
+	}
+
+	for _, label := range labels {
+		label := label
+		draw(func(x, y float64) { drawChildTag(pdf, tr, layout, label, x, y) })
+		draw(func(x, y float64) { drawPickupStub(pdf, tr, layout, label, x, y) })
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render labels: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawChildTag prints name, age group and alerts on the left and the security code on the right
+func drawChildTag(pdf *gofpdf.Fpdf, tr func(string) string, layout LabelLayout, label childLabel, x, y float64) {
+	h := layout.LabelHeight - 2*labelPadding
+	codeWidth := layout.LabelWidth * 0.3
+	textWidth := layout.LabelWidth - codeWidth - 3*labelPadding
+	left := x + labelPadding
+	top := y + labelPadding
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "B", fitFontSize(pdf, tr(label.Name), "B", textWidth, h*0.35))
+	pdf.SetXY(left, top)
+	pdf.CellFormat(textWidth, h*0.35, tr(label.Name), "", 0, "LM", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", fitFontSize(pdf, tr(label.AgeGroup), "", textWidth, h*0.2))
+	pdf.SetXY(left, top+h*0.35)
+	pdf.CellFormat(textWidth, h*0.2, tr(label.AgeGroup), "", 0, "LM", false, 0, "")
+
+	if len(label.Alerts) > 0 {
+		alerts := strings.Join(label.Alerts, " | ")
+		pdf.SetTextColor(200, 0, 0)
+		pdf.SetFont("Helvetica", "B", fitFontSize(pdf, tr(alerts), "B", textWidth, h*0.2))
+		pdf.SetXY(left, top+h*0.55)
+		pdf.CellFormat(textWidth, h*0.2, tr(alerts), "", 0, "LM", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	pdf.SetFont("Helvetica", "", fitFontSize(pdf, tr(label.Service), "", textWidth, h*0.2))
+	pdf.SetXY(left, top+h*0.8)
+	pdf.CellFormat(textWidth, h*0.2, tr(label.Service), "", 0, "LM", false, 0, "")
+
+	drawSecurityCode(pdf, label.SecurityCode, x+layout.LabelWidth-codeWidth-labelPadding, top, codeWidth, h)
+}
+
+// drawPickupStub prints the guardian's copy of the security code
+func drawPickupStub(pdf *gofpdf.Fpdf, tr func(string) string, layout LabelLayout, label childLabel, x, y float64) {
+	h := layout.LabelHeight - 2*labelPadding
+	codeWidth := layout.LabelWidth * 0.3
+	textWidth := layout.LabelWidth - codeWidth - 3*labelPadding
+	left := x + labelPadding
+	top := y + labelPadding
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "B", fitFontSize(pdf, "PICKUP", "B", textWidth, h*0.3))
+	pdf.SetXY(left, top)
+	pdf.CellFormat(textWidth, h*0.3, "PICKUP", "", 0, "LM", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", fitFontSize(pdf, tr(label.Name), "", textWidth, h*0.25))
+	pdf.SetXY(left, top+h*0.35)
+	pdf.CellFormat(textWidth, h*0.25, tr(label.Name), "", 0, "LM", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", fitFontSize(pdf, tr(label.Service), "", textWidth, h*0.2))
+	pdf.SetXY(left, top+h*0.65)
+	pdf.CellFormat(textWidth, h*0.2, tr(label.Service), "", 0, "LM", false, 0, "")
+
+	drawSecurityCode(pdf, label.SecurityCode, x+layout.LabelWidth-codeWidth-labelPadding, top, codeWidth, h)
+}
+
+// drawSecurityCode prints the code as large as the box allows inside a border
+func drawSecurityCode(pdf *gofpdf.Fpdf, code string, x, y, w, h float64) {
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetLineWidth(0.4)
+	pdf.Rect(x, y, w, h, "D")
+	pdf.SetFont("Helvetica", "B", fitFontSize(pdf, code, "B", w-2, h*0.6))
+	pdf.SetXY(x, y)
+	pdf.CellFormat(w, h, code, "", 0, "CM", false, 0, "")
+}
+
+// fitFontSize returns the largest font size in points that fits text in width and height (mm)
+func fitFontSize(pdf *gofpdf.Fpdf, text, style string, width, height float64) float64 {
+	// One point is 0.3528 mm, and a line needs roughly its font size in height
+	size := height / 0.3528
+	if size > 28 {
+		size = 28
+	}
+	if text == "" {
+		return size
+	}
+	pdf.SetFont("Helvetica", style, size)
+	if textWidth := pdf.GetStringWidth(text); textWidth > width {
+		size = size * width / textWidth
+	}
+	if size < 4 {
+		size = 4
+	}
+	return size
+}
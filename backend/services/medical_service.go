@@ -0,0 +1,494 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/encryption"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const MedicalCollection = "medical_info"
+
+// AuditEntityMedical is the audit entity type for medical records
+const AuditEntityMedical = "medical_info"
+
+var allergySeverities = map[string]bool{
+	models.SeverityMild:            true,
+	models.SeverityModerate:        true,
+	models.SeveritySevere:          true,
+	models.SeverityLifeThreatening: true,
+}
+
+// medicalDocument is how a medical record is stored. With encryption configured the
+// clinical fields are kept together as encrypted JSON in Data; records saved before
+// encryption was enabled still carry them as plain fields.
+type medicalDocument struct {
+	models.MedicalInfo `bson:",inline"`
+	Data               string `bson:"data,omitempty"`
+}
+
+// medicalDetails holds the clinical fields sealed into medicalDocument.Data
+type medicalDetails struct {
+	Allergies           []models.Allergy    `json:"allergies"`
+	Medications         []models.Medication `json:"medications"`
+	Conditions          []string            `json:"conditions"`
+	SpecialNeeds        []string            `json:"special_needs"`
+	EmergencyActionPlan string              `json:"emergency_action_plan,omitempty"`
+}
+
+// medicalFields are the plain clinical fields cleared once a record is encrypted
+var medicalFields = []string{"allergies", "medications", "conditions", "special_needs", "emergency_action_plan"}
+
+type MedicalService struct {
+	collection    *mongo.Collection
+	attendance    *mongo.Collection
+	peopleService *PeopleService
+	auditService  *AuditService
+	keyring       *encryption.Keyring
+}
+
+func NewMedicalService(peopleService *PeopleService, auditService *AuditService, keyring *encryption.Keyring) *MedicalService {
+	return &MedicalService{
+		collection:    database.GetCollection(MedicalCollection),
+		attendance:    database.GetCollection(AttendanceCollection),
+		peopleService: peopleService,
+		auditService:  auditService,
+		keyring:       keyring,
+	}
+}
+
+// GetMedicalInfo retrieves a child's medical record for an authorized minister and audits the view
+func (s *MedicalService) GetMedicalInfo(ctx context.Context, personID string) (*models.MedicalInfo, error) {
+	caller, ok := AuthorizedCaller(ctx)
+	if !ok {
+		return nil, forbidden("caller is not authorized")
+	}
+
+	child, err := s.getChild(ctx, personID)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc medicalDocument
+	err = s.collection.FindOne(ctx, bson.M{"person_id": child.ID}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("medical info not found")
+		}
+		return nil, fmt.Errorf("failed to get medical info: %v", err)
+	}
+
+	info, err := s.open(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(ctx, AuditActionView, AuditEntityMedical, child.ID, caller.ID.Hex(), ""); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// UpsertMedicalInfo creates or replaces a child's medical record and audits the change
+func (s *MedicalService) UpsertMedicalInfo(ctx context.Context, personID string, req models.UpsertMedicalInfoRequest) (*models.MedicalInfo, error) {
+	caller, ok := AuthorizedCaller(ctx)
+	if !ok {
+		return nil, forbidden("caller is not authorized")
+	}
+	actorID := caller.ID.Hex()
+
+	child, err := s.getChild(ctx, personID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, allergy := range req.Allergies {
+		if strings.TrimSpace(allergy.Allergen) == "" {
+			return nil, invalid(CodeMissingField, "allergen is required")
+		}
+		if !allergySeverities[allergy.Severity] {
+			return nil, invalid(CodeInvalidValue, "allergy severity must be one of mild, moderate, severe, life_threatening")
+		}
+	}
+	for _, medication := range req.Medications {
+		if strings.TrimSpace(medication.Name) == "" {
+			return nil, invalid(CodeMissingField, "medication name is required")
+		}
+	}
+
+	details := medicalDetails{
+		Allergies:           nonNilAllergies(req.Allergies),
+		Medications:         nonNilMedications(req.Medications),
+		Conditions:          nonNilStrings(req.Conditions),
+		SpecialNeeds:        nonNilStrings(req.SpecialNeeds),
+		EmergencyActionPlan: req.EmergencyActionPlan,
+	}
+
+	now := time.Now()
+	update, err := s.sealUpdate(details)
+	if err != nil {
+		return nil, err
+	}
+	update["$set"].(bson.M)["updated_by"] = actorID
+	update["$set"].(bson.M)["updated_at"] = now
+	update["$setOnInsert"] = bson.M{
+		"_id":        primitive.NewObjectID(),
+		"created_at": now,
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var doc medicalDocument
+	err = s.collection.FindOneAndUpdate(ctx, bson.M{"person_id": child.ID}, update, opts).Decode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save medical info: %v", err)
+	}
+
+	info, err := s.open(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(ctx, AuditActionUpdate, AuditEntityMedical, child.ID, actorID, ""); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetAlertFlags returns the alert flags for each of the given people that has a medical record.
+// Flags name the allergen, medication, condition or need only for authorized callers, and
+// those views are written to the audit trail; other callers get generic labels.
+func (s *MedicalService) GetAlertFlags(ctx context.Context, personIDs []primitive.ObjectID) (map[primitive.ObjectID][]models.AlertFlag, error) {
+	flags := make(map[primitive.ObjectID][]models.AlertFlag)
+	if len(personIDs) == 0 {
+		return flags, nil
+	}
+
+	cursor, err := s.collection.Find(ctx, bson.M{"person_id": bson.M{"$in": personIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get medical info: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []medicalDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode medical info: %v", err)
+	}
+
+	// Flags are shown to everyone serving with the child, so they are derived here
+	// even though the full record is only returned to authorized callers
+	caller, details := AuthorizedCaller(ctx)
+	for _, doc := range docs {
+		record, err := s.open(doc)
+		if err != nil {
+			return nil, err
+		}
+		recordFlags := AlertFlagsFor(record, details)
+		if len(recordFlags) == 0 {
+			continue
+		}
+		if details {
+			if err := s.auditService.Record(ctx, AuditActionView, AuditEntityMedical, record.PersonID, caller.ID.Hex(), "alert flags"); err != nil {
+				return nil, err
+			}
+		}
+		flags[record.PersonID] = recordFlags
+	}
+
+	return flags, nil
+}
+
+// GetServiceAlerts returns, for each service of the week, the alert flags of the
+// children currently checked in to it. Keys are built with ServiceKey.
+func (s *MedicalService) GetServiceAlerts(ctx context.Context, week *models.Week) (map[string][]models.ChildAlert, error) {
+	cursor, err := s.attendance.Find(ctx, bson.M{
+		"week_id":        week.ID,
+		"checked_out_at": bson.M{"$exists": false},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var records []models.Attendance
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode attendance: %v", err)
+	}
+
+	childIDs := make([]primitive.ObjectID, 0, len(records))
+	for _, record := range records {
+		childIDs = append(childIDs, record.ChildID)
+	}
+
+	flags, err := s.GetAlertFlags(ctx, childIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	flagged := make([]primitive.ObjectID, 0, len(flags))
+	for childID := range flags {
+		flagged = append(flagged, childID)
+	}
+	children, err := s.peopleService.find(ctx, bson.M{"_id": bson.M{"$in": flagged}, "deleted": false})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get children: %v", err)
+	}
+	childrenByID := make(map[primitive.ObjectID]models.People, len(children))
+	for _, child := range children {
+		childrenByID[child.ID] = child
+	}
+
+	alerts := make(map[string][]models.ChildAlert)
+	seen := make(map[string]bool)
+	for _, record := range records {
+		childFlags, ok := flags[record.ChildID]
+		key := ServiceKey(record.ServiceName, record.ServiceTime)
+		if !ok || seen[key+record.ChildID.Hex()] {
+			continue
+		}
+		seen[key+record.ChildID.Hex()] = true
+
+		alert := models.ChildAlert{ChildID: record.ChildID, Flags: childFlags}
+		if child, ok := childrenByID[record.ChildID]; ok {
+			alert.FirstName = child.FirstName
+			alert.LastName = child.LastName
+		}
+		alerts[key] = append(alerts[key], alert)
+	}
+
+	return alerts, nil
+}
+
+// ServiceKey identifies a service within a week by its name and time
+func ServiceKey(name, serviceTime string) string {
+	return serviceTime + "|" + name
+}
+
+// genericAlertLabels label the flags of callers not authorized for the medical record
+var genericAlertLabels = map[string]string{
+	models.AlertTypeAllergy:       "Allergy",
+	models.AlertTypeMedication:    "Medication",
+	models.AlertTypeCondition:     "Medical condition",
+	models.AlertTypeSpecialNeeds:  "Special needs",
+	models.AlertTypeEmergencyPlan: "Emergency action plan on file",
+}
+
+// AlertFlagsFor derives the alert flags shown for a medical record. Without details each
+// flag keeps its type and severity but carries a generic label, since what it names is
+// part of the restricted record.
+func AlertFlagsFor(info models.MedicalInfo, details bool) []models.AlertFlag {
+	var flags []models.AlertFlag
+	for _, allergy := range info.Allergies {
+		flags = append(flags, models.AlertFlag{
+			Type:     models.AlertTypeAllergy,
+			Label:    allergy.Allergen + " allergy",
+			Severity: allergy.Severity,
+		})
+	}
+	for _, medication := range info.Medications {
+		flags = append(flags, models.AlertFlag{
+			Type:  models.AlertTypeMedication,
+			Label: "Takes " + medication.Name,
+		})
+	}
+	for _, condition := range info.Conditions {
+		flags = append(flags, models.AlertFlag{
+			Type:  models.AlertTypeCondition,
+			Label: condition,
+		})
+	}
+	for _, need := range info.SpecialNeeds {
+		flags = append(flags, models.AlertFlag{
+			Type:  models.AlertTypeSpecialNeeds,
+			Label: need,
+		})
+	}
+	if strings.TrimSpace(info.EmergencyActionPlan) != "" {
+		flags = append(flags, models.AlertFlag{
+			Type:  models.AlertTypeEmergencyPlan,
+			Label: "Emergency action plan on file",
+		})
+	}
+
+	if !details {
+		for i := range flags {
+			flags[i].Label = genericAlertLabels[flags[i].Type]
+		}
+	}
+	return flags
+}
+
+// GetMedicalRecord returns a person's medical record without auditing the read, or nil
+// when they have none. Callers are responsible for recording why it was read.
+func (s *MedicalService) GetMedicalRecord(ctx context.Context, personID primitive.ObjectID) (*models.MedicalInfo, error) {
+	var doc medicalDocument
+	err := s.collection.FindOne(ctx, bson.M{"person_id": personID}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get medical info: %v", err)
+	}
+
+	info, err := s.open(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// DeleteMedicalRecord removes a person's medical record and returns how many records were removed
+func (s *MedicalService) DeleteMedicalRecord(ctx context.Context, personID primitive.ObjectID) (int64, error) {
+	result, err := s.collection.DeleteMany(ctx, bson.M{"person_id": personID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete medical info: %v", err)
+	}
+	return result.DeletedCount, nil
+}
+
+// RotateEncryption re-encrypts medical records stored as plaintext or under an old key
+// with the current key, and returns how many records were rewritten
+func (s *MedicalService) RotateEncryption(ctx context.Context) (int, error) {
+	if !s.keyring.Enabled() {
+		return 0, conflict(CodeNotConfigured, "encryption is not configured")
+	}
+
+	cursor, err := s.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get medical info: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	rotated := 0
+	for cursor.Next(ctx) {
+		var doc medicalDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return rotated, fmt.Errorf("failed to decode medical info: %v", err)
+		}
+		if doc.Data != "" && !s.keyring.NeedsRotation(doc.Data) {
+			continue
+		}
+
+		info, err := s.open(doc)
+		if err != nil {
+			return rotated, err
+		}
+		update, err := s.sealUpdate(medicalDetails{
+			Allergies:           info.Allergies,
+			Medications:         info.Medications,
+			Conditions:          info.Conditions,
+			SpecialNeeds:        info.SpecialNeeds,
+			EmergencyActionPlan: info.EmergencyActionPlan,
+		})
+		if err != nil {
+			return rotated, err
+		}
+
+		if _, err := s.collection.UpdateOne(ctx, bson.M{"_id": doc.ID}, update); err != nil {
+			return rotated, fmt.Errorf("failed to update medical info: %v", err)
+		}
+		rotated++
+	}
+
+	return rotated, cursor.Err()
+}
+
+// sealUpdate builds the update storing details, encrypted when a keyring is configured
+func (s *MedicalService) sealUpdate(details medicalDetails) (bson.M, error) {
+	if !s.keyring.Enabled() {
+		return bson.M{
+			"$set": bson.M{
+				"allergies":             details.Allergies,
+				"medications":           details.Medications,
+				"conditions":            details.Conditions,
+				"special_needs":         details.SpecialNeeds,
+				"emergency_action_plan": details.EmergencyActionPlan,
+			},
+		}, nil
+	}
+
+	plaintext, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode medical info: %v", err)
+	}
+	sealed, err := s.keyring.Encrypt(string(plaintext))
+	if err != nil {
+		return nil, err
+	}
+
+	unset := bson.M{}
+	for _, field := range medicalFields {
+		unset[field] = ""
+	}
+	return bson.M{
+		"$set":   bson.M{"data": sealed},
+		"$unset": unset,
+	}, nil
+}
+
+// open returns the medical record held by a stored document, decrypting it if needed
+func (s *MedicalService) open(doc medicalDocument) (models.MedicalInfo, error) {
+	info := doc.MedicalInfo
+	if doc.Data == "" {
+		return info, nil
+	}
+
+	plaintext, err := s.keyring.Decrypt(doc.Data)
+	if err != nil {
+		return info, err
+	}
+	var details medicalDetails
+	if err := json.Unmarshal([]byte(plaintext), &details); err != nil {
+		return info, fmt.Errorf("failed to decode medical info: %v", err)
+	}
+
+	info.Allergies = details.Allergies
+	info.Medications = details.Medications
+	info.Conditions = details.Conditions
+	info.SpecialNeeds = details.SpecialNeeds
+	info.EmergencyActionPlan = details.EmergencyActionPlan
+	return info, nil
+}
+
+func (s *MedicalService) getChild(ctx context.Context, personID string) (*models.People, error) {
+	child, err := s.peopleService.GetPeopleByID(ctx, personID)
+	if err != nil {
+		return nil, err
+	}
+	if child.Type != "children" {
+		return nil, invalid(CodeInvalidValue, "medical info is only kept for children")
+	}
+	return child, nil
+}
+
+func nonNilAllergies(values []models.Allergy) []models.Allergy {
+	if values == nil {
+		return []models.Allergy{}
+	}
+	return values
+}
+
+func nonNilMedications(values []models.Medication) []models.Medication {
+	if values == nil {
+		return []models.Medication{}
+	}
+	return values
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
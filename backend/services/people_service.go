@@ -2,9 +2,10 @@ package services
 
 import (
 	"context"
-	"errors"
+	"strings"
 	"time"
 
+	"eaglekidz-backend/encryption"
 	"eaglekidz-backend/models"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -12,83 +13,156 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+const PeopleCollection = "people"
+
+// AllStatuses selects people of every status in list filters
+const AllStatuses = "all"
+
+// statusTransitions lists the statuses each status may move to
+var statusTransitions = map[string][]string{
+	models.PeopleStatusProspect:  {models.PeopleStatusActive, models.PeopleStatusInactive},
+	models.PeopleStatusActive:    {models.PeopleStatusInactive, models.PeopleStatusGraduated},
+	models.PeopleStatusInactive:  {models.PeopleStatusActive, models.PeopleStatusAlumni},
+	models.PeopleStatusGraduated: {models.PeopleStatusAlumni, models.PeopleStatusActive},
+	models.PeopleStatusAlumni:    {models.PeopleStatusActive},
+}
+
 type PeopleService struct {
-	collection *mongo.Collection
+	collection      *mongo.Collection
+	keyring         *encryption.Keyring
+	ageGroupService *AgeGroupService
+	roleService     *RoleService
 }
 
-func NewPeopleService(db *mongo.Database) *PeopleService {
+func NewPeopleService(db *mongo.Database, keyring *encryption.Keyring, ageGroupService *AgeGroupService, roleService *RoleService) *PeopleService {
 	return &PeopleService{
-		collection: db.Collection("people"),
+		collection:      db.Collection(PeopleCollection),
+		keyring:         keyring,
+		ageGroupService: ageGroupService,
+		roleService:     roleService,
 	}
 }
 
 // CreatePeople creates a new person
-func (s *PeopleService) CreatePeople(req models.CreatePeopleRequest) (*models.People, error) {
+func (s *PeopleService) CreatePeople(ctx context.Context, req models.CreatePeopleRequest) (*models.People, error) {
+	status := req.Status
+	if status == "" {
+		status = models.PeopleStatusActive
+	}
+	if _, ok := statusTransitions[status]; !ok {
+		return nil, invalid(CodeInvalidValue, "status must be one of prospect, active, inactive, graduated, alumni")
+	}
+	// Ministers serve in a role; a child's age group comes from their date of birth when they have one
+	if req.Type == "minister" && len(req.Roles) == 0 {
+		return nil, invalid(CodeMissingField, "roles are required for ministers")
+	}
+	if req.Type == "children" && req.DateOfBirth == nil && len(req.AgeGroup) == 0 {
+		return nil, invalid(CodeMissingField, "age_group is required for children without a date of birth")
+	}
+
+	ageGroup, roles, err := s.canonicalCatalogs(ctx, req.AgeGroup, req.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
 	people := models.People{
-		ID:        primitive.NewObjectID(),
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		Type:      req.Type,
-		AgeGroup:  req.AgeGroup,
-		Roles:     req.Roles,
-		Phone:     req.Phone,
-		Email:     req.Email,
-		Notes:     req.Notes,
+		ID:          primitive.NewObjectID(),
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		Type:        req.Type,
+		AgeGroup:    ageGroup,
+		DateOfBirth: req.DateOfBirth,
+		Roles:       roles,
+		Household:   strings.TrimSpace(req.Household),
+		Phone:       req.Phone,
+		Email:       req.Email,
+		Notes:       req.Notes,
+		Status:      status,
+		StatusHistory: []models.StatusChange{
+			{To: status, ChangedAt: now},
+		},
 		Deleted:   false,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
-	_, err := s.collection.InsertOne(context.Background(), people)
-	if err != nil {
+	if err := s.assignAgeGroup(ctx, &people); err != nil {
 		return nil, err
 	}
 
-	return &people, nil
-}
+	stored := people
+	if err := s.protect(&stored); err != nil {
+		return nil, err
+	}
 
-// GetAllPeople retrieves all non-deleted people
-func (s *PeopleService) GetAllPeople() ([]models.People, error) {
-	filter := bson.M{"deleted": false}
-	cursor, err := s.collection.Find(context.Background(), filter)
+	_, err = s.collection.InsertOne(ctx, stored)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
 
-	var people []models.People
-	if err = cursor.All(context.Background(), &people); err != nil {
+	if err := s.reveal(ctx, &people); err != nil {
 		return nil, err
 	}
 
-	return people, nil
+	return &people, nil
 }
 
-// GetPeopleByType retrieves all non-deleted people of a specific type
-func (s *PeopleService) GetPeopleByType(peopleType string) ([]models.People, error) {
-	filter := bson.M{
-		"deleted": false,
-		"type":    peopleType,
-	}
-	cursor, err := s.collection.Find(context.Background(), filter)
+// GetAllPeople retrieves all non-deleted people with the given status. An empty
+// status selects active people and AllStatuses selects everyone; several statuses
+// may be given separated by commas.
+func (s *PeopleService) GetAllPeople(ctx context.Context, status string) ([]models.People, error) {
+	filter, err := statusFilter(status)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	filter["deleted"] = false
+	return s.find(ctx, filter)
+}
 
-	var people []models.People
-	if err = cursor.All(context.Background(), &people); err != nil {
+// GetPeopleByType retrieves all non-deleted people of a specific type with the given
+// status, selected as for GetAllPeople
+func (s *PeopleService) GetPeopleByType(ctx context.Context, peopleType, status string) ([]models.People, error) {
+	filter, err := statusFilter(status)
+	if err != nil {
 		return nil, err
 	}
+	filter["deleted"] = false
+	filter["type"] = peopleType
+	return s.find(ctx, filter)
+}
 
-	return people, nil
+// GetPeopleByPhone retrieves all non-deleted people with the given phone number and
+// status for an authorized caller, selected as for GetAllPeople. Encrypted phone
+// numbers are matched through their blind index.
+func (s *PeopleService) GetPeopleByPhone(ctx context.Context, phone, status string) ([]models.People, error) {
+	if _, ok := AuthorizedCaller(ctx); !ok {
+		return nil, forbidden("caller is not authorized")
+	}
+
+	normalized := normalizePhone(phone)
+	matches := bson.A{
+		bson.M{"phone": phone},
+		bson.M{"phone": normalized},
+	}
+	if index := s.keyring.BlindIndex(normalized); index != "" {
+		matches = append(matches, bson.M{"phone_index": index})
+	}
+
+	filter, err := statusFilter(status)
+	if err != nil {
+		return nil, err
+	}
+	filter["deleted"] = false
+	filter["$or"] = matches
+	return s.find(ctx, filter)
 }
 
 // GetPeopleByID retrieves a person by ID
-func (s *PeopleService) GetPeopleByID(id string) (*models.People, error) {
+func (s *PeopleService) GetPeopleByID(ctx context.Context, id string) (*models.People, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid ID format")
+		return nil, invalid(CodeInvalidID, "invalid ID format")
 	}
 
 	filter := bson.M{
@@ -97,22 +171,50 @@ func (s *PeopleService) GetPeopleByID(id string) (*models.People, error) {
 	}
 
 	var people models.People
-	err = s.collection.FindOne(context.Background(), filter).Decode(&people)
+	err = s.collection.FindOne(ctx, filter).Decode(&people)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("person not found")
+			return nil, notFound("person not found")
 		}
 		return nil, err
 	}
 
+	if err := s.reveal(ctx, &people); err != nil {
+		return nil, err
+	}
+
 	return &people, nil
 }
 
 // UpdatePeople updates a person
-func (s *PeopleService) UpdatePeople(id string, req models.UpdatePeopleRequest) (*models.People, error) {
+func (s *PeopleService) UpdatePeople(ctx context.Context, id string, req models.UpdatePeopleRequest) (*models.People, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid ID format")
+		return nil, invalid(CodeInvalidID, "invalid ID format")
+	}
+
+	ageGroup, roles, err := s.canonicalCatalogs(ctx, req.AgeGroup, req.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{
+		"_id":     objID,
+		"deleted": false,
+	}
+
+	// Protected fields depend on the person's type, so they are rewritten from the
+	// merged record rather than set individually
+	var people models.People
+	err = s.collection.FindOne(ctx, filter).Decode(&people)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("person not found")
+		}
+		return nil, err
+	}
+	if err := s.decrypt(&people); err != nil {
+		return nil, err
 	}
 
 	update := bson.M{
@@ -129,49 +231,67 @@ func (s *PeopleService) UpdatePeople(id string, req models.UpdatePeopleRequest)
 	}
 	if req.Type != nil {
 		update["$set"].(bson.M)["type"] = *req.Type
+		people.Type = *req.Type
 	}
-	if req.AgeGroup != nil {
-		update["$set"].(bson.M)["age_group"] = req.AgeGroup
+	if ageGroup != nil {
+		people.AgeGroup = ageGroup
+		update["$set"].(bson.M)["age_group"] = ageGroup
 	}
-	if req.Roles != nil {
-		update["$set"].(bson.M)["roles"] = req.Roles
+	if req.DateOfBirth != nil {
+		people.DateOfBirth = req.DateOfBirth
+		update["$set"].(bson.M)["date_of_birth"] = req.DateOfBirth
+	}
+	if err := s.assignAgeGroup(ctx, &people); err != nil {
+		return nil, err
+	}
+	if people.Type == "children" && people.DateOfBirth != nil {
+		update["$set"].(bson.M)["age_group"] = people.AgeGroup
+	}
+	if roles != nil {
+		update["$set"].(bson.M)["roles"] = roles
+	}
+	if req.Household != nil {
+		update["$set"].(bson.M)["household"] = strings.TrimSpace(*req.Household)
 	}
 	if req.Phone != nil {
-		update["$set"].(bson.M)["phone"] = *req.Phone
+		people.Phone = *req.Phone
 	}
 	if req.Email != nil {
-		update["$set"].(bson.M)["email"] = *req.Email
+		people.Email = *req.Email
 	}
 	if req.Notes != nil {
-		update["$set"].(bson.M)["notes"] = *req.Notes
+		people.Notes = *req.Notes
 	}
 
-	filter := bson.M{
-		"_id":     objID,
-		"deleted": false,
+	if err := s.protect(&people); err != nil {
+		return nil, err
 	}
+	update["$set"].(bson.M)["phone"] = people.Phone
+	update["$set"].(bson.M)["email"] = people.Email
+	update["$set"].(bson.M)["notes"] = people.Notes
+	update["$set"].(bson.M)["phone_index"] = people.PhoneIndex
 
 	result := s.collection.FindOneAndUpdate(
-		context.Background(),
+		ctx,
 		filter,
 		update,
 	)
 
 	if result.Err() != nil {
 		if result.Err() == mongo.ErrNoDocuments {
-			return nil, errors.New("person not found")
+			return nil, notFound("person not found")
 		}
 		return nil, result.Err()
 	}
 
-	return s.GetPeopleByID(id)
+	return s.GetPeopleByID(ctx, id)
 }
 
 // DeletePeople soft deletes a person
-func (s *PeopleService) DeletePeople(id string) error {
+func (s *PeopleService) DeletePeople(ctx context.Context, id string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid ID format")
+		return invalid(CodeInvalidID, "invalid ID format")
 	}
 
 	update := bson.M{
@@ -186,40 +306,29 @@ func (s *PeopleService) DeletePeople(id string) error {
 		"deleted": false,
 	}
 
-	result, err := s.collection.UpdateOne(context.Background(), filter, update)
+	result, err := s.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
 
 	if result.MatchedCount == 0 {
-		return errors.New("person not found")
+		return notFound("person not found")
 	}
 
 	return nil
 }
 
 // GetDeletedPeople retrieves all deleted people
-func (s *PeopleService) GetDeletedPeople() ([]models.People, error) {
+func (s *PeopleService) GetDeletedPeople(ctx context.Context) ([]models.People, error) {
 	filter := bson.M{"deleted": true}
-	cursor, err := s.collection.Find(context.Background(), filter)
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(context.Background())
-
-	var people []models.People
-	if err = cursor.All(context.Background(), &people); err != nil {
-		return nil, err
-	}
-
-	return people, nil
+	return s.find(ctx, filter)
 }
 
 // HardDeletePeople permanently deletes a person
-func (s *PeopleService) HardDeletePeople(id string) error {
+func (s *PeopleService) HardDeletePeople(ctx context.Context, id string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid ID format")
+		return invalid(CodeInvalidID, "invalid ID format")
 	}
 
 	filter := bson.M{
@@ -227,23 +336,23 @@ func (s *PeopleService) HardDeletePeople(id string) error {
 		"deleted": true,
 	}
 
-	result, err := s.collection.DeleteOne(context.Background(), filter)
+	result, err := s.collection.DeleteOne(ctx, filter)
 	if err != nil {
 		return err
 	}
 
 	if result.DeletedCount == 0 {
-		return errors.New("person not found or not deleted")
+		return notFound("person not found or not deleted")
 	}
 
 	return nil
 }
 
 // RestorePeople restores a soft-deleted person
-func (s *PeopleService) RestorePeople(id string) (*models.People, error) {
+func (s *PeopleService) RestorePeople(ctx context.Context, id string) (*models.People, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid ID format")
+		return nil, invalid(CodeInvalidID, "invalid ID format")
 	}
 
 	update := bson.M{
@@ -259,17 +368,313 @@ func (s *PeopleService) RestorePeople(id string) (*models.People, error) {
 	}
 
 	result := s.collection.FindOneAndUpdate(
-		context.Background(),
+		ctx,
 		filter,
 		update,
 	)
 
 	if result.Err() != nil {
 		if result.Err() == mongo.ErrNoDocuments {
-			return nil, errors.New("person not found or not deleted")
+			return nil, notFound("person not found or not deleted")
 		}
 		return nil, result.Err()
 	}
 
-	return s.GetPeopleByID(id)
-}
\ No newline at end of file
+	return s.GetPeopleByID(ctx, id)
+}
+
+// GetPeopleRecord retrieves a person by ID whether or not they have been soft deleted
+func (s *PeopleService) GetPeopleRecord(ctx context.Context, id primitive.ObjectID) (*models.People, error) {
+	var people models.People
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&people)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("person not found")
+		}
+		return nil, err
+	}
+
+	if err := s.reveal(ctx, &people); err != nil {
+		return nil, err
+	}
+
+	return &people, nil
+}
+
+// ErasePeople permanently removes a person's record whether or not they have been soft deleted
+func (s *PeopleService) ErasePeople(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return notFound("person not found")
+	}
+
+	return nil
+}
+
+// ChangeStatus moves a person to a new status and records the transition in their history
+func (s *PeopleService) ChangeStatus(ctx context.Context, id string, req models.ChangeStatusRequest) (*models.People, error) {
+	if _, ok := statusTransitions[req.Status]; !ok {
+		return nil, invalid(CodeInvalidValue, "status must be one of prospect, active, inactive, graduated, alumni")
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, invalid(CodeMissingField, "reason is required")
+	}
+
+	people, err := s.GetPeopleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := false
+	for _, next := range statusTransitions[people.Status] {
+		if next == req.Status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, conflict(CodeInvalidTransition, "cannot change status from %s to %s", people.Status, req.Status)
+	}
+
+	now := time.Now()
+	change := models.StatusChange{
+		From:      people.Status,
+		To:        req.Status,
+		Reason:    strings.TrimSpace(req.Reason),
+		ChangedBy: req.ChangedBy,
+		ChangedAt: now,
+	}
+
+	// Matching on the status read above keeps two concurrent changes from both applying
+	filter := statusMatch([]string{people.Status})
+	filter["_id"] = people.ID
+	filter["deleted"] = false
+	update := bson.M{
+		"$set":  bson.M{"status": req.Status, "updated_at": now},
+		"$push": bson.M{"status_history": change},
+	}
+
+	result, err := s.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, conflict(CodeEditConflict, "status was changed by someone else, please retry")
+	}
+
+	return s.GetPeopleByID(ctx, id)
+}
+
+// canonicalCatalogs checks age groups and roles against their catalogs and returns their catalog names
+func (s *PeopleService) canonicalCatalogs(ctx context.Context, ageGroup, roles []string) ([]string, []string, error) {
+	ageGroup, err := s.ageGroupService.CanonicalAgeGroups(ctx, ageGroup)
+	if err != nil {
+		return nil, nil, err
+	}
+	roles, err = s.roleService.CanonicalRoles(ctx, roles)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ageGroup, roles, nil
+}
+
+// assignAgeGroup sets the age group of a child with a date of birth from the age group catalog
+func (s *PeopleService) assignAgeGroup(ctx context.Context, people *models.People) error {
+	if people.Type != "children" || people.DateOfBirth == nil {
+		return nil
+	}
+
+	ageGroup, err := s.ageGroupService.AssignAgeGroup(ctx, *people.DateOfBirth)
+	if err != nil {
+		return err
+	}
+	people.AgeGroup = ageGroup
+	return nil
+}
+
+// statusFilter builds the filter selecting people by status for list endpoints
+func statusFilter(status string) (bson.M, error) {
+	status = strings.TrimSpace(status)
+	if status == AllStatuses {
+		return bson.M{}, nil
+	}
+	if status == "" {
+		return statusMatch([]string{models.PeopleStatusActive}), nil
+	}
+
+	var statuses []string
+	for _, value := range strings.Split(status, ",") {
+		value = strings.TrimSpace(value)
+		if _, ok := statusTransitions[value]; !ok {
+			return nil, invalid(CodeInvalidValue, "status must be one of prospect, active, inactive, graduated, alumni or all")
+		}
+		statuses = append(statuses, value)
+	}
+	return statusMatch(statuses), nil
+}
+
+// statusMatch matches people in any of the given statuses. People stored before
+// statuses existed have none and count as active.
+func statusMatch(statuses []string) bson.M {
+	values := bson.A{}
+	for _, status := range statuses {
+		values = append(values, status)
+		if status == models.PeopleStatusActive {
+			values = append(values, nil)
+		}
+	}
+	return bson.M{"status": bson.M{"$in": values}}
+}
+
+// RotateEncryption re-encrypts protected fields still stored as plaintext or under an
+// old key with the current key, and returns how many people were rewritten
+func (s *PeopleService) RotateEncryption(ctx context.Context) (int, error) {
+	if !s.keyring.Enabled() {
+		return 0, conflict(CodeNotConfigured, "encryption is not configured")
+	}
+
+	cursor, err := s.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	rotated := 0
+	for cursor.Next(ctx) {
+		var people models.People
+		if err := cursor.Decode(&people); err != nil {
+			return rotated, err
+		}
+		if !s.needsRotation(&people) {
+			continue
+		}
+
+		if err := s.decrypt(&people); err != nil {
+			return rotated, err
+		}
+		if err := s.protect(&people); err != nil {
+			return rotated, err
+		}
+
+		_, err := s.collection.UpdateOne(ctx, bson.M{"_id": people.ID}, bson.M{
+			"$set": bson.M{
+				"phone":       people.Phone,
+				"email":       people.Email,
+				"notes":       people.Notes,
+				"phone_index": people.PhoneIndex,
+			},
+		})
+		if err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+
+	return rotated, cursor.Err()
+}
+
+func (s *PeopleService) find(ctx context.Context, filter bson.M) ([]models.People, error) {
+	cursor, err := s.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var people []models.People
+	if err = cursor.All(ctx, &people); err != nil {
+		return nil, err
+	}
+
+	for i := range people {
+		if err := s.reveal(ctx, &people[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return people, nil
+}
+
+// isProtected reports whether a person's contact details and notes are encrypted at rest
+func isProtected(people *models.People) bool {
+	return people.Type == "children"
+}
+
+// protect encrypts the protected fields of a person before they are stored
+func (s *PeopleService) protect(people *models.People) error {
+	people.PhoneIndex = ""
+	if !isProtected(people) {
+		return nil
+	}
+
+	people.PhoneIndex = s.keyring.BlindIndex(normalizePhone(people.Phone))
+	for _, field := range []*string{&people.Phone, &people.Email, &people.Notes} {
+		encrypted, err := s.keyring.Encrypt(*field)
+		if err != nil {
+			return err
+		}
+		*field = encrypted
+	}
+	return nil
+}
+
+// decrypt decrypts the protected fields of a stored person
+func (s *PeopleService) decrypt(people *models.People) error {
+	for _, field := range []*string{&people.Phone, &people.Email, &people.Notes} {
+		decrypted, err := s.keyring.Decrypt(*field)
+		if err != nil {
+			return err
+		}
+		*field = decrypted
+	}
+	return nil
+}
+
+// reveal prepares a stored person for a response: it decrypts protected fields for
+// authorized callers and withholds them from everyone else
+func (s *PeopleService) reveal(ctx context.Context, people *models.People) error {
+	if people.Status == "" {
+		people.Status = models.PeopleStatusActive
+	}
+
+	if _, ok := AuthorizedCaller(ctx); isProtected(people) && !ok {
+		people.Redacted = people.Phone != "" || people.Email != "" || people.Notes != ""
+		people.Phone, people.Email, people.Notes = "", "", ""
+		return nil
+	}
+	return s.decrypt(people)
+}
+
+// needsRotation reports whether a stored person has protected fields to re-encrypt
+func (s *PeopleService) needsRotation(people *models.People) bool {
+	fields := []string{people.Phone, people.Email, people.Notes}
+	if !isProtected(people) {
+		for _, field := range fields {
+			if encryption.IsEncrypted(field) {
+				return true
+			}
+		}
+		return people.PhoneIndex != ""
+	}
+
+	for _, field := range fields {
+		if s.keyring.NeedsRotation(field) {
+			return true
+		}
+	}
+	return people.PhoneIndex == "" && people.Phone != ""
+}
+
+// normalizePhone strips formatting so the same number always has the same blind index
+func normalizePhone(phone string) string {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		if (r >= '0' && r <= '9') || (r == '+' && i == 0) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,603 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/sanitize"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ErasuresCollection = "erasures"
+
+// ErasedName replaces an erased person's name wherever it appears in free text
+const ErasedName = "[removed]"
+
+// reviewTextFields are the review fields searched for mentions of a person
+var reviewTextFields = []string{"what_went_well", "can_improve", "action_plans", "summary", "answers.text"}
+
+// PrivacyService answers subject-access requests and erases people on request
+type PrivacyService struct {
+	collection        *mongo.Collection
+	attendance        *mongo.Collection
+	followUps         *mongo.Collection
+	weeks             *mongo.Collection
+	reviews           *mongo.Collection
+	audit             *mongo.Collection
+	peopleService     *PeopleService
+	medicalService    *MedicalService
+	complianceService *ComplianceService
+	incidentService   *IncidentService
+	actionItemService *ActionItemService
+	commentService    *ReviewCommentService
+	auditService      *AuditService
+}
+
+func NewPrivacyService(peopleService *PeopleService, medicalService *MedicalService, complianceService *ComplianceService, incidentService *IncidentService, actionItemService *ActionItemService, commentService *ReviewCommentService, auditService *AuditService) *PrivacyService {
+	return &PrivacyService{
+		collection:        database.GetCollection(ErasuresCollection),
+		attendance:        database.GetCollection(AttendanceCollection),
+		followUps:         database.GetCollection(FollowUpsCollection),
+		weeks:             database.GetCollection(WeeksCollection),
+		reviews:           database.GetCollection(ReviewsCollection),
+		audit:             database.GetCollection(AuditCollection),
+		peopleService:     peopleService,
+		medicalService:    medicalService,
+		complianceService: complianceService,
+		incidentService:   incidentService,
+		actionItemService: actionItemService,
+		commentService:    commentService,
+		auditService:      auditService,
+	}
+}
+
+// ExportPerson gathers everything held about a person, including soft-deleted people,
+// for an authorized caller and audits the export
+func (s *PrivacyService) ExportPerson(ctx context.Context, personID string) (*models.SubjectAccessExport, error) {
+	caller, ok := AuthorizedCaller(ctx)
+	if !ok {
+		return nil, forbidden("caller is not authorized")
+	}
+
+	person, err := s.getPerson(ctx, personID)
+	if err != nil {
+		return nil, err
+	}
+	hexID := person.ID.Hex()
+
+	export := &models.SubjectAccessExport{
+		GeneratedAt:        time.Now(),
+		Person:             *person,
+		Attendance:         []models.Attendance{},
+		GuardianLinks:      []models.GuardianLink{},
+		FollowUps:          []models.FollowUp{},
+		Incidents:          []models.Incident{},
+		ActionItems:        []models.ActionItem{},
+		ReviewComments:     []*models.ReviewComment{},
+		ServiceAssignments: []models.ServiceAssignment{},
+		ReviewMentions:     []models.ReviewMention{},
+		AuditEntries:       []models.AuditEntry{},
+	}
+
+	if export.MedicalInfo, err = s.medicalService.GetMedicalRecord(ctx, person.ID); err != nil {
+		return nil, err
+	}
+	if export.ComplianceItems, err = s.complianceService.GetMinisterItems(ctx, person.ID); err != nil {
+		return nil, err
+	}
+
+	byCheckIn := options.Find().SetSort(bson.D{{Key: "checked_in_at", Value: 1}})
+	cursor, err := s.attendance.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"child_id": person.ID},
+		bson.M{"checked_in_by": hexID},
+	}}, byCheckIn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance: %v", err)
+	}
+	if err := cursor.All(ctx, &export.Attendance); err != nil {
+		return nil, fmt.Errorf("failed to decode attendance: %v", err)
+	}
+	for i := range export.Attendance {
+		export.Attendance[i].SecurityCode = ""
+	}
+	export.GuardianLinks = guardianLinks(person.ID, export.Attendance)
+
+	cursor, err = s.followUps.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"child_id": person.ID},
+		bson.M{"contacted_by": hexID},
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follow-ups: %v", err)
+	}
+	if err := cursor.All(ctx, &export.FollowUps); err != nil {
+		return nil, fmt.Errorf("failed to decode follow-ups: %v", err)
+	}
+
+	if export.Incidents, err = s.incidentService.GetIncidentRecords(ctx, person.ID); err != nil {
+		return nil, err
+	}
+	if export.ActionItems, err = s.actionItemService.GetMinisterActionItems(ctx, person.ID); err != nil {
+		return nil, err
+	}
+	if export.ReviewComments, err = s.commentService.GetMinisterComments(ctx, person.ID); err != nil {
+		return nil, err
+	}
+
+	byStart := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}})
+	cursor, err = s.weeks.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"services.sic": hexID},
+		bson.M{"services.ministers": hexID},
+	}}, byStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weeks: %v", err)
+	}
+	var weeks []models.Week
+	if err := cursor.All(ctx, &weeks); err != nil {
+		return nil, fmt.Errorf("failed to decode weeks: %v", err)
+	}
+	for _, week := range weeks {
+		for _, service := range week.Services {
+			if rosterContains(service, hexID) {
+				export.ServiceAssignments = append(export.ServiceAssignments, models.ServiceAssignment{
+					WeekID:      week.ID,
+					WeekStart:   week.StartTime,
+					ServiceName: service.Name,
+					ServiceTime: service.Time,
+				})
+			}
+		}
+	}
+
+	pattern := mentionPattern(person)
+	reviews, err := s.findMentioningReviews(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, review := range reviews {
+		for field, text := range reviewText(review) {
+			if pattern.MatchString(text) {
+				export.ReviewMentions = append(export.ReviewMentions, models.ReviewMention{
+					ReviewID: review.ID,
+					WeekID:   review.WeekID,
+					Field:    field,
+					Text:     sanitize.PlainText(text),
+				})
+			}
+		}
+	}
+	sort.Slice(export.ReviewMentions, func(i, j int) bool {
+		a, b := export.ReviewMentions[i], export.ReviewMentions[j]
+		if a.ReviewID != b.ReviewID {
+			return a.ReviewID.Hex() < b.ReviewID.Hex()
+		}
+		return a.Field < b.Field
+	})
+
+	byCreated := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err = s.audit.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"entity_id": person.ID},
+		bson.M{"actor_id": hexID},
+	}}, byCreated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %v", err)
+	}
+	if err := cursor.All(ctx, &export.AuditEntries); err != nil {
+		return nil, fmt.Errorf("failed to decode audit entries: %v", err)
+	}
+
+	if err := s.auditService.Record(ctx, AuditActionExport, AuditEntityPeople, person.ID, caller.ID.Hex(), ""); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// BuildExportArchive packs an export into a ZIP archive with one JSON file per section
+func BuildExportArchive(export *models.SubjectAccessExport) ([]byte, error) {
+	sections := []struct {
+		name string
+		data interface{}
+	}{
+		{"person.json", export.Person},
+		{"medical_info.json", export.MedicalInfo},
+		{"compliance_items.json", export.ComplianceItems},
+		{"attendance.json", export.Attendance},
+		{"guardian_links.json", export.GuardianLinks},
+		{"follow_ups.json", export.FollowUps},
+		{"incidents.json", export.Incidents},
+		{"action_items.json", export.ActionItems},
+		{"review_comments.json", export.ReviewComments},
+		{"service_assignments.json", export.ServiceAssignments},
+		{"review_mentions.json", export.ReviewMentions},
+		{"audit_entries.json", export.AuditEntries},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, section := range sections {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     section.name,
+			Method:   zip.Deflate,
+			Modified: export.GeneratedAt,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build export archive: %v", err)
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(section.data); err != nil {
+			return nil, fmt.Errorf("failed to build export archive: %v", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build export archive: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ErasePerson removes a person everywhere for an authorized caller and leaves a tombstone.
+// Attendance is kept under a pseudonymous child ID so reports still count it, the
+// person's name is removed from reviews, and audit entries are retained as they only
+// reference the person by ID.
+//
+// The tombstone is written first, marked pending, and completed once the person is gone
+// and the erasure audited, so an erasure that fails part way is recorded and erasing
+// the person again finishes it.
+func (s *PrivacyService) ErasePerson(ctx context.Context, personID string, req models.EraseRequest) (*models.ErasureTombstone, error) {
+	caller, ok := AuthorizedCaller(ctx)
+	if !ok {
+		return nil, forbidden("caller is not authorized")
+	}
+
+	objID, err := primitive.ObjectIDFromHex(personID)
+	if err != nil {
+		return nil, invalid(CodeInvalidID, "invalid ID format")
+	}
+
+	var tombstone models.ErasureTombstone
+	err = s.collection.FindOne(ctx, bson.M{"person_id": objID}).Decode(&tombstone)
+	switch {
+	case err == mongo.ErrNoDocuments:
+	case err != nil:
+		return nil, fmt.Errorf("failed to check erasures: %v", err)
+	case !tombstone.Pending:
+		return nil, NewError(ErrGone, CodeErased, "person has been erased")
+	}
+
+	person, err := s.peopleService.GetPeopleRecord(ctx, objID)
+	if err != nil {
+		if tombstone.Pending && errors.Is(err, ErrNotFound) {
+			// The person was removed before the last attempt failed; only the record of it is left
+			return s.completeErasure(ctx, &tombstone)
+		}
+		return nil, err
+	}
+
+	if !tombstone.Pending {
+		tombstone = models.ErasureTombstone{
+			ID:         primitive.NewObjectID(),
+			PersonID:   person.ID,
+			PersonType: person.Type,
+			Reason:     strings.TrimSpace(req.Reason),
+			ErasedBy:   caller.ID.Hex(),
+			Pending:    true,
+			ErasedAt:   time.Now(),
+		}
+		if _, err := s.collection.InsertOne(ctx, tombstone); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, conflict(CodeEditConflict, "person is being erased by someone else, please retry")
+			}
+			return nil, fmt.Errorf("failed to record erasure: %v", err)
+		}
+	}
+
+	hexID := person.ID.Hex()
+	now := time.Now()
+
+	// Every step is safe to repeat, so an erasure interrupted part way can be retried.
+	// Counts add to those of earlier attempts.
+	removed := tombstone.Removed
+	var count int64
+
+	result, err := s.attendance.UpdateMany(ctx, bson.M{"child_id": person.ID}, bson.M{
+		"$set":   bson.M{"child_id": primitive.NewObjectID(), "security_code": "", "updated_at": now},
+		"$unset": bson.M{"collected_by": ""},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize attendance: %v", err)
+	}
+	removed.Attendance += result.ModifiedCount
+
+	result, err = s.attendance.UpdateMany(ctx, bson.M{"checked_in_by": hexID}, bson.M{
+		"$set":   bson.M{"updated_at": now},
+		"$unset": bson.M{"checked_in_by": ""},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize attendance: %v", err)
+	}
+	removed.Attendance += result.ModifiedCount
+
+	deleted, err := s.followUps.DeleteMany(ctx, bson.M{"child_id": person.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete follow-ups: %v", err)
+	}
+	removed.FollowUps += deleted.DeletedCount
+
+	result, err = s.followUps.UpdateMany(ctx, bson.M{"contacted_by": hexID}, bson.M{
+		"$set":   bson.M{"updated_at": now},
+		"$unset": bson.M{"contacted_by": ""},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize follow-ups: %v", err)
+	}
+	removed.FollowUps += result.ModifiedCount
+
+	if count, err = s.medicalService.DeleteMedicalRecord(ctx, person.ID); err != nil {
+		return nil, err
+	}
+	removed.MedicalInfo += count
+	if count, err = s.complianceService.DeleteMinisterItems(ctx, person.ID); err != nil {
+		return nil, err
+	}
+	removed.ComplianceItems += count
+
+	unassign := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"service.sic": hexID}},
+	})
+	result, err = s.weeks.UpdateMany(ctx, bson.M{"services.sic": hexID}, bson.M{
+		"$set": bson.M{"services.$[service].sic": "", "updated_at": now},
+	}, unassign)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear service assignments: %v", err)
+	}
+	removed.ServiceAssignments += result.ModifiedCount
+
+	result, err = s.weeks.UpdateMany(ctx, bson.M{"services.ministers": hexID}, bson.M{
+		"$pull": bson.M{"services.$[].ministers": hexID},
+		"$set":  bson.M{"updated_at": now},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear service assignments: %v", err)
+	}
+	removed.ServiceAssignments += result.ModifiedCount
+
+	pattern := mentionPattern(person)
+	if count, err = s.incidentService.AnonymizeIncidents(ctx, person.ID, pattern); err != nil {
+		return nil, err
+	}
+	removed.Incidents += count
+	if count, err = s.actionItemService.AnonymizeActionItems(ctx, person.ID, pattern); err != nil {
+		return nil, err
+	}
+	removed.ActionItems += count
+	if count, err = s.commentService.AnonymizeComments(ctx, person.ID, pattern); err != nil {
+		return nil, err
+	}
+	removed.ReviewComments += count
+
+	reviews, err := s.findMentioningReviews(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, review := range reviews {
+		redacted := bson.M{"updated_at": now}
+		for field, text := range reviewText(review) {
+			if pattern.MatchString(text) && !strings.HasPrefix(field, "answers.") {
+				redacted[field] = pattern.ReplaceAllString(text, ErasedName)
+			}
+		}
+		for i, answer := range review.Answers {
+			if pattern.MatchString(answer.Text) {
+				review.Answers[i].Text = pattern.ReplaceAllString(answer.Text, ErasedName)
+				redacted["answers"] = review.Answers
+			}
+		}
+		if _, err := s.reviews.UpdateOne(ctx, bson.M{"_id": review.ID}, bson.M{"$set": redacted}); err != nil {
+			return nil, fmt.Errorf("failed to update review: %v", err)
+		}
+		removed.ReviewMentions++
+	}
+
+	if count, err = s.anonymizeReviews(ctx, hexID, now); err != nil {
+		return nil, err
+	}
+	removed.Reviews += count
+
+	// Keep the counts before the person goes, so a retry after that still reports them
+	tombstone.Removed = removed
+	if _, err := s.collection.UpdateOne(ctx, bson.M{"_id": tombstone.ID}, bson.M{"$set": bson.M{"removed": removed}}); err != nil {
+		return nil, fmt.Errorf("failed to record erasure: %v", err)
+	}
+
+	if err := s.peopleService.ErasePeople(ctx, person.ID); err != nil {
+		return nil, err
+	}
+
+	return s.completeErasure(ctx, &tombstone)
+}
+
+// completeErasure audits an erasure whose person has been removed and marks its
+// tombstone complete
+func (s *PrivacyService) completeErasure(ctx context.Context, tombstone *models.ErasureTombstone) (*models.ErasureTombstone, error) {
+	if err := s.auditService.Record(ctx, AuditActionErase, AuditEntityPeople, tombstone.PersonID, tombstone.ErasedBy, tombstone.Reason); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.collection.UpdateOne(ctx, bson.M{"_id": tombstone.ID}, bson.M{"$unset": bson.M{"pending": ""}}); err != nil {
+		return nil, fmt.Errorf("failed to record erasure: %v", err)
+	}
+	tombstone.Pending = false
+
+	return tombstone, nil
+}
+
+// anonymizeReviews removes a minister as the author, approver and maker of workflow
+// steps of reviews and returns how many reviews referenced them
+func (s *PrivacyService) anonymizeReviews(ctx context.Context, ministerID string, now time.Time) (int64, error) {
+	ids, err := s.reviews.Distinct(ctx, "_id", bson.M{"$or": bson.A{
+		bson.M{"author_id": ministerID},
+		bson.M{"approved_by": ministerID},
+		bson.M{"status_history.changed_by": ministerID},
+	}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get reviews: %v", err)
+	}
+
+	for _, field := range []string{"author_id", "approved_by"} {
+		if _, err := s.reviews.UpdateMany(ctx, bson.M{field: ministerID}, bson.M{
+			"$set":   bson.M{"updated_at": now},
+			"$unset": bson.M{field: ""},
+		}); err != nil {
+			return 0, fmt.Errorf("failed to anonymize reviews: %v", err)
+		}
+	}
+
+	steps := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"step.changed_by": ministerID}},
+	})
+	if _, err := s.reviews.UpdateMany(ctx, bson.M{"status_history.changed_by": ministerID}, bson.M{
+		"$set":   bson.M{"updated_at": now},
+		"$unset": bson.M{"status_history.$[step].changed_by": ""},
+	}, steps); err != nil {
+		return 0, fmt.Errorf("failed to anonymize reviews: %v", err)
+	}
+
+	return int64(len(ids)), nil
+}
+
+// GetErasures retrieves the tombstones of erased people, newest first
+func (s *PrivacyService) GetErasures(ctx context.Context) ([]models.ErasureTombstone, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "erased_at", Value: -1}})
+	cursor, err := s.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get erasures: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	tombstones := []models.ErasureTombstone{}
+	if err := cursor.All(ctx, &tombstones); err != nil {
+		return nil, fmt.Errorf("failed to decode erasures: %v", err)
+	}
+
+	return tombstones, nil
+}
+
+// getPerson returns the person with the given ID, reporting people who were already erased
+func (s *PrivacyService) getPerson(ctx context.Context, personID string) (*models.People, error) {
+	objID, err := primitive.ObjectIDFromHex(personID)
+	if err != nil {
+		return nil, invalid(CodeInvalidID, "invalid ID format")
+	}
+
+	count, err := s.collection.CountDocuments(ctx, bson.M{"person_id": objID, "pending": bson.M{"$ne": true}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check erasures: %v", err)
+	}
+	if count > 0 {
+		return nil, NewError(ErrGone, CodeErased, "person has been erased")
+	}
+
+	return s.peopleService.GetPeopleRecord(ctx, objID)
+}
+
+// findMentioningReviews returns every review, deleted or not, with text matching pattern
+func (s *PrivacyService) findMentioningReviews(ctx context.Context, pattern *regexp.Regexp) ([]models.Review, error) {
+	if pattern == nil {
+		return nil, nil
+	}
+
+	regex := mentionRegex(pattern)
+	matches := bson.A{}
+	for _, field := range reviewTextFields {
+		matches = append(matches, bson.M{field: regex})
+	}
+
+	cursor, err := s.reviews.Find(ctx, bson.M{"$or": matches})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %v", err)
+	}
+
+	var reviews []models.Review
+	if err := cursor.All(ctx, &reviews); err != nil {
+		return nil, fmt.Errorf("failed to decode reviews: %v", err)
+	}
+
+	return reviews, nil
+}
+
+// mentionPattern matches a person's name in free text: their full name, or the one name
+// they have as a whole word when the other is missing. It is nil when they have no name.
+func mentionPattern(person *models.People) *regexp.Regexp {
+	first := strings.TrimSpace(person.FirstName)
+	last := strings.TrimSpace(person.LastName)
+	switch {
+	case first != "" && last != "":
+		return regexp.MustCompile(`(?i)` + regexp.QuoteMeta(first) + `\s+` + regexp.QuoteMeta(last))
+	case first != "" || last != "":
+		return regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(first+last) + `\b`)
+	}
+	return nil
+}
+
+// mentionRegex returns a mention pattern as a MongoDB regex for queries. Go's QuoteMeta
+// output is also a valid literal in MongoDB's PCRE regexes.
+func mentionRegex(pattern *regexp.Regexp) primitive.Regex {
+	return primitive.Regex{Pattern: strings.TrimPrefix(pattern.String(), "(?i)"), Options: "i"}
+}
+
+// reviewText returns the free-text fields of a review keyed by their stored field name.
+// Answers to template questions other than the built-in ones are keyed "answers.<question ID>".
+func reviewText(review models.Review) map[string]string {
+	text := map[string]string{
+		"what_went_well": review.WhatWentWell,
+		"can_improve":    review.CanImprove,
+		"action_plans":   review.ActionPlans,
+		"summary":        review.Summary,
+	}
+	for _, answer := range review.Answers {
+		if answer.Text != "" && !isLegacyQuestion(answer.QuestionID) {
+			text["answers."+answer.QuestionID] = answer.Text
+		}
+	}
+	return text
+}
+
+// guardianLinks summarises who collected the child from their attendance records
+func guardianLinks(childID primitive.ObjectID, attendance []models.Attendance) []models.GuardianLink {
+	links := []models.GuardianLink{}
+	index := make(map[string]int)
+	for _, record := range attendance {
+		name := strings.TrimSpace(record.CollectedBy)
+		if record.ChildID != childID || name == "" || record.CheckedOutAt == nil {
+			continue
+		}
+
+		key := strings.ToLower(name)
+		i, ok := index[key]
+		if !ok {
+			i = len(links)
+			index[key] = i
+			links = append(links, models.GuardianLink{CollectedBy: name})
+		}
+		links[i].Times++
+		if record.CheckedOutAt.After(links[i].LastCollectedAt) {
+			links[i].LastCollectedAt = *record.CheckedOutAt
+		}
+	}
+	return links
+}
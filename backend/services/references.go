@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"eaglekidz-backend/models"
+)
+
+// ReferenceError reports a request field that refers to a record that does not exist,
+// or to one that cannot be linked there, such as a child given as a service's SIC
+type ReferenceError struct {
+	Field   string // Path of the field in the request, e.g. "week_id" or "services[0].sic"
+	Message string
+}
+
+func (e *ReferenceError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ReferencedWeek returns the week a request field refers to
+func (s *WeekService) ReferencedWeek(ctx context.Context, field, id string) (*models.Week, error) {
+	week, err := s.GetWeekByID(ctx, strings.TrimSpace(id))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalid) {
+			return nil, &ReferenceError{Field: field, Message: "week not found"}
+		}
+		return nil, err
+	}
+	return week, nil
+}
+
+// ReferencedMinister returns the minister a request field refers to
+func (s *PeopleService) ReferencedMinister(ctx context.Context, field, id string) (*models.People, error) {
+	return s.referencedPerson(ctx, field, id, "minister")
+}
+
+// ReferencedChild returns the child a request field refers to
+func (s *PeopleService) ReferencedChild(ctx context.Context, field, id string) (*models.People, error) {
+	return s.referencedPerson(ctx, field, id, "children")
+}
+
+func (s *PeopleService) referencedPerson(ctx context.Context, field, id, peopleType string) (*models.People, error) {
+	person, err := s.GetPeopleByID(ctx, strings.TrimSpace(id))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalid) {
+			return nil, &ReferenceError{Field: field, Message: "person not found"}
+		}
+		return nil, err
+	}
+	if person.Type != peopleType {
+		kind := "a minister"
+		if peopleType == "children" {
+			kind = "a child"
+		}
+		return nil, &ReferenceError{Field: field, Message: "must be " + kind}
+	}
+	return person, nil
+}
+
+// checkRosterReferences checks that the SIC and other ministers of every service are ministers
+func (s *PeopleService) checkRosterReferences(ctx context.Context, services []models.Service) error {
+	for i, service := range services {
+		if service.SIC != "" {
+			if _, err := s.ReferencedMinister(ctx, fmt.Sprintf("services[%d].sic", i), service.SIC); err != nil {
+				return err
+			}
+		}
+		for j, minister := range service.Ministers {
+			if _, err := s.ReferencedMinister(ctx, fmt.Sprintf("services[%d].ministers[%d]", i, j), minister); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// referencedService checks that a week has the service a request refers to
+func referencedService(week *models.Week, name, serviceTime string) error {
+	if !weekHasService(week, name, serviceTime) {
+		return &ReferenceError{Field: "service_name", Message: "service not found in week"}
+	}
+	return nil
+}
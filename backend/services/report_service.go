@@ -0,0 +1,552 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultRollingWindow is the number of weeks averaged when no window is requested
+const DefaultRollingWindow = 4
+
+// UnassignedAgeGroup is reported for children without an age group
+const UnassignedAgeGroup = "Unassigned"
+
+type ReportService struct {
+	attendance *mongo.Collection
+	reviews    *mongo.Collection
+	weeks      *mongo.Collection
+}
+
+func NewReportService() *ReportService {
+	return &ReportService{
+		attendance: database.GetCollection(AttendanceCollection),
+		reviews:    database.GetCollection(ReviewsCollection),
+		weeks:      database.GetCollection(WeeksCollection),
+	}
+}
+
+// GetWeeklyAttendance reports distinct children per week with first-timers, a rolling
+// average and the change from the previous week. Every week is reported, with 0 for
+// weeks without attendance.
+func (s *ReportService) GetWeeklyAttendance(ctx context.Context, filter models.AttendanceReportFilter) ([]models.WeeklyAttendanceReport, error) {
+	weeks, err := s.reportWeeks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := attendanceHistoryStages()
+	if match := dateRangeMatch("week.start_time", models.AttendanceReportFilter{To: filter.To}); match != nil {
+		pipeline = append(pipeline, match)
+	}
+	pipeline = append(pipeline,
+		bson.M{"$group": bson.M{
+			"_id":          "$week._id",
+			"children":     bson.M{"$addToSet": "$child_id"},
+			"first_timers": bson.M{"$addToSet": firstTimerExpr()},
+		}},
+		bson.M{"$project": bson.M{
+			"total":      bson.M{"$size": "$children"},
+			"first_time": bson.M{"$size": "$first_timers"},
+		}},
+	)
+
+	counts := []models.WeeklyAttendanceReport{}
+	if err := s.aggregate(ctx, pipeline, &counts); err != nil {
+		return nil, err
+	}
+	byWeek := make(map[primitive.ObjectID]models.WeeklyAttendanceReport, len(counts))
+	for _, count := range counts {
+		byWeek[count.WeekID] = count
+	}
+
+	reports := make([]models.WeeklyAttendanceReport, len(weeks))
+	totals := make([]int, len(weeks))
+	for i, week := range weeks {
+		count := byWeek[week.ID]
+		reports[i] = models.WeeklyAttendanceReport{
+			WeekID:    week.ID,
+			StartTime: week.StartTime,
+			EndTime:   week.EndTime,
+			Total:     count.Total,
+			FirstTime: count.FirstTime,
+		}
+		totals[i] = count.Total
+	}
+	for i, trend := range trends(totals, reportWindow(filter)) {
+		reports[i].AttendanceTrend = trend
+	}
+
+	// Trends are computed over the full history so the first weeks of the range
+	// still see the weeks before it
+	result := []models.WeeklyAttendanceReport{}
+	for _, report := range reports {
+		if inDateRange(report.StartTime, filter) {
+			result = append(result, report)
+		}
+	}
+	return result, nil
+}
+
+// GetServiceAttendance reports distinct children per service of each week, with trends
+// over the weeks each service was held. Services held without attendance are reported with 0.
+func (s *ReportService) GetServiceAttendance(ctx context.Context, filter models.AttendanceReportFilter) ([]models.ServiceAttendanceReport, error) {
+	weeks, err := s.reportWeeks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := attendanceHistoryStages()
+	if match := dateRangeMatch("week.start_time", models.AttendanceReportFilter{To: filter.To}); match != nil {
+		pipeline = append(pipeline, match)
+	}
+	pipeline = append(pipeline,
+		bson.M{"$group": bson.M{
+			"_id": bson.M{
+				"week_id":      "$week._id",
+				"service_name": "$service_name",
+				"service_time": "$service_time",
+			},
+			"children":     bson.M{"$addToSet": "$child_id"},
+			"first_timers": bson.M{"$addToSet": firstTimerExpr()},
+		}},
+		bson.M{"$project": bson.M{
+			"_id":          0,
+			"week_id":      "$_id.week_id",
+			"service_name": "$_id.service_name",
+			"service_time": "$_id.service_time",
+			"total":        bson.M{"$size": "$children"},
+			"first_time":   bson.M{"$size": "$first_timers"},
+		}},
+	)
+
+	counts := []models.ServiceAttendanceReport{}
+	if err := s.aggregate(ctx, pipeline, &counts); err != nil {
+		return nil, err
+	}
+	byWeek := make(map[primitive.ObjectID][]models.ServiceAttendanceReport)
+	for _, count := range counts {
+		byWeek[count.WeekID] = append(byWeek[count.WeekID], count)
+	}
+
+	reports := []models.ServiceAttendanceReport{}
+	for _, week := range weeks {
+		rows := byWeek[week.ID]
+		for _, service := range week.Services {
+			held := false
+			for _, row := range rows {
+				if row.ServiceName == service.Name && row.ServiceTime == service.Time {
+					held = true
+					break
+				}
+			}
+			if !held {
+				rows = append(rows, models.ServiceAttendanceReport{WeekID: week.ID, ServiceName: service.Name, ServiceTime: service.Time})
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			if rows[i].ServiceTime != rows[j].ServiceTime {
+				return rows[i].ServiceTime < rows[j].ServiceTime
+			}
+			return rows[i].ServiceName < rows[j].ServiceName
+		})
+		for _, row := range rows {
+			row.StartTime = week.StartTime
+			reports = append(reports, row)
+		}
+	}
+
+	keys := make([]string, len(reports))
+	totals := make([]int, len(reports))
+	for i, report := range reports {
+		keys[i] = report.ServiceName + "\x00" + report.ServiceTime
+		totals[i] = report.Total
+	}
+	for i, trend := range seriesTrends(keys, totals, reportWindow(filter)) {
+		reports[i].AttendanceTrend = trend
+	}
+
+	result := []models.ServiceAttendanceReport{}
+	for _, report := range reports {
+		if inDateRange(report.StartTime, filter) {
+			result = append(result, report)
+		}
+	}
+	return result, nil
+}
+
+// GetAgeGroupAttendance reports distinct children per age group of each week, with trends.
+// A child in several age groups is counted once in each of them. Once an age group has
+// been attended it is reported every week, with 0 for weeks nobody in it attended.
+func (s *ReportService) GetAgeGroupAttendance(ctx context.Context, filter models.AttendanceReportFilter) ([]models.AgeGroupAttendanceReport, error) {
+	weeks, err := s.reportWeeks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := attendanceHistoryStages()
+	if match := dateRangeMatch("week.start_time", models.AttendanceReportFilter{To: filter.To}); match != nil {
+		pipeline = append(pipeline, match)
+	}
+	pipeline = append(pipeline,
+		bson.M{"$lookup": bson.M{
+			"from":         PeopleCollection,
+			"localField":   "child_id",
+			"foreignField": "_id",
+			"as":           "child",
+		}},
+		bson.M{"$unwind": bson.M{"path": "$child", "preserveNullAndEmptyArrays": true}},
+		bson.M{"$addFields": bson.M{
+			"age_groups": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$child.age_group", bson.A{}}}}, 0}},
+				"$child.age_group",
+				bson.A{UnassignedAgeGroup},
+			}},
+		}},
+		bson.M{"$unwind": "$age_groups"},
+		bson.M{"$group": bson.M{
+			"_id": bson.M{
+				"week_id":   "$week._id",
+				"age_group": "$age_groups",
+			},
+			"children":     bson.M{"$addToSet": "$child_id"},
+			"first_timers": bson.M{"$addToSet": firstTimerExpr()},
+		}},
+		bson.M{"$project": bson.M{
+			"_id":        0,
+			"week_id":    "$_id.week_id",
+			"age_group":  "$_id.age_group",
+			"total":      bson.M{"$size": "$children"},
+			"first_time": bson.M{"$size": "$first_timers"},
+		}},
+	)
+
+	counts := []models.AgeGroupAttendanceReport{}
+	if err := s.aggregate(ctx, pipeline, &counts); err != nil {
+		return nil, err
+	}
+	byWeek := make(map[primitive.ObjectID]map[string]models.AgeGroupAttendanceReport)
+	for _, count := range counts {
+		if byWeek[count.WeekID] == nil {
+			byWeek[count.WeekID] = make(map[string]models.AgeGroupAttendanceReport)
+		}
+		byWeek[count.WeekID][count.AgeGroup] = count
+	}
+
+	reports := []models.AgeGroupAttendanceReport{}
+	attended := make(map[string]bool)
+	for _, week := range weeks {
+		for ageGroup := range byWeek[week.ID] {
+			attended[ageGroup] = true
+		}
+		ageGroups := make([]string, 0, len(attended))
+		for ageGroup := range attended {
+			ageGroups = append(ageGroups, ageGroup)
+		}
+		sort.Strings(ageGroups)
+
+		for _, ageGroup := range ageGroups {
+			row, ok := byWeek[week.ID][ageGroup]
+			if !ok {
+				row = models.AgeGroupAttendanceReport{WeekID: week.ID, AgeGroup: ageGroup}
+			}
+			row.StartTime = week.StartTime
+			reports = append(reports, row)
+		}
+	}
+
+	keys := make([]string, len(reports))
+	totals := make([]int, len(reports))
+	for i, report := range reports {
+		keys[i] = report.AgeGroup
+		totals[i] = report.Total
+	}
+	for i, trend := range seriesTrends(keys, totals, reportWindow(filter)) {
+		reports[i].AttendanceTrend = trend
+	}
+
+	result := []models.AgeGroupAttendanceReport{}
+	for _, report := range reports {
+		if inDateRange(report.StartTime, filter) {
+			result = append(result, report)
+		}
+	}
+	return result, nil
+}
+
+// GetWeeklyServiceRatings averages the review ratings of each service of each week, so a
+// service's scores can be followed over time
+func (s *ReportService) GetWeeklyServiceRatings(ctx context.Context, filter models.AttendanceReportFilter) ([]models.ServiceRatingReport, error) {
+	pipeline := reviewRatingStages(filter)
+	pipeline = append(pipeline,
+		bson.M{"$group": ratingGroup(bson.M{
+			"week_id":      "$week._id",
+			"service_name": "$service_name",
+			"service_time": "$service_time",
+		}, bson.M{"start_time": bson.M{"$first": "$week.start_time"}})},
+		bson.M{"$project": ratingProjection(bson.M{
+			"week_id":    "$_id.week_id",
+			"start_time": 1,
+		})},
+		bson.M{"$sort": bson.D{
+			{Key: "start_time", Value: 1},
+			{Key: "service_time", Value: 1},
+			{Key: "service_name", Value: 1},
+		}},
+	)
+	return s.aggregateRatings(ctx, pipeline)
+}
+
+// GetServiceRatings averages the review ratings of each service over the whole date range
+func (s *ReportService) GetServiceRatings(ctx context.Context, filter models.AttendanceReportFilter) ([]models.ServiceRatingReport, error) {
+	pipeline := reviewRatingStages(filter)
+	pipeline = append(pipeline,
+		bson.M{"$group": ratingGroup(bson.M{
+			"service_name": "$service_name",
+			"service_time": "$service_time",
+		}, bson.M{})},
+		bson.M{"$project": ratingProjection(bson.M{})},
+		bson.M{"$sort": bson.D{
+			{Key: "service_time", Value: 1},
+			{Key: "service_name", Value: 1},
+		}},
+	)
+	return s.aggregateRatings(ctx, pipeline)
+}
+
+func (s *ReportService) aggregateRatings(ctx context.Context, pipeline bson.A) ([]models.ServiceRatingReport, error) {
+	cursor, err := s.reviews.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate review ratings: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	reports := []models.ServiceRatingReport{}
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, fmt.Errorf("failed to decode review rating report: %v", err)
+	}
+
+	for i := range reports {
+		var sum float64
+		var rated int
+		for _, average := range []*float64{reports[i].Engagement, reports[i].Preparation, reports[i].Punctuality, reports[i].Safety} {
+			if average != nil {
+				sum += *average
+				rated++
+			}
+		}
+		if rated > 0 {
+			overall := sum / float64(rated)
+			reports[i].Overall = &overall
+		}
+	}
+
+	return reports, nil
+}
+
+// reviewRatingStages selects the rated reviews of weeks in the filter's date range, joined with their week
+func reviewRatingStages(filter models.AttendanceReportFilter) bson.A {
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"deleted": bson.M{"$ne": true}, "ratings": bson.M{"$exists": true}}},
+		bson.M{"$lookup": bson.M{
+			"from":         WeeksCollection,
+			"localField":   "week_id",
+			"foreignField": "_id",
+			"as":           "week",
+		}},
+		bson.M{"$unwind": "$week"},
+	}
+	if match := dateRangeMatch("week.start_time", filter); match != nil {
+		pipeline = append(pipeline, match)
+	}
+	return pipeline
+}
+
+// ratingGroup groups reviews by id, averaging each rating; $avg skips unrated areas
+func ratingGroup(id bson.M, fields bson.M) bson.M {
+	group := bson.M{
+		"_id":         id,
+		"reviews":     bson.M{"$sum": 1},
+		"engagement":  bson.M{"$avg": "$ratings.engagement"},
+		"preparation": bson.M{"$avg": "$ratings.preparation"},
+		"punctuality": bson.M{"$avg": "$ratings.punctuality"},
+		"safety":      bson.M{"$avg": "$ratings.safety"},
+	}
+	for key, value := range fields {
+		group[key] = value
+	}
+	return group
+}
+
+// ratingProjection flattens a rating group, keeping fields as well
+func ratingProjection(fields bson.M) bson.M {
+	projection := bson.M{
+		"_id":          0,
+		"service_name": bson.M{"$ifNull": bson.A{"$_id.service_name", ""}},
+		"service_time": bson.M{"$ifNull": bson.A{"$_id.service_time", ""}},
+		"reviews":      1,
+		"engagement":   1,
+		"preparation":  1,
+		"punctuality":  1,
+		"safety":       1,
+	}
+	for key, value := range fields {
+		projection[key] = value
+	}
+	return projection
+}
+
+// reportWeeks returns the weeks starting up to the end of the filter's range, oldest first.
+// Weeks before the range are included because trends look back over them.
+func (s *ReportService) reportWeeks(ctx context.Context, filter models.AttendanceReportFilter) ([]models.Week, error) {
+	query := bson.M{}
+	if filter.To != nil {
+		query["start_time"] = bson.M{"$lte": *filter.To}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "start_time", Value: 1}}).
+		SetProjection(bson.M{"start_time": 1, "end_time": 1, "services.name": 1, "services.time": 1})
+
+	cursor, err := s.weeks.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find weeks: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	weeks := []models.Week{}
+	if err := cursor.All(ctx, &weeks); err != nil {
+		return nil, fmt.Errorf("failed to decode weeks: %v", err)
+	}
+	return weeks, nil
+}
+
+// reportWindow returns the number of weeks in the filter's rolling average
+func reportWindow(filter models.AttendanceReportFilter) int {
+	if filter.Window <= 0 {
+		return DefaultRollingWindow
+	}
+	return filter.Window
+}
+
+// trends computes the trend of each total of a series of consecutive weeks: the average
+// of the last window totals and the change from the total before it
+func trends(totals []int, window int) []models.AttendanceTrend {
+	result := make([]models.AttendanceTrend, len(totals))
+	sum := 0
+	for i, total := range totals {
+		sum += total
+		weeks := i + 1
+		if i >= window {
+			sum -= totals[i-window]
+			weeks = window
+		}
+		result[i].RollingAverage = float64(sum) / float64(weeks)
+
+		if i == 0 {
+			continue
+		}
+		previous := totals[i-1]
+		change := total - previous
+		result[i].PreviousTotal = &previous
+		result[i].Change = &change
+		if previous > 0 {
+			percent := float64(change) / float64(previous) * 100
+			result[i].ChangePercent = &percent
+		}
+	}
+	return result
+}
+
+// seriesTrends computes the trends of rows belonging to several series, named by keys.
+// Rows of a series must be in week order.
+func seriesTrends(keys []string, totals []int, window int) []models.AttendanceTrend {
+	series := make(map[string][]int)
+	for i, key := range keys {
+		series[key] = append(series[key], i)
+	}
+
+	result := make([]models.AttendanceTrend, len(keys))
+	for _, rows := range series {
+		seriesTotals := make([]int, len(rows))
+		for i, row := range rows {
+			seriesTotals[i] = totals[row]
+		}
+		for i, trend := range trends(seriesTotals, window) {
+			result[rows[i]] = trend
+		}
+	}
+	return result
+}
+
+func (s *ReportService) aggregate(ctx context.Context, pipeline bson.A, results interface{}) error {
+	cursor, err := s.attendance.Aggregate(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate attendance: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, results); err != nil {
+		return fmt.Errorf("failed to decode attendance report: %v", err)
+	}
+
+	return nil
+}
+
+// attendanceHistoryStages joins each attendance record with its week and marks
+// whether it falls in the child's first week ever attended
+func attendanceHistoryStages() bson.A {
+	return bson.A{
+		bson.M{"$lookup": bson.M{
+			"from":         WeeksCollection,
+			"localField":   "week_id",
+			"foreignField": "_id",
+			"as":           "week",
+		}},
+		bson.M{"$unwind": "$week"},
+		bson.M{"$setWindowFields": bson.M{
+			"partitionBy": "$child_id",
+			"output": bson.M{
+				"first_seen": bson.M{"$min": "$week.start_time"},
+			},
+		}},
+		bson.M{"$addFields": bson.M{
+			"is_first_time": bson.M{"$eq": bson.A{"$week.start_time", "$first_seen"}},
+		}},
+	}
+}
+
+// firstTimerExpr yields the child ID for first-time attendance and nothing otherwise
+func firstTimerExpr() bson.M {
+	return bson.M{"$cond": bson.A{"$is_first_time", "$child_id", "$$REMOVE"}}
+}
+
+// inDateRange reports whether t falls in the filter's date range
+func inDateRange(t time.Time, filter models.AttendanceReportFilter) bool {
+	return (filter.From == nil || !t.Before(*filter.From)) && (filter.To == nil || !t.After(*filter.To))
+}
+
+// dateRangeMatch builds a $match stage on field for the filter's date range, or nil when unbounded
+func dateRangeMatch(field string, filter models.AttendanceReportFilter) bson.M {
+	if filter.From == nil && filter.To == nil {
+		return nil
+	}
+
+	rangeFilter := bson.M{}
+	if filter.From != nil {
+		rangeFilter["$gte"] = *filter.From
+	}
+	if filter.To != nil {
+		rangeFilter["$lte"] = *filter.To
+	}
+
+	return bson.M{"$match": bson.M{field: rangeFilter}}
+}
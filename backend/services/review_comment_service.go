@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ReviewCommentsCollection = "review_comments"
+
+// mentionToken finds @-mentions: "@<minister ID>", "@First.Last" or "@First". Mentions
+// must start a word so e-mail addresses are left alone.
+var mentionToken = regexp.MustCompile(`(^|[^\p{L}\p{N}_@.])@([\p{L}\p{N}][\p{L}\p{N}._'-]*)`)
+
+// ReviewCommentService keeps the discussion threads on reviews
+type ReviewCommentService struct {
+	collection    *mongo.Collection
+	reviewService *ReviewService
+	peopleService *PeopleService
+}
+
+func NewReviewCommentService(reviewService *ReviewService, peopleService *PeopleService) *ReviewCommentService {
+	return &ReviewCommentService{
+		collection:    database.GetCollection(ReviewCommentsCollection),
+		reviewService: reviewService,
+		peopleService: peopleService,
+	}
+}
+
+// CreateComment adds a comment to a review, or a reply when a parent comment is given
+func (s *ReviewCommentService) CreateComment(ctx context.Context, reviewID string, req models.CreateReviewCommentRequest) (*models.ReviewComment, error) {
+	review, err := s.reviewService.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.Deleted {
+		return nil, notFound("review not found")
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, invalid(CodeMissingField, "comment text is required")
+	}
+	author, err := s.peopleService.ReferencedMinister(ctx, "author_id", req.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.ReviewComment{
+		ID:       primitive.NewObjectID(),
+		ReviewID: review.ID,
+		AuthorID: author.ID.Hex(),
+		Text:     text,
+	}
+	if req.ParentID != "" {
+		parent, err := s.GetComment(ctx, req.ParentID)
+		if err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrInvalid) {
+			return nil, err
+		}
+		if err != nil || parent.ReviewID != review.ID || parent.Deleted {
+			return nil, &ReferenceError{Field: "parent_id", Message: "comment not found in review"}
+		}
+		comment.ParentID = &parent.ID
+	}
+	if comment.Mentions, err = s.resolveMentions(ctx, text); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+	if _, err := s.collection.InsertOne(ctx, comment); err != nil {
+		return nil, fmt.Errorf("failed to create comment: %v", err)
+	}
+	return comment, nil
+}
+
+// GetComment retrieves a comment by its ID, including deleted comments
+func (s *ReviewCommentService) GetComment(ctx context.Context, id string) (*models.ReviewComment, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, invalidID("comment", err)
+	}
+
+	var comment models.ReviewComment
+	if err := s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&comment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("comment not found")
+		}
+		return nil, fmt.Errorf("failed to get comment: %v", err)
+	}
+	return &comment, nil
+}
+
+// GetReviewComments lists a review's comments as threads, oldest first. A deleted
+// comment that still has replies stays in its thread with its text removed.
+func (s *ReviewCommentService) GetReviewComments(ctx context.Context, reviewID string) ([]*models.ReviewComment, error) {
+	review, err := s.reviewService.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.find(ctx, bson.M{"review_id": review.ID})
+	if err != nil {
+		return nil, err
+	}
+	return commentThreads(comments), nil
+}
+
+// GetDeletedComments lists a review's deleted comments, oldest first
+func (s *ReviewCommentService) GetDeletedComments(ctx context.Context, reviewID string) ([]*models.ReviewComment, error) {
+	review, err := s.reviewService.GetReviewByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	return s.find(ctx, bson.M{"review_id": review.ID, "deleted": true})
+}
+
+// UpdateComment changes the text of a comment. Only its author, identified by callerID, may edit it.
+func (s *ReviewCommentService) UpdateComment(ctx context.Context, id string, req models.UpdateReviewCommentRequest, callerID string) (*models.ReviewComment, error) {
+	comment, err := s.authorComment(ctx, id, callerID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Deleted {
+		return nil, notFound("comment not found")
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, invalid(CodeMissingField, "comment text is required")
+	}
+	mentions, err := s.resolveMentions(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": comment.ID}, bson.M{"$set": bson.M{
+		"text":       text,
+		"mentions":   mentions,
+		"edited_at":  now,
+		"updated_at": now,
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment: %v", err)
+	}
+	return s.GetComment(ctx, id)
+}
+
+// DeleteComment soft deletes a comment. Only its author, identified by callerID, may delete it.
+func (s *ReviewCommentService) DeleteComment(ctx context.Context, id string, callerID string) error {
+	comment, err := s.authorComment(ctx, id, callerID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	result, err := s.collection.UpdateOne(ctx, bson.M{"_id": comment.ID, "deleted": false}, bson.M{"$set": bson.M{
+		"deleted":    true,
+		"deleted_at": now,
+		"updated_at": now,
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %v", err)
+	}
+	if result.ModifiedCount == 0 {
+		return notFound("comment not found")
+	}
+	return nil
+}
+
+// RestoreComment restores a soft-deleted comment. Only its author, identified by callerID, may restore it.
+func (s *ReviewCommentService) RestoreComment(ctx context.Context, id string, callerID string) (*models.ReviewComment, error) {
+	comment, err := s.authorComment(ctx, id, callerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.collection.UpdateOne(ctx, bson.M{"_id": comment.ID, "deleted": true}, bson.M{
+		"$set":   bson.M{"deleted": false, "updated_at": time.Now()},
+		"$unset": bson.M{"deleted_at": ""},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore comment: %v", err)
+	}
+	if result.ModifiedCount == 0 {
+		return nil, notFound("deleted comment not found")
+	}
+	return s.GetComment(ctx, id)
+}
+
+// GetMinisterComments lists the comments a minister wrote or was mentioned in, for
+// subject-access exports
+func (s *ReviewCommentService) GetMinisterComments(ctx context.Context, ministerID primitive.ObjectID) ([]*models.ReviewComment, error) {
+	return s.find(ctx, commentsOf(ministerID))
+}
+
+// AnonymizeComments removes a person as author and mention of review comments and
+// replaces their name in the text with ErasedName. It returns how many comments were changed.
+func (s *ReviewCommentService) AnonymizeComments(ctx context.Context, ministerID primitive.ObjectID, pattern *regexp.Regexp) (int64, error) {
+	hexID := ministerID.Hex()
+	query := commentsOf(ministerID)
+	if pattern != nil {
+		regex := mentionRegex(pattern)
+		query = bson.M{"$or": bson.A{query, bson.M{"text": regex}}}
+	}
+	comments, err := s.find(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	var changed int64
+	for _, comment := range comments {
+		if comment.AuthorID == hexID {
+			comment.AuthorID = ""
+		}
+		mentions := []string{}
+		for _, mention := range comment.Mentions {
+			if mention != hexID {
+				mentions = append(mentions, mention)
+			}
+		}
+		text := strings.ReplaceAll(comment.Text, "@"+hexID, "@"+ErasedName)
+		if pattern != nil {
+			text = pattern.ReplaceAllString(text, ErasedName)
+		}
+		_, err := s.collection.UpdateOne(ctx, bson.M{"_id": comment.ID}, bson.M{"$set": bson.M{
+			"author_id":  comment.AuthorID,
+			"mentions":   mentions,
+			"text":       text,
+			"updated_at": time.Now(),
+		}})
+		if err != nil {
+			return changed, fmt.Errorf("failed to anonymize comment: %v", err)
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// authorComment retrieves a comment for a change only its author may make
+func (s *ReviewCommentService) authorComment(ctx context.Context, id, callerID string) (*models.ReviewComment, error) {
+	if callerID == "" {
+		return nil, NewError(ErrUnauthenticated, CodeUnauthenticated, "caller not identified")
+	}
+	comment, err := s.GetComment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.AuthorID != callerID {
+		return nil, forbidden("only the author can change a comment")
+	}
+	return comment, nil
+}
+
+// resolveMentions returns the IDs of the ministers @-mentioned in text. A mention is a
+// minister ID, a full name with the parts joined by ".", "_" or "-" or nothing, or a
+// first name that only one minister has.
+func (s *ReviewCommentService) resolveMentions(ctx context.Context, text string) ([]string, error) {
+	mentions := []string{}
+	matches := mentionToken.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return mentions, nil
+	}
+
+	ministers, err := s.peopleService.GetPeopleByType(ctx, "minister", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ministers: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		token := strings.TrimRight(match[2], ".'-_")
+		id, err := mentionedMinister(token, ministers)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			mentions = append(mentions, id)
+		}
+	}
+	return mentions, nil
+}
+
+func (s *ReviewCommentService) find(ctx context.Context, filter bson.M) ([]*models.ReviewComment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %v", err)
+	}
+
+	comments := []*models.ReviewComment{}
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, fmt.Errorf("failed to decode comments: %v", err)
+	}
+	return comments, nil
+}
+
+// mentionedMinister resolves one @-mention token to a minister ID
+func mentionedMinister(token string, ministers []models.People) (string, error) {
+	if _, err := primitive.ObjectIDFromHex(token); err == nil {
+		for _, minister := range ministers {
+			if minister.ID.Hex() == token {
+				return token, nil
+			}
+		}
+		return "", &ReferenceError{Field: "text", Message: "no minister matches @" + token}
+	}
+
+	name := mentionName(token)
+	var byFullName, byFirstName []string
+	for _, minister := range ministers {
+		first := mentionName(minister.FirstName)
+		if first+mentionName(minister.LastName) == name {
+			byFullName = append(byFullName, minister.ID.Hex())
+		}
+		if first == name {
+			byFirstName = append(byFirstName, minister.ID.Hex())
+		}
+	}
+
+	for _, candidates := range [][]string{byFullName, byFirstName} {
+		switch len(candidates) {
+		case 0:
+			continue
+		case 1:
+			return candidates[0], nil
+		default:
+			return "", &ReferenceError{Field: "text", Message: "@" + token + " matches several ministers, mention them by full name or ID"}
+		}
+	}
+	return "", &ReferenceError{Field: "text", Message: "no minister matches @" + token}
+}
+
+// mentionName folds a name or mention for comparison, dropping case and separators
+func mentionName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '_', '-', '\'':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// commentThreads nests replies under the comments they answer. Deleted comments are
+// dropped unless they have replies, in which case they keep their place without text.
+func commentThreads(comments []*models.ReviewComment) []*models.ReviewComment {
+	byID := make(map[primitive.ObjectID]*models.ReviewComment, len(comments))
+	for _, comment := range comments {
+		byID[comment.ID] = comment
+	}
+
+	threads := []*models.ReviewComment{}
+	for _, comment := range comments {
+		var parent *models.ReviewComment
+		if comment.ParentID != nil {
+			parent = byID[*comment.ParentID]
+		}
+		if parent != nil {
+			parent.Replies = append(parent.Replies, comment)
+		} else {
+			threads = append(threads, comment)
+		}
+	}
+	if threads = pruneDeleted(threads); threads == nil {
+		return []*models.ReviewComment{}
+	}
+	return threads
+}
+
+// pruneDeleted drops deleted comments without live replies and blanks the rest
+func pruneDeleted(comments []*models.ReviewComment) []*models.ReviewComment {
+	kept := []*models.ReviewComment{}
+	for _, comment := range comments {
+		comment.Replies = pruneDeleted(comment.Replies)
+		if comment.Deleted {
+			if len(comment.Replies) == 0 {
+				continue
+			}
+			comment.Text = ""
+			comment.Mentions = []string{}
+		}
+		kept = append(kept, comment)
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+// commentsOf matches the comments a minister wrote or was mentioned in
+func commentsOf(ministerID primitive.ObjectID) bson.M {
+	hexID := ministerID.Hex()
+	return bson.M{"$or": bson.A{
+		bson.M{"author_id": hexID},
+		bson.M{"mentions": hexID},
+	}}
+}
@@ -2,11 +2,14 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"eaglekidz-backend/database"
 	"eaglekidz-backend/models"
+	"eaglekidz-backend/sanitize"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,34 +18,94 @@ import (
 
 const ReviewsCollection = "reviews"
 
+// Review ratings range from MinReviewRating to MaxReviewRating
+const (
+	MinReviewRating = 1
+	MaxReviewRating = 5
+)
+
+// DefaultReviewApproverRoles are the minister roles allowed to approve reviews when none are configured
+var DefaultReviewApproverRoles = []string{"Leader"}
+
+// reviewTransitions lists the statuses each review status may move to
+var reviewTransitions = map[string][]string{
+	models.ReviewStatusDraft:            {models.ReviewStatusSubmitted},
+	models.ReviewStatusSubmitted:        {models.ReviewStatusApproved, models.ReviewStatusChangesRequested, models.ReviewStatusDraft},
+	models.ReviewStatusChangesRequested: {models.ReviewStatusSubmitted},
+	models.ReviewStatusApproved:         {models.ReviewStatusDraft},
+}
+
+// editableReviewStatuses are the statuses in which a review's content may change
+var editableReviewStatuses = []string{models.ReviewStatusDraft, models.ReviewStatusChangesRequested}
+
 type ReviewService struct {
-	collection *mongo.Collection
+	collection      *mongo.Collection
+	weekService     *WeekService
+	templateService *ReviewTemplateService
+	approvers       *AccessService
 }
 
-func NewReviewService() *ReviewService {
+// NewReviewService creates a review service. Approving reviews, requesting changes and
+// reopening approved reviews is limited to the ministers approvers authorizes.
+func NewReviewService(weekService *WeekService, templateService *ReviewTemplateService, approvers *AccessService) *ReviewService {
 	return &ReviewService{
-		collection: database.GetCollection(ReviewsCollection),
+		collection:      database.GetCollection(ReviewsCollection),
+		weekService:     weekService,
+		templateService: templateService,
+		approvers:       approvers,
 	}
 }
 
-// CreateReview creates a new review
-func (s *ReviewService) CreateReview(ctx context.Context, req models.CreateReviewRequest) (*models.Review, error) {
-	weekObjID, err := primitive.ObjectIDFromHex(req.WeekID)
+// CreateReview creates a new review of an existing week as a draft, written by the
+// minister identified by callerID
+func (s *ReviewService) CreateReview(ctx context.Context, req models.CreateReviewRequest, callerID string) (*models.Review, error) {
+	week, err := s.weekService.ReferencedWeek(ctx, "week_id", req.WeekID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid week ID: %v", err)
+		return nil, err
+	}
+	weekObjID := week.ID
+	if err := s.checkService(ctx, weekObjID, req.ServiceName, req.ServiceTime); err != nil {
+		return nil, err
+	}
+	if err := validateRatings(req.Ratings); err != nil {
+		return nil, err
 	}
 
-	review := &models.Review{
-		ID:           primitive.NewObjectID(),
-		WeekID:       weekObjID,
-		WhatWentWell: req.WhatWentWell,
-		CanImprove:   req.CanImprove,
-		ActionPlans:  req.ActionPlans,
-		Summary:      req.Summary,
-		Deleted:      false,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+	template, err := s.templateFor(ctx, req.TemplateID)
+	if err != nil {
+		return nil, err
 	}
+	version, err := s.templateService.GetTemplateVersion(ctx, template.ID, template.Version)
+	if err != nil {
+		return nil, err
+	}
+	answers := withLegacyAnswers(req.Answers, version.Questions, map[string]*string{
+		QuestionWhatWentWell: &req.WhatWentWell,
+		QuestionCanImprove:   &req.CanImprove,
+		QuestionActionPlans:  &req.ActionPlans,
+	})
+	if answers, err = validateAnswers(version.Questions, answers); err != nil {
+		return nil, err
+	}
+
+	review := &models.Review{
+		ID:              primitive.NewObjectID(),
+		WeekID:          weekObjID,
+		ServiceName:     req.ServiceName,
+		ServiceTime:     req.ServiceTime,
+		TemplateID:      template.ID,
+		TemplateVersion: template.Version,
+		Answers:         answers,
+		Summary:         sanitize.HTML(req.Summary),
+		Ratings:         req.Ratings,
+		Status:          models.ReviewStatusDraft,
+		AuthorID:        callerID,
+		Deleted:         false,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	review.StatusHistory = []models.ReviewStatusChange{{To: review.Status, ChangedBy: callerID, ChangedAt: review.CreatedAt}}
+	mirrorLegacyFields(review)
 
 	_, err = s.collection.InsertOne(ctx, review)
 	if err != nil {
@@ -56,29 +119,52 @@ func (s *ReviewService) CreateReview(ctx context.Context, req models.CreateRevie
 func (s *ReviewService) GetReviewByID(ctx context.Context, id string) (*models.Review, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid review ID: %v", err)
+		return nil, invalidID("review", err)
 	}
 
 	var review models.Review
 	err = s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&review)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("review not found")
+			return nil, notFound("review not found")
 		}
 		return nil, fmt.Errorf("failed to get review: %v", err)
 	}
+	withDefaultStatus(&review)
 
 	return &review, nil
 }
 
-// GetReviewsByWeekID retrieves all reviews for a specific week
-func (s *ReviewService) GetReviewsByWeekID(ctx context.Context, weekID string) ([]*models.Review, error) {
+// GetVisibleReview retrieves a review the minister identified by callerID may see. Reviews
+// that are not approved are only shown to their author and to approvers.
+func (s *ReviewService) GetVisibleReview(ctx context.Context, id, callerID string) (*models.Review, error) {
+	review, err := s.GetReviewByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != models.ReviewStatusApproved && (callerID == "" || review.AuthorID != callerID) && !s.isApprover(ctx, callerID) {
+		return nil, notFound("review not found")
+	}
+	return review, nil
+}
+
+// GetReviewsByWeekID retrieves the reviews of a specific week the minister identified by
+// callerID may see. An empty status selects approved reviews and AllStatuses selects
+// every status; several statuses may be given separated by commas. Reviews that are not
+// approved are only listed for their author and for approvers.
+func (s *ReviewService) GetReviewsByWeekID(ctx context.Context, weekID, status, callerID string) ([]*models.Review, error) {
 	weekObjID, err := primitive.ObjectIDFromHex(weekID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid week ID: %v", err)
+		return nil, invalidID("week", err)
+	}
+	filter, err := s.visibleReviewFilter(ctx, status, callerID)
+	if err != nil {
+		return nil, err
 	}
+	filter["week_id"] = weekObjID
+	filter["deleted"] = bson.M{"$ne": true}
 
-	cursor, err := s.collection.Find(ctx, bson.M{"week_id": weekObjID, "deleted": bson.M{"$ne": true}})
+	cursor, err := s.collection.Find(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reviews: %v", err)
 	}
@@ -90,6 +176,7 @@ func (s *ReviewService) GetReviewsByWeekID(ctx context.Context, weekID string) (
 		if err := cursor.Decode(&review); err != nil {
 			return nil, fmt.Errorf("failed to decode review: %v", err)
 		}
+		withDefaultStatus(&review)
 		reviews = append(reviews, &review)
 	}
 
@@ -100,9 +187,15 @@ func (s *ReviewService) GetReviewsByWeekID(ctx context.Context, weekID string) (
 	return reviews, nil
 }
 
-// GetAllReviews retrieves all reviews
-func (s *ReviewService) GetAllReviews(ctx context.Context) ([]*models.Review, error) {
-	cursor, err := s.collection.Find(ctx, bson.M{"deleted": bson.M{"$ne": true}})
+// GetAllReviews retrieves all reviews the caller may see, filtered by status like GetReviewsByWeekID
+func (s *ReviewService) GetAllReviews(ctx context.Context, status, callerID string) ([]*models.Review, error) {
+	filter, err := s.visibleReviewFilter(ctx, status, callerID)
+	if err != nil {
+		return nil, err
+	}
+	filter["deleted"] = bson.M{"$ne": true}
+
+	cursor, err := s.collection.Find(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reviews: %v", err)
 	}
@@ -114,6 +207,7 @@ func (s *ReviewService) GetAllReviews(ctx context.Context) ([]*models.Review, er
 		if err := cursor.Decode(&review); err != nil {
 			return nil, fmt.Errorf("failed to decode review: %v", err)
 		}
+		withDefaultStatus(&review)
 		reviews = append(reviews, &review)
 	}
 
@@ -124,11 +218,19 @@ func (s *ReviewService) GetAllReviews(ctx context.Context) ([]*models.Review, er
 	return reviews, nil
 }
 
-// UpdateReview updates a review by its ID
+// UpdateReview updates a review by its ID. Only drafts and reviews sent back for
+// changes can be edited.
 func (s *ReviewService) UpdateReview(ctx context.Context, id string, req models.UpdateReviewRequest) (*models.Review, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid review ID: %v", err)
+		return nil, invalidID("review", err)
+	}
+	current, err := s.GetReviewByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isEditable(current) {
+		return nil, conflict(CodeNotEditable, "review is %s and can only be edited as a draft or when changes are requested", current.Status)
 	}
 
 	update := bson.M{
@@ -137,32 +239,83 @@ func (s *ReviewService) UpdateReview(ctx context.Context, id string, req models.
 		},
 	}
 
-	if req.WhatWentWell != nil {
-		update["$set"].(bson.M)["what_went_well"] = *req.WhatWentWell
+	if req.ServiceName != nil || req.ServiceTime != nil {
+		review := current
+		name, serviceTime := review.ServiceName, review.ServiceTime
+		if req.ServiceName != nil {
+			name = *req.ServiceName
+		}
+		if req.ServiceTime != nil {
+			serviceTime = *req.ServiceTime
+		}
+		if err := s.checkService(ctx, review.WeekID, name, serviceTime); err != nil {
+			return nil, err
+		}
+		update["$set"].(bson.M)["service_name"] = name
+		update["$set"].(bson.M)["service_time"] = serviceTime
 	}
-	if req.CanImprove != nil {
-		update["$set"].(bson.M)["can_improve"] = *req.CanImprove
+	if req.Ratings != nil {
+		if err := validateRatings(req.Ratings); err != nil {
+			return nil, err
+		}
+		update["$set"].(bson.M)["ratings"] = req.Ratings
 	}
-	if req.ActionPlans != nil {
-		update["$set"].(bson.M)["action_plans"] = *req.ActionPlans
+	if req.Answers != nil || req.WhatWentWell != nil || req.CanImprove != nil || req.ActionPlans != nil {
+		review := current
+
+		// Answers are checked against the template version the review was written with
+		questions, err := s.questionsOf(ctx, review)
+		if err != nil {
+			return nil, err
+		}
+		answers := review.Answers
+		if req.Answers != nil {
+			answers = req.Answers
+		}
+		answers = withLegacyAnswers(answers, questions, map[string]*string{
+			QuestionWhatWentWell: req.WhatWentWell,
+			QuestionCanImprove:   req.CanImprove,
+			QuestionActionPlans:  req.ActionPlans,
+		})
+		if review.Answers, err = validateAnswers(questions, answers); err != nil {
+			return nil, err
+		}
+		mirrorLegacyFields(review)
+
+		update["$set"].(bson.M)["answers"] = review.Answers
+		update["$set"].(bson.M)["what_went_well"] = review.WhatWentWell
+		update["$set"].(bson.M)["can_improve"] = review.CanImprove
+		update["$set"].(bson.M)["action_plans"] = review.ActionPlans
 	}
 	if req.Summary != nil {
-		update["$set"].(bson.M)["summary"] = *req.Summary
+		update["$set"].(bson.M)["summary"] = sanitize.HTML(*req.Summary)
 	}
 
-	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	// Matching on an editable status keeps an edit from landing on a review submitted meanwhile
+	result, err := s.collection.UpdateOne(ctx, bson.M{"_id": objID, "status": bson.M{"$in": editableReviewStatuses}}, update)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update review: %v", err)
 	}
+	if result.MatchedCount == 0 {
+		return nil, conflict(CodeEditConflict, "review status was changed by someone else, please retry")
+	}
 
 	return s.GetReviewByID(ctx, id)
 }
 
-// DeleteReview soft deletes a review by its ID
+// DeleteReview soft deletes a review by its ID. Submitted and approved reviews have to
+// go back to draft first.
 func (s *ReviewService) DeleteReview(ctx context.Context, id string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return fmt.Errorf("invalid review ID: %v", err)
+		return invalidID("review", err)
+	}
+	review, err := s.GetReviewByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !isEditable(review) {
+		return conflict(CodeNotEditable, "review is %s and can only be deleted as a draft or when changes are requested", review.Status)
 	}
 
 	update := bson.M{
@@ -172,26 +325,33 @@ func (s *ReviewService) DeleteReview(ctx context.Context, id string) error {
 		},
 	}
 
-	result, err := s.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	result, err := s.collection.UpdateOne(ctx, bson.M{"_id": objID, "status": bson.M{"$in": editableReviewStatuses}}, update)
 	if err != nil {
 		return fmt.Errorf("failed to delete review: %v", err)
 	}
 
-	if result.ModifiedCount == 0 {
-		return fmt.Errorf("review not found")
+	if result.MatchedCount == 0 {
+		return conflict(CodeEditConflict, "review status was changed by someone else, please retry")
 	}
 
 	return nil
 }
 
-// GetDeletedReviewsByWeekID retrieves all deleted reviews for a specific week
-func (s *ReviewService) GetDeletedReviewsByWeekID(ctx context.Context, weekID string) ([]*models.Review, error) {
+// GetDeletedReviewsByWeekID retrieves the deleted reviews of a specific week the minister
+// identified by callerID may see, as for GetReviewsByWeekID
+func (s *ReviewService) GetDeletedReviewsByWeekID(ctx context.Context, weekID, callerID string) ([]*models.Review, error) {
 	weekObjID, err := primitive.ObjectIDFromHex(weekID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid week ID: %v", err)
+		return nil, invalidID("week", err)
+	}
+	filter, err := s.visibleReviewFilter(ctx, AllStatuses, callerID)
+	if err != nil {
+		return nil, err
 	}
+	filter["week_id"] = weekObjID
+	filter["deleted"] = true
 
-	cursor, err := s.collection.Find(ctx, bson.M{"week_id": weekObjID, "deleted": true})
+	cursor, err := s.collection.Find(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get deleted reviews: %v", err)
 	}
@@ -203,6 +363,7 @@ func (s *ReviewService) GetDeletedReviewsByWeekID(ctx context.Context, weekID st
 		if err := cursor.Decode(&review); err != nil {
 			return nil, fmt.Errorf("failed to decode review: %v", err)
 		}
+		withDefaultStatus(&review)
 		reviews = append(reviews, &review)
 	}
 
@@ -213,19 +374,43 @@ func (s *ReviewService) GetDeletedReviewsByWeekID(ctx context.Context, weekID st
 	return reviews, nil
 }
 
-// HardDeleteReview permanently deletes a review by its ID
+// HardDeleteReview permanently deletes a review that was deleted while it could still be
+// edited. Approved and submitted reviews stay read-only, so they cannot be removed this way.
 func (s *ReviewService) HardDeleteReview(ctx context.Context, id string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return fmt.Errorf("invalid review ID: %v", err)
+		return invalidID("review", err)
+	}
+
+	var review models.Review
+	if err := s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&review); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return notFound("review not found")
+		}
+		return fmt.Errorf("failed to get review: %v", err)
+	}
+	withDefaultStatus(&review)
+	if !review.Deleted {
+		return conflict(CodeNotEditable, "review must be deleted before it can be permanently deleted")
+	}
+	if !isEditable(&review) {
+		return conflict(CodeNotEditable, "review is %s and can only be permanently deleted as a draft or when changes are requested", review.Status)
 	}
 
-	filter := bson.M{"_id": objID}
-	_, err = s.collection.DeleteOne(ctx, filter)
+	// Guard on the state checked above so a concurrent restore or status change wins
+	result, err := s.collection.DeleteOne(ctx, bson.M{
+		"_id":     objID,
+		"deleted": true,
+		"status":  bson.M{"$in": editableReviewStatuses},
+	})
 	if err != nil {
 		return fmt.Errorf("failed to permanently delete review: %v", err)
 	}
 
+	if result.DeletedCount == 0 {
+		return conflict(CodeEditConflict, "review was changed by someone else, please retry")
+	}
+
 	return nil
 }
 
@@ -233,7 +418,7 @@ func (s *ReviewService) HardDeleteReview(ctx context.Context, id string) error {
 func (s *ReviewService) RestoreReview(ctx context.Context, id string) (*models.Review, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid review ID: %v", err)
+		return nil, invalidID("review", err)
 	}
 
 	filter := bson.M{"_id": objID, "deleted": true}
@@ -247,7 +432,7 @@ func (s *ReviewService) RestoreReview(ctx context.Context, id string) (*models.R
 	result := s.collection.FindOneAndUpdate(ctx, filter, update)
 	if result.Err() != nil {
 		if result.Err() == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("deleted review not found")
+			return nil, notFound("deleted review not found")
 		}
 		return nil, fmt.Errorf("failed to restore review: %v", result.Err())
 	}
@@ -259,6 +444,313 @@ func (s *ReviewService) RestoreReview(ctx context.Context, id string) (*models.R
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch restored review: %v", err)
 	}
+	withDefaultStatus(&review)
 
 	return &review, nil
+}
+
+// ChangeStatus moves a review through the approval workflow and records the step in its
+// history. Authors submit drafts and withdraw submitted reviews; approving, requesting
+// changes and reopening an approved review need an approver, identified by callerID.
+func (s *ReviewService) ChangeStatus(ctx context.Context, id string, req models.ChangeReviewStatusRequest, callerID string) (*models.Review, error) {
+	if _, ok := reviewTransitions[req.Status]; !ok {
+		return nil, invalid(CodeInvalidValue, "status must be one of draft, submitted, changes_requested, approved")
+	}
+
+	review, err := s.GetReviewByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Deleted {
+		return nil, notFound("review not found")
+	}
+
+	allowed := false
+	for _, next := range reviewTransitions[review.Status] {
+		if next == req.Status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, conflict(CodeInvalidTransition, "cannot change status from %s to %s", review.Status, req.Status)
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	needsApprover := req.Status == models.ReviewStatusApproved || req.Status == models.ReviewStatusChangesRequested ||
+		review.Status == models.ReviewStatusApproved
+	if needsApprover {
+		if _, err := s.approvers.AuthorizeRestricted(ctx, callerID); err != nil {
+			return nil, err
+		}
+		if comment == "" && req.Status != models.ReviewStatusApproved {
+			return nil, invalid(CodeMissingField, "comment is required when requesting changes or reopening a review")
+		}
+	}
+
+	now := time.Now()
+	change := models.ReviewStatusChange{
+		From:      review.Status,
+		To:        req.Status,
+		Comment:   comment,
+		ChangedBy: callerID,
+		ChangedAt: now,
+	}
+	set := bson.M{"status": req.Status, "updated_at": now}
+	switch req.Status {
+	case models.ReviewStatusSubmitted:
+		set["submitted_at"] = now
+	case models.ReviewStatusApproved:
+		set["approved_at"] = now
+		set["approved_by"] = callerID
+	}
+	update := bson.M{
+		"$set":  set,
+		"$push": bson.M{"status_history": change},
+	}
+	if review.Status == models.ReviewStatusApproved {
+		update["$unset"] = bson.M{"approved_at": "", "approved_by": ""}
+	}
+
+	// Matching on the status read above keeps two concurrent changes from both applying
+	filter := reviewStatusMatch([]string{review.Status})
+	filter["_id"] = review.ID
+	filter["deleted"] = bson.M{"$ne": true}
+
+	result, err := s.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to change review status: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, conflict(CodeEditConflict, "review status was changed by someone else, please retry")
+	}
+
+	return s.GetReviewByID(ctx, id)
+}
+
+// visibleReviewFilter builds the filter selecting reviews by status for list endpoints,
+// limited to approved reviews and the caller's own unless the caller is an approver
+func (s *ReviewService) visibleReviewFilter(ctx context.Context, status, callerID string) (bson.M, error) {
+	filter, err := reviewStatusFilter(status)
+	if err != nil {
+		return nil, err
+	}
+	if s.isApprover(ctx, callerID) {
+		return filter, nil
+	}
+
+	visible := bson.A{reviewStatusMatch([]string{models.ReviewStatusApproved})}
+	if callerID != "" {
+		visible = append(visible, bson.M{"author_id": callerID})
+	}
+	return bson.M{"$and": bson.A{filter, bson.M{"$or": visible}}}, nil
+}
+
+// isApprover reports whether the minister identified by callerID holds a review approver role
+func (s *ReviewService) isApprover(ctx context.Context, callerID string) bool {
+	if callerID == "" {
+		return false
+	}
+	_, err := s.approvers.AuthorizeRestricted(ctx, callerID)
+	return err == nil
+}
+
+// reviewStatusFilter builds the filter selecting reviews by status: approved ones when
+// status is empty and every status for AllStatuses
+func reviewStatusFilter(status string) (bson.M, error) {
+	status = strings.TrimSpace(status)
+	if status == "" {
+		return reviewStatusMatch([]string{models.ReviewStatusApproved}), nil
+	}
+	if status == AllStatuses {
+		return bson.M{}, nil
+	}
+
+	var statuses []string
+	for _, value := range strings.Split(status, ",") {
+		value = strings.TrimSpace(value)
+		if _, ok := reviewTransitions[value]; !ok {
+			return nil, invalid(CodeInvalidValue, "status must be one of draft, submitted, changes_requested, approved or all")
+		}
+		statuses = append(statuses, value)
+	}
+	return reviewStatusMatch(statuses), nil
+}
+
+// reviewStatusMatch matches reviews in any of the given statuses. Reviews written
+// before the workflow existed have none and count as approved.
+func reviewStatusMatch(statuses []string) bson.M {
+	values := bson.A{}
+	for _, status := range statuses {
+		values = append(values, status)
+		if status == models.ReviewStatusApproved {
+			values = append(values, nil)
+		}
+	}
+	return bson.M{"status": bson.M{"$in": values}}
+}
+
+// withDefaultStatus fills in the status of reviews written before the workflow existed
+func withDefaultStatus(review *models.Review) {
+	if review.Status == "" {
+		review.Status = models.ReviewStatusApproved
+	}
+}
+
+// isEditable reports whether a review's content may change in its current status
+func isEditable(review *models.Review) bool {
+	for _, status := range editableReviewStatuses {
+		if review.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// checkService checks that a review targets a service of its week. An empty name and
+// time mean the review covers the whole week.
+func (s *ReviewService) checkService(ctx context.Context, weekID primitive.ObjectID, name, serviceTime string) error {
+	if name == "" && serviceTime == "" {
+		return nil
+	}
+	if name == "" || serviceTime == "" {
+		return invalid(CodeInvalidValue, "service name and time must be given together")
+	}
+
+	week, err := s.weekService.GetWeekByID(ctx, weekID.Hex())
+	if err != nil {
+		return err
+	}
+	return referencedService(week, name, serviceTime)
+}
+
+// validateRatings checks that every rating given is on the rating scale
+func validateRatings(ratings *models.ReviewRatings) error {
+	if ratings == nil {
+		return nil
+	}
+	for _, rating := range []*int{ratings.Engagement, ratings.Preparation, ratings.Punctuality, ratings.Safety} {
+		if rating != nil && (*rating < MinReviewRating || *rating > MaxReviewRating) {
+			return invalid(CodeInvalidValue, "ratings must be between %d and %d", MinReviewRating, MaxReviewRating)
+		}
+	}
+	return nil
+}
+
+// MigrateToTemplates moves reviews written before templates existed onto the first
+// version of the built-in template, answering its questions from the fixed review
+// fields. It returns how many reviews were migrated.
+func (s *ReviewService) MigrateToTemplates(ctx context.Context) (int64, error) {
+	template, err := s.templateService.GetDefaultTemplate(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	cursor, err := s.collection.Find(ctx, bson.M{"template_id": bson.M{"$exists": false}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get reviews: %v", err)
+	}
+	var reviews []models.Review
+	if err := cursor.All(ctx, &reviews); err != nil {
+		return 0, fmt.Errorf("failed to decode reviews: %v", err)
+	}
+
+	var migrated int64
+	for _, review := range reviews {
+		answers, err := validateAnswers(DefaultReviewTemplate.Questions, withLegacyAnswers(nil, DefaultReviewTemplate.Questions, map[string]*string{
+			QuestionWhatWentWell: &review.WhatWentWell,
+			QuestionCanImprove:   &review.CanImprove,
+			QuestionActionPlans:  &review.ActionPlans,
+		}))
+		if err != nil {
+			return migrated, err
+		}
+		_, err = s.collection.UpdateOne(ctx, bson.M{"_id": review.ID, "template_id": bson.M{"$exists": false}}, bson.M{
+			"$set": bson.M{
+				"template_id":      template.ID,
+				"template_version": 1,
+				"answers":          answers,
+			},
+		})
+		if err != nil {
+			return migrated, fmt.Errorf("failed to migrate review: %v", err)
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// templateFor returns the template a new review is written with: the one asked for,
+// or the built-in template
+func (s *ReviewService) templateFor(ctx context.Context, templateID string) (*models.ReviewTemplate, error) {
+	if templateID == "" {
+		return s.templateService.GetDefaultTemplate(ctx)
+	}
+	template, err := s.templateService.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalid) {
+			return nil, &ReferenceError{Field: "template_id", Message: "review template not found"}
+		}
+		return nil, err
+	}
+	if template.Deleted {
+		return nil, &ReferenceError{Field: "template_id", Message: "review template not found"}
+	}
+	return template, nil
+}
+
+// questionsOf returns the questions of the template version a review was written with
+func (s *ReviewService) questionsOf(ctx context.Context, review *models.Review) ([]models.TemplateQuestion, error) {
+	if review.TemplateID.IsZero() {
+		return DefaultReviewTemplate.Questions, nil
+	}
+	version, err := s.templateService.GetTemplateVersion(ctx, review.TemplateID, review.TemplateVersion)
+	if err != nil {
+		return nil, err
+	}
+	return version.Questions, nil
+}
+
+// withLegacyAnswers applies the fixed review fields given as answers to the questions
+// with the same IDs. Blank fields are ignored when the template has no such question.
+func withLegacyAnswers(answers []models.ReviewAnswer, questions []models.TemplateQuestion, fields map[string]*string) []models.ReviewAnswer {
+	asked := make(map[string]bool, len(questions))
+	for _, question := range questions {
+		asked[question.ID] = true
+	}
+
+	result := append([]models.ReviewAnswer{}, answers...)
+	for _, questionID := range []string{QuestionWhatWentWell, QuestionCanImprove, QuestionActionPlans} {
+		text := fields[questionID]
+		if text == nil || (*text == "" && !asked[questionID]) {
+			continue
+		}
+		replaced := false
+		for i := range result {
+			if result[i].QuestionID == questionID {
+				result[i] = models.ReviewAnswer{QuestionID: questionID, Text: *text}
+				replaced = true
+			}
+		}
+		if !replaced {
+			result = append(result, models.ReviewAnswer{QuestionID: questionID, Text: *text})
+		}
+	}
+	return result
+}
+
+// isLegacyQuestion reports whether a question ID is one of the built-in questions kept in the fixed review fields
+func isLegacyQuestion(questionID string) bool {
+	return questionID == QuestionWhatWentWell || questionID == QuestionCanImprove || questionID == QuestionActionPlans
+}
+
+// mirrorLegacyFields copies the answers to the built-in questions into the fixed review fields
+func mirrorLegacyFields(review *models.Review) {
+	texts := make(map[string]string)
+	for _, answer := range review.Answers {
+		texts[answer.QuestionID] = answer.Text
+	}
+	review.WhatWentWell = texts[QuestionWhatWentWell]
+	review.CanImprove = texts[QuestionCanImprove]
+	review.ActionPlans = texts[QuestionActionPlans]
 }
\ No newline at end of file
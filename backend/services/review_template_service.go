@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/sanitize"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	ReviewTemplatesCollection        = "review_templates"
+	ReviewTemplateVersionsCollection = "review_template_versions"
+)
+
+// Question IDs of the built-in template, which match the fixed review fields
+const (
+	QuestionWhatWentWell = "what_went_well"
+	QuestionCanImprove   = "can_improve"
+	QuestionActionPlans  = "action_plans"
+)
+
+// DefaultReviewTemplate is the built-in template holding the original review prompts
+var DefaultReviewTemplate = models.CreateReviewTemplateRequest{
+	Name:        "Weekly review",
+	Description: "What went well, what can improve and the action plans",
+	Questions: []models.TemplateQuestion{
+		{ID: QuestionWhatWentWell, Prompt: "What went well?", Kind: models.QuestionKindLongText},
+		{ID: QuestionCanImprove, Prompt: "What can we improve?", Kind: models.QuestionKindLongText},
+		{ID: QuestionActionPlans, Prompt: "What are the action plans?", Kind: models.QuestionKindLongText},
+	},
+}
+
+var questionKinds = map[string]bool{
+	models.QuestionKindLongText:    true,
+	models.QuestionKindRating:      true,
+	models.QuestionKindYesNo:       true,
+	models.QuestionKindMultiChoice: true,
+}
+
+// ReviewTemplateService manages review templates and keeps every version of them
+type ReviewTemplateService struct {
+	collection *mongo.Collection
+	versions   *mongo.Collection
+}
+
+func NewReviewTemplateService() *ReviewTemplateService {
+	return &ReviewTemplateService{
+		collection: database.GetCollection(ReviewTemplatesCollection),
+		versions:   database.GetCollection(ReviewTemplateVersionsCollection),
+	}
+}
+
+// EnsureDefaultTemplate creates the built-in template when it does not exist yet
+func (s *ReviewTemplateService) EnsureDefaultTemplate(ctx context.Context) (*models.ReviewTemplate, error) {
+	template, err := s.GetDefaultTemplate(ctx)
+	if err == nil || !errors.Is(err, ErrNotFound) {
+		return template, err
+	}
+	return s.create(ctx, DefaultReviewTemplate, true)
+}
+
+// GetDefaultTemplate retrieves the built-in template
+func (s *ReviewTemplateService) GetDefaultTemplate(ctx context.Context) (*models.ReviewTemplate, error) {
+	return s.findOne(ctx, bson.M{"built_in": true})
+}
+
+// CreateTemplate creates a review template at version 1
+func (s *ReviewTemplateService) CreateTemplate(ctx context.Context, req models.CreateReviewTemplateRequest) (*models.ReviewTemplate, error) {
+	return s.create(ctx, req, false)
+}
+
+func (s *ReviewTemplateService) create(ctx context.Context, req models.CreateReviewTemplateRequest, builtIn bool) (*models.ReviewTemplate, error) {
+	now := time.Now()
+	template := &models.ReviewTemplate{
+		ID:          primitive.NewObjectID(),
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Version:     1,
+		Questions:   req.Questions,
+		BuiltIn:     builtIn,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := validateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.snapshot(ctx, template); err != nil {
+		return nil, err
+	}
+	if _, err := s.collection.InsertOne(ctx, template); err != nil {
+		return nil, fmt.Errorf("failed to create review template: %v", err)
+	}
+	return template, nil
+}
+
+// GetTemplates lists the templates that take new reviews, by name
+func (s *ReviewTemplateService) GetTemplates(ctx context.Context) ([]models.ReviewTemplate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "built_in", Value: -1}, {Key: "name", Value: 1}})
+	cursor, err := s.collection.Find(ctx, bson.M{"deleted": false}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review templates: %v", err)
+	}
+
+	templates := []models.ReviewTemplate{}
+	if err := cursor.All(ctx, &templates); err != nil {
+		return nil, fmt.Errorf("failed to decode review templates: %v", err)
+	}
+	return templates, nil
+}
+
+// GetTemplateByID retrieves the latest version of a template, deleted or not
+func (s *ReviewTemplateService) GetTemplateByID(ctx context.Context, id string) (*models.ReviewTemplate, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, invalidID("review template", err)
+	}
+	return s.findOne(ctx, bson.M{"_id": objID})
+}
+
+// GetTemplateVersion retrieves a template as it was at a version
+func (s *ReviewTemplateService) GetTemplateVersion(ctx context.Context, templateID primitive.ObjectID, version int) (*models.ReviewTemplateVersion, error) {
+	var snapshot models.ReviewTemplateVersion
+	err := s.versions.FindOne(ctx, bson.M{"template_id": templateID, "version": version}).Decode(&snapshot)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("review template version not found")
+		}
+		return nil, fmt.Errorf("failed to get review template version: %v", err)
+	}
+	return &snapshot, nil
+}
+
+// GetTemplateVersions lists every version of a template, oldest first
+func (s *ReviewTemplateService) GetTemplateVersions(ctx context.Context, id string) ([]models.ReviewTemplateVersion, error) {
+	template, err := s.GetTemplateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+	cursor, err := s.versions.Find(ctx, bson.M{"template_id": template.ID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review template versions: %v", err)
+	}
+
+	versions := []models.ReviewTemplateVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, fmt.Errorf("failed to decode review template versions: %v", err)
+	}
+	return versions, nil
+}
+
+// UpdateTemplate edits a template as a new version. Earlier versions are kept, so
+// reviews written against them can still be read.
+func (s *ReviewTemplateService) UpdateTemplate(ctx context.Context, id string, req models.UpdateReviewTemplateRequest) (*models.ReviewTemplate, error) {
+	template, err := s.GetTemplateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template.Deleted {
+		return nil, notFound("review template not found")
+	}
+
+	if req.Name != nil {
+		template.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		template.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Questions != nil {
+		template.Questions = req.Questions
+	}
+	if err := validateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	previous := template.Version
+	template.Version++
+	template.UpdatedAt = time.Now()
+	snapshotID, err := s.snapshot(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the edit that read the previous version may replace it
+	result, err := s.collection.ReplaceOne(ctx, bson.M{"_id": template.ID, "version": previous}, template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update review template: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		s.versions.DeleteOne(ctx, bson.M{"_id": snapshotID})
+		return nil, conflict(CodeEditConflict, "review template was changed by someone else, try again")
+	}
+	return template, nil
+}
+
+// DeleteTemplate stops a template taking new reviews. It stays readable for the reviews written with it.
+func (s *ReviewTemplateService) DeleteTemplate(ctx context.Context, id string) error {
+	template, err := s.GetTemplateByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if template.Deleted {
+		return notFound("review template not found")
+	}
+	if template.BuiltIn {
+		return NewError(ErrUnprocessable, CodeNotEditable, "the built-in review template cannot be deleted")
+	}
+
+	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": template.ID}, bson.M{
+		"$set": bson.M{"deleted": true, "updated_at": time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete review template: %v", err)
+	}
+	return nil
+}
+
+func (s *ReviewTemplateService) findOne(ctx context.Context, filter bson.M) (*models.ReviewTemplate, error) {
+	var template models.ReviewTemplate
+	if err := s.collection.FindOne(ctx, filter).Decode(&template); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("review template not found")
+		}
+		return nil, fmt.Errorf("failed to get review template: %v", err)
+	}
+	return &template, nil
+}
+
+// snapshot stores the template's current version and returns the snapshot's ID
+func (s *ReviewTemplateService) snapshot(ctx context.Context, template *models.ReviewTemplate) (primitive.ObjectID, error) {
+	id := primitive.NewObjectID()
+	_, err := s.versions.InsertOne(ctx, models.ReviewTemplateVersion{
+		ID:          id,
+		TemplateID:  template.ID,
+		Version:     template.Version,
+		Name:        template.Name,
+		Description: template.Description,
+		Questions:   template.Questions,
+		CreatedAt:   template.UpdatedAt,
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return id, conflict(CodeEditConflict, "review template was changed by someone else, try again")
+		}
+		return id, fmt.Errorf("failed to save review template version: %v", err)
+	}
+	return id, nil
+}
+
+// validateTemplate checks a template's questions, tidying them and generating missing IDs
+func validateTemplate(template *models.ReviewTemplate) error {
+	if template.Name == "" {
+		return invalid(CodeMissingField, "review template name is required")
+	}
+	if len(template.Questions) == 0 {
+		return invalid(CodeInvalidValue, "review template needs at least one question")
+	}
+
+	seen := make(map[string]bool)
+	for i := range template.Questions {
+		question := &template.Questions[i]
+		question.ID = strings.TrimSpace(question.ID)
+		if question.ID == "" {
+			question.ID = primitive.NewObjectID().Hex()
+		}
+		if seen[question.ID] {
+			return invalid(CodeInvalidValue, "question ID %q is used twice", question.ID)
+		}
+		seen[question.ID] = true
+
+		question.Prompt = strings.TrimSpace(question.Prompt)
+		if question.Prompt == "" {
+			return invalid(CodeMissingField, "question prompt is required")
+		}
+		if !questionKinds[question.Kind] {
+			return invalid(CodeInvalidValue, "question kind must be long_text, rating, yes_no or multi_choice")
+		}
+
+		if question.Kind != models.QuestionKindMultiChoice {
+			question.Options = nil
+			question.AllowMultiple = false
+			continue
+		}
+		options := []string{}
+		seenOptions := make(map[string]bool)
+		for _, option := range question.Options {
+			option = strings.TrimSpace(option)
+			if option == "" || seenOptions[option] {
+				continue
+			}
+			seenOptions[option] = true
+			options = append(options, option)
+		}
+		if len(options) < 2 {
+			return invalid(CodeInvalidValue, "multi-choice questions need at least two options")
+		}
+		question.Options = options
+	}
+	return nil
+}
+
+// validateAnswers checks answers against a template version's questions and returns them
+// in question order, with long text sanitized. Blank answers are dropped.
+func validateAnswers(questions []models.TemplateQuestion, answers []models.ReviewAnswer) ([]models.ReviewAnswer, error) {
+	byQuestion := make(map[string]models.ReviewAnswer, len(answers))
+	for _, answer := range answers {
+		if _, ok := byQuestion[answer.QuestionID]; ok {
+			return nil, invalid(CodeInvalidValue, "question %q is answered twice", answer.QuestionID)
+		}
+		byQuestion[answer.QuestionID] = answer
+	}
+
+	ordered := []models.ReviewAnswer{}
+	for _, question := range questions {
+		answer, ok := byQuestion[question.ID]
+		delete(byQuestion, question.ID)
+
+		// Keep only the field for the question's kind
+		given := models.ReviewAnswer{QuestionID: question.ID}
+		switch question.Kind {
+		case models.QuestionKindLongText:
+			given.Text = sanitize.HTML(answer.Text)
+			ok = ok && sanitize.PlainText(given.Text) != ""
+		case models.QuestionKindRating:
+			given.Rating = answer.Rating
+			ok = ok && given.Rating != nil
+			if ok && (*given.Rating < MinReviewRating || *given.Rating > MaxReviewRating) {
+				return nil, invalid(CodeInvalidValue, "ratings must be between %d and %d", MinReviewRating, MaxReviewRating)
+			}
+		case models.QuestionKindYesNo:
+			given.Yes = answer.Yes
+			ok = ok && given.Yes != nil
+		case models.QuestionKindMultiChoice:
+			given.Choices = answer.Choices
+			ok = ok && len(given.Choices) > 0
+			if ok {
+				if err := checkChoices(question, given.Choices); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if !ok {
+			if question.Required {
+				return nil, invalid(CodeMissingField, "question %q requires an answer", question.ID)
+			}
+			continue
+		}
+		ordered = append(ordered, given)
+	}
+
+	for _, answer := range answers {
+		if _, ok := byQuestion[answer.QuestionID]; ok {
+			return nil, invalid(CodeInvalidValue, "question %q is not in the review template", answer.QuestionID)
+		}
+	}
+	return ordered, nil
+}
+
+// checkChoices checks that choices are options of a multi-choice question
+func checkChoices(question models.TemplateQuestion, choices []string) error {
+	if len(choices) > 1 && !question.AllowMultiple {
+		return invalid(CodeInvalidValue, "question %q takes a single choice", question.ID)
+	}
+	for _, choice := range choices {
+		found := false
+		for _, option := range question.Options {
+			if option == choice {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return invalid(CodeInvalidValue, "%q is not an option of question %q", choice, question.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const RolesCollection = "roles"
+
+type RoleService struct {
+	collection *mongo.Collection
+	people     *mongo.Collection
+}
+
+func NewRoleService() *RoleService {
+	return &RoleService{
+		collection: database.GetCollection(RolesCollection),
+		people:     database.GetCollection(PeopleCollection),
+	}
+}
+
+// CreateRole creates a new role
+func (s *RoleService) CreateRole(ctx context.Context, req models.CreateRoleRequest) (*models.Role, error) {
+	now := time.Now()
+	role := models.Role{
+		ID:          primitive.NewObjectID(),
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.checkName(ctx, role.Name, role.ID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.collection.InsertOne(ctx, role); err != nil {
+		return nil, fmt.Errorf("failed to create role: %v", err)
+	}
+
+	return &role, nil
+}
+
+// GetRoles retrieves all roles sorted by name
+func (s *RoleService) GetRoles(ctx context.Context) ([]models.Role, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := s.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	roles := []models.Role{}
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, fmt.Errorf("failed to decode roles: %v", err)
+	}
+
+	return roles, nil
+}
+
+// GetRoleByID retrieves a role by its ID
+func (s *RoleService) GetRoleByID(ctx context.Context, id string) (*models.Role, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, invalidID("role", err)
+	}
+
+	var role models.Role
+	err = s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&role)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, notFound("role not found")
+		}
+		return nil, fmt.Errorf("failed to get role: %v", err)
+	}
+
+	return &role, nil
+}
+
+// UpdateRole updates a role. A new name is carried over to everyone holding the role.
+func (s *RoleService) UpdateRole(ctx context.Context, id string, req models.UpdateRoleRequest) (*models.Role, error) {
+	role, err := s.GetRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	oldName := role.Name
+	if req.Name != nil {
+		role.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		role.Description = strings.TrimSpace(*req.Description)
+	}
+	role.UpdatedAt = time.Now()
+
+	if err := s.checkName(ctx, role.Name, role.ID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": role.ID}, role); err != nil {
+		return nil, fmt.Errorf("failed to update role: %v", err)
+	}
+
+	if role.Name != oldName {
+		if err := renameInPeople(ctx, s.people, "roles", oldName, role.Name); err != nil {
+			return nil, fmt.Errorf("failed to rename role on people: %v", err)
+		}
+	}
+
+	return role, nil
+}
+
+// DeleteRole deletes a role that no one holds
+func (s *RoleService) DeleteRole(ctx context.Context, id string) error {
+	role, err := s.GetRoleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.people.CountDocuments(ctx, bson.M{"roles": role.Name})
+	if err != nil {
+		return fmt.Errorf("failed to check role usage: %v", err)
+	}
+	if count > 0 {
+		return conflict(CodeInUse, "role is in use")
+	}
+
+	if _, err := s.collection.DeleteOne(ctx, bson.M{"_id": role.ID}); err != nil {
+		return fmt.Errorf("failed to delete role: %v", err)
+	}
+
+	return nil
+}
+
+// CanonicalRoles returns the catalog names of the given roles, rejecting any the
+// catalog does not hold. Spelling variants such as "first-aider" match "First Aider".
+func (s *RoleService) CanonicalRoles(ctx context.Context, names []string) ([]string, error) {
+	if names == nil {
+		return nil, nil
+	}
+	c, err := s.catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.canonical(names, "role")
+}
+
+func (s *RoleService) catalog(ctx context.Context) (catalog, error) {
+	roles, err := s.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.Name
+	}
+	return newCatalog(names), nil
+}
+
+// checkName validates a role name and checks no other role uses it or a spelling variant of it
+func (s *RoleService) checkName(ctx context.Context, name string, id primitive.ObjectID) error {
+	if catalogKey(name) == "" {
+		return invalid(CodeMissingField, "role name is required")
+	}
+
+	taken, err := catalogNameTaken(ctx, s.collection, name, id)
+	if err != nil {
+		return fmt.Errorf("failed to check role name: %v", err)
+	}
+	if taken {
+		return conflict(CodeAlreadyExists, "role already exists")
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultSafeguardingPolicy requires two unrelated adults in every room and at most
+// ten children per adult where an age group sets no ratio of its own
+var DefaultSafeguardingPolicy = models.SafeguardingPolicy{
+	MinAdults:               2,
+	DefaultChildrenPerAdult: 10,
+}
+
+// SafeguardingService checks week service rosters against the two-adult rule and
+// the child-to-minister ratios
+type SafeguardingService struct {
+	weeks           *mongo.Collection
+	attendance      *mongo.Collection
+	weekService     *WeekService
+	peopleService   *PeopleService
+	ageGroupService *AgeGroupService
+	policy          models.SafeguardingPolicy
+}
+
+func NewSafeguardingService(weekService *WeekService, peopleService *PeopleService, ageGroupService *AgeGroupService, policy models.SafeguardingPolicy) *SafeguardingService {
+	return &SafeguardingService{
+		weeks:           database.GetCollection(WeeksCollection),
+		attendance:      database.GetCollection(AttendanceCollection),
+		weekService:     weekService,
+		peopleService:   peopleService,
+		ageGroupService: ageGroupService,
+		policy:          policy,
+	}
+}
+
+// ValidateSafeguardingPolicy checks that the policy can be met by a roster
+func ValidateSafeguardingPolicy(policy models.SafeguardingPolicy) error {
+	if policy.MinAdults < 1 {
+		return fmt.Errorf("min adults must be at least 1")
+	}
+	if policy.DefaultChildrenPerAdult < 1 {
+		return fmt.Errorf("default children per adult must be at least 1")
+	}
+	return nil
+}
+
+// Policy returns the safeguarding policy in force
+func (s *SafeguardingService) Policy() models.SafeguardingPolicy {
+	return s.policy
+}
+
+// GetWeekReport checks every service of a week
+func (s *SafeguardingService) GetWeekReport(ctx context.Context, weekID string) (*models.SafeguardingReport, error) {
+	week, err := s.weekService.GetWeekByID(ctx, weekID)
+	if err != nil {
+		return nil, err
+	}
+	return s.EvaluateWeek(ctx, week)
+}
+
+// GetViolations checks every week starting within the filter's date range and returns
+// the reports of weeks with at least one violation, oldest first
+func (s *SafeguardingService) GetViolations(ctx context.Context, filter models.AttendanceReportFilter) ([]models.SafeguardingReport, error) {
+	query := bson.M{}
+	if match := dateRangeMatch("start_time", filter); match != nil {
+		query = match["$match"].(bson.M)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}})
+	cursor, err := s.weeks.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weeks: %v", err)
+	}
+	var weeks []models.Week
+	if err := cursor.All(ctx, &weeks); err != nil {
+		return nil, fmt.Errorf("failed to decode weeks: %v", err)
+	}
+
+	reports := []models.SafeguardingReport{}
+	for i := range weeks {
+		report, err := s.EvaluateWeek(ctx, &weeks[i])
+		if err != nil {
+			return nil, err
+		}
+		if report.Violations > 0 {
+			reports = append(reports, *report)
+		}
+	}
+	return reports, nil
+}
+
+// AnnotateWeek records on each service of a week the safeguarding rules it breaks
+func (s *SafeguardingService) AnnotateWeek(ctx context.Context, week *models.Week) error {
+	report, err := s.EvaluateWeek(ctx, week)
+	if err != nil {
+		return err
+	}
+	for i := range week.Services {
+		week.Services[i].Violations = report.Services[i].Violations
+	}
+	return nil
+}
+
+// EvaluateWeek checks every service of a week. A service is checked with the children
+// checked in to it once anyone has checked in, and with its expected children before that.
+func (s *SafeguardingService) EvaluateWeek(ctx context.Context, week *models.Week) (*models.SafeguardingReport, error) {
+	groups, err := s.ageGroupService.GetAgeGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ratios := make(map[string]int)
+	for _, group := range groups {
+		if group.MaxChildrenPerAdult != nil {
+			ratios[catalogKey(group.Name)] = *group.MaxChildrenPerAdult
+		}
+	}
+
+	checkedIn, err := s.checkedInChildren(ctx, week.ID)
+	if err != nil {
+		return nil, err
+	}
+	adults, err := s.rosteredAdults(ctx, week.Services)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.SafeguardingReport{
+		WeekID:    week.ID,
+		StartTime: week.StartTime,
+		Services:  make([]models.ServiceSafeguarding, len(week.Services)),
+	}
+	for i, service := range week.Services {
+		result := s.evaluateService(service, adults, checkedIn[ServiceKey(service.Name, service.Time)], ratios)
+		report.Services[i] = result
+		report.Violations += len(result.Violations)
+	}
+	return report, nil
+}
+
+// evaluateService applies the two-adult rule and the ratio to one service
+func (s *SafeguardingService) evaluateService(service models.Service, adults map[string]models.People, children []models.People, ratios map[string]int) models.ServiceSafeguarding {
+	result := models.ServiceSafeguarding{
+		ServiceName: service.Name,
+		ServiceTime: service.Time,
+		Violations:  []models.SafeguardingViolation{},
+	}
+
+	// Ministers sharing a household are related, so each household counts once
+	households := make(map[string]bool)
+	for _, id := range rosterIDs(service) {
+		adult, ok := adults[id]
+		if !ok {
+			continue
+		}
+		result.Adults++
+		household := strings.ToLower(strings.TrimSpace(adult.Household))
+		if household == "" {
+			household = id
+		}
+		households[household] = true
+	}
+	result.UnrelatedAdults = len(households)
+
+	// Rooms shared by several age groups use the strictest of their ratios
+	serviceRatio := s.policy.DefaultChildrenPerAdult
+	if ratio := strictestRatio(strings.Split(service.Name, ","), ratios); ratio > 0 {
+		serviceRatio = ratio
+	}
+
+	// Each child takes up 1/ratio of an adult, so mixed rooms are weighed by age group
+	var load float64
+	if len(children) > 0 {
+		result.Children = len(children)
+		result.ChildrenSource = models.ChildCountActual
+		for _, child := range children {
+			ratio := strictestRatio(child.AgeGroup, ratios)
+			if ratio == 0 {
+				ratio = serviceRatio
+			}
+			load += 1 / float64(ratio)
+		}
+	} else {
+		result.Children = service.ExpectedChildren
+		result.ChildrenSource = models.ChildCountExpected
+		load = float64(service.ExpectedChildren) / float64(serviceRatio)
+	}
+	result.RequiredAdults = int(math.Ceil(load - 1e-9))
+
+	if result.UnrelatedAdults < s.policy.MinAdults {
+		result.Violations = append(result.Violations, models.SafeguardingViolation{
+			Rule:     models.SafeguardingRuleTwoAdults,
+			Message:  fmt.Sprintf("%d unrelated adults rostered, %d required", result.UnrelatedAdults, s.policy.MinAdults),
+			Required: s.policy.MinAdults,
+			Actual:   result.UnrelatedAdults,
+		})
+	}
+	if result.Adults < result.RequiredAdults {
+		result.Violations = append(result.Violations, models.SafeguardingViolation{
+			Rule:     models.SafeguardingRuleRatio,
+			Message:  fmt.Sprintf("%d adults rostered for %d %s children, %d required", result.Adults, result.Children, result.ChildrenSource, result.RequiredAdults),
+			Required: result.RequiredAdults,
+			Actual:   result.Adults,
+		})
+	}
+
+	return result
+}
+
+// checkedInChildren returns the distinct children checked in to each service of a
+// week, keyed by ServiceKey. Children who have since checked out are included.
+func (s *SafeguardingService) checkedInChildren(ctx context.Context, weekID primitive.ObjectID) (map[string][]models.People, error) {
+	cursor, err := s.attendance.Find(ctx, bson.M{"week_id": weekID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance: %v", err)
+	}
+	var records []models.Attendance
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode attendance: %v", err)
+	}
+
+	children := make(map[string][]models.People)
+	if len(records) == 0 {
+		return children, nil
+	}
+
+	childIDs := make([]primitive.ObjectID, len(records))
+	for i, record := range records {
+		childIDs[i] = record.ChildID
+	}
+	people, err := s.peopleService.find(ctx, bson.M{"_id": bson.M{"$in": childIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get children: %v", err)
+	}
+	byID := make(map[primitive.ObjectID]models.People, len(people))
+	for _, person := range people {
+		byID[person.ID] = person
+	}
+
+	seen := make(map[string]bool)
+	for _, record := range records {
+		key := ServiceKey(record.ServiceName, record.ServiceTime)
+		if seen[key+record.ChildID.Hex()] {
+			continue
+		}
+		seen[key+record.ChildID.Hex()] = true
+
+		// Erased children are kept in attendance under an ID no person has; they
+		// still count, with the ratio of the room
+		child, ok := byID[record.ChildID]
+		if !ok {
+			child = models.People{ID: record.ChildID}
+		}
+		children[key] = append(children[key], child)
+	}
+	return children, nil
+}
+
+// rosteredAdults looks up the ministers rostered on any of the services, keyed by ID.
+// IDs that are not current ministers are left out and do not count as adults.
+func (s *SafeguardingService) rosteredAdults(ctx context.Context, services []models.Service) (map[string]models.People, error) {
+	var ids []primitive.ObjectID
+	for _, service := range services {
+		for _, id := range rosterIDs(service) {
+			if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+				ids = append(ids, objID)
+			}
+		}
+	}
+
+	adults := make(map[string]models.People)
+	if len(ids) == 0 {
+		return adults, nil
+	}
+
+	people, err := s.peopleService.find(ctx, bson.M{
+		"_id":     bson.M{"$in": ids},
+		"type":    "minister",
+		"deleted": false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ministers: %v", err)
+	}
+	for _, person := range people {
+		adults[person.ID.Hex()] = person
+	}
+	return adults, nil
+}
+
+// rosterIDs returns the distinct minister IDs serving in a service, SIC first
+func rosterIDs(service models.Service) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range append([]string{service.SIC}, service.Ministers...) {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// rosterContains reports whether a minister serves in a service
+func rosterContains(service models.Service, ministerID string) bool {
+	for _, id := range rosterIDs(service) {
+		if id == ministerID {
+			return true
+		}
+	}
+	return false
+}
+
+// strictestRatio returns the lowest ratio set by any of the named age groups, or 0 when none sets one
+func strictestRatio(names []string, ratios map[string]int) int {
+	strictest := 0
+	for _, name := range names {
+		ratio, ok := ratios[catalogKey(name)]
+		if ok && (strictest == 0 || ratio < strictest) {
+			strictest = ratio
+		}
+	}
+	return strictest
+}
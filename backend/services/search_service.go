@@ -0,0 +1,398 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"eaglekidz-backend/database"
+	"eaglekidz-backend/models"
+	"eaglekidz-backend/sanitize"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Search limits
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// SearchIndexName names the text index search relies on in each collection
+const SearchIndexName = "search"
+
+// snippetRadius is roughly how many characters of context a snippet keeps either side of the first match
+const snippetRadius = 60
+
+// SearchTypes lists every result type, in the order results of equal score are returned
+var SearchTypes = []string{models.SearchTypePerson, models.SearchTypeReview, models.SearchTypeWeek}
+
+var whitespacePattern = regexp.MustCompile(`\s+`)
+
+// SearchService searches people, reviews and weeks with MongoDB text indexes
+type SearchService struct {
+	people        *mongo.Collection
+	reviews       *mongo.Collection
+	weeks         *mongo.Collection
+	peopleService *PeopleService
+}
+
+func NewSearchService(peopleService *PeopleService) *SearchService {
+	return &SearchService{
+		people:        database.GetCollection(PeopleCollection),
+		reviews:       database.GetCollection(ReviewsCollection),
+		weeks:         database.GetCollection(WeeksCollection),
+		peopleService: peopleService,
+	}
+}
+
+// EnsureIndexes creates the text indexes search relies on when they are missing
+func (s *SearchService) EnsureIndexes(ctx context.Context) error {
+	indexes := []struct {
+		collection *mongo.Collection
+		keys       bson.D
+		weights    bson.M
+	}{
+		{s.people, bson.D{{Key: "first_name", Value: "text"}, {Key: "last_name", Value: "text"}, {Key: "notes", Value: "text"}},
+			bson.M{"first_name": 10, "last_name": 10}},
+		{s.reviews, bson.D{{Key: "what_went_well", Value: "text"}, {Key: "can_improve", Value: "text"}, {Key: "action_plans", Value: "text"}, {Key: "summary", Value: "text"}, {Key: "answers.text", Value: "text"}},
+			nil},
+		{s.weeks, bson.D{{Key: "services.name", Value: "text"}},
+			nil},
+	}
+
+	for _, index := range indexes {
+		opts := options.Index().SetName(SearchIndexName)
+		if index.weights != nil {
+			opts.SetWeights(index.weights)
+		}
+		model := mongo.IndexModel{Keys: index.keys, Options: opts}
+		_, err := index.collection.Indexes().CreateOne(ctx, model)
+		if isIndexConflict(err) {
+			// The fields searched have changed since the index was created
+			if _, err = index.collection.Indexes().DropOne(ctx, SearchIndexName); err == nil {
+				_, err = index.collection.Indexes().CreateOne(ctx, model)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create %s search index: %v", index.collection.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Search finds people, reviews and weeks matching query, best match first. types limits
+// the kinds of result returned; nil searches everything. Children's protected notes are
+// only searched for authorized callers.
+func (s *SearchService) Search(ctx context.Context, query string, types []string, limit int) ([]models.SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, invalid(CodeMissingField, "search query is required")
+	}
+	if types == nil {
+		types = SearchTypes
+	}
+	if limit < 1 || limit > MaxSearchLimit {
+		return nil, invalid(CodeInvalidValue, "limit must be between 1 and %d", MaxSearchLimit)
+	}
+	pattern := highlightPattern(terms)
+
+	results := []models.SearchResult{}
+	for _, resultType := range types {
+		var found []models.SearchResult
+		var err error
+		switch resultType {
+		case models.SearchTypePerson:
+			found, err = s.searchPeople(ctx, query, pattern, limit)
+		case models.SearchTypeReview:
+			found, err = s.searchReviews(ctx, query, pattern, limit)
+		case models.SearchTypeWeek:
+			found, err = s.searchWeeks(ctx, query, pattern, limit)
+		default:
+			return nil, invalid(CodeInvalidValue, "unknown search type %q", resultType)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (s *SearchService) searchPeople(ctx context.Context, query string, pattern *regexp.Regexp, limit int) ([]models.SearchResult, error) {
+	var docs []struct {
+		models.People `bson:",inline"`
+		Score         float64 `bson:"score"`
+	}
+	if err := s.textSearch(ctx, s.people, query, bson.M{"deleted": false}, limit, &docs); err != nil {
+		return nil, err
+	}
+
+	results := []models.SearchResult{}
+	for _, doc := range docs {
+		person := doc.People
+		if err := s.peopleService.reveal(ctx, &person); err != nil {
+			return nil, err
+		}
+
+		// Encrypted or withheld notes never produce a snippet, so a person who only
+		// matched on them is left out
+		snippets := matchSnippets(pattern, []searchField{
+			{"name", strings.TrimSpace(person.FirstName + " " + person.LastName)},
+			{"notes", person.Notes},
+		})
+		if len(snippets) == 0 {
+			continue
+		}
+		results = append(results, models.SearchResult{
+			Type:     models.SearchTypePerson,
+			ID:       person.ID,
+			Title:    strings.TrimSpace(person.FirstName + " " + person.LastName),
+			Score:    doc.Score,
+			Snippets: snippets,
+		})
+	}
+	return results, nil
+}
+
+func (s *SearchService) searchReviews(ctx context.Context, query string, pattern *regexp.Regexp, limit int) ([]models.SearchResult, error) {
+	var docs []struct {
+		models.Review `bson:",inline"`
+		Score         float64 `bson:"score"`
+	}
+	if err := s.textSearch(ctx, s.reviews, query, bson.M{"deleted": false}, limit, &docs); err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return []models.SearchResult{}, nil
+	}
+
+	// Reviews are titled after their week
+	weekIDs := make([]primitive.ObjectID, len(docs))
+	for i, doc := range docs {
+		weekIDs[i] = doc.WeekID
+	}
+	cursor, err := s.weeks.Find(ctx, bson.M{"_id": bson.M{"$in": weekIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weeks: %v", err)
+	}
+	var weeks []models.Week
+	if err := cursor.All(ctx, &weeks); err != nil {
+		return nil, fmt.Errorf("failed to decode weeks: %v", err)
+	}
+	weekTitles := make(map[primitive.ObjectID]string, len(weeks))
+	for _, week := range weeks {
+		weekTitles[week.ID] = weekTitle(week)
+	}
+
+	results := []models.SearchResult{}
+	for _, doc := range docs {
+		// Text indexes see the raw HTML, so matches on markup alone are dropped here
+		fields := []searchField{
+			{"what_went_well", stripHTML(doc.WhatWentWell)},
+			{"can_improve", stripHTML(doc.CanImprove)},
+			{"action_plans", stripHTML(doc.ActionPlans)},
+			{"summary", stripHTML(doc.Summary)},
+		}
+		for _, answer := range doc.Answers {
+			// The built-in template's answers are already searched as the fields above
+			if answer.Text != "" && !isLegacyQuestion(answer.QuestionID) {
+				fields = append(fields, searchField{"answers." + answer.QuestionID, stripHTML(answer.Text)})
+			}
+		}
+		snippets := matchSnippets(pattern, fields)
+		if len(snippets) == 0 {
+			continue
+		}
+		title := "Review"
+		if week, ok := weekTitles[doc.WeekID]; ok {
+			title = "Review: " + week
+		}
+		weekID := doc.WeekID
+		results = append(results, models.SearchResult{
+			Type:     models.SearchTypeReview,
+			ID:       doc.ID,
+			Title:    title,
+			WeekID:   &weekID,
+			Score:    doc.Score,
+			Snippets: snippets,
+		})
+	}
+	return results, nil
+}
+
+func (s *SearchService) searchWeeks(ctx context.Context, query string, pattern *regexp.Regexp, limit int) ([]models.SearchResult, error) {
+	var docs []struct {
+		models.Week `bson:",inline"`
+		Score       float64 `bson:"score"`
+	}
+	if err := s.textSearch(ctx, s.weeks, query, bson.M{}, limit, &docs); err != nil {
+		return nil, err
+	}
+
+	results := []models.SearchResult{}
+	for _, doc := range docs {
+		var fields []searchField
+		seen := make(map[string]bool)
+		for _, service := range doc.Services {
+			if !seen[service.Name] {
+				seen[service.Name] = true
+				fields = append(fields, searchField{"services", service.Name})
+			}
+		}
+		snippets := matchSnippets(pattern, fields)
+		if len(snippets) == 0 {
+			continue
+		}
+		results = append(results, models.SearchResult{
+			Type:     models.SearchTypeWeek,
+			ID:       doc.ID,
+			Title:    weekTitle(doc.Week),
+			Score:    doc.Score,
+			Snippets: snippets,
+		})
+	}
+	return results, nil
+}
+
+// textSearch runs a $text query on collection and decodes the best matches, with their
+// text score in a "score" field, into docs
+func (s *SearchService) textSearch(ctx context.Context, collection *mongo.Collection, query string, filter bson.M, limit int, docs interface{}) error {
+	filter["$text"] = bson.M{"$search": query}
+	score := bson.M{"score": bson.M{"$meta": "textScore"}}
+	opts := options.Find().SetProjection(score).SetSort(score).SetLimit(int64(limit))
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return fmt.Errorf("failed to search %s: %v", collection.Name(), err)
+	}
+	if err := cursor.All(ctx, docs); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", collection.Name(), err)
+	}
+	return nil
+}
+
+// searchField is a named piece of text a snippet may be taken from
+type searchField struct {
+	name string
+	text string
+}
+
+// matchSnippets returns a highlighted snippet for each field pattern matches
+func matchSnippets(pattern *regexp.Regexp, fields []searchField) []models.SearchSnippet {
+	snippets := []models.SearchSnippet{}
+	for _, field := range fields {
+		if text, ok := highlightSnippet(pattern, field.text); ok {
+			snippets = append(snippets, models.SearchSnippet{Field: field.name, Text: text})
+		}
+	}
+	return snippets
+}
+
+// highlightSnippet cuts text down to the words around its first match, escapes it for
+// HTML and wraps every match in <mark>
+func highlightSnippet(pattern *regexp.Regexp, text string) (string, bool) {
+	first := pattern.FindStringIndex(text)
+	if first == nil {
+		return "", false
+	}
+
+	// Widen to whole words; cutting at spaces also keeps multi-byte characters intact
+	start, end := 0, len(text)
+	if first[0] > snippetRadius {
+		start = strings.LastIndex(text[:first[0]-snippetRadius], " ") + 1
+	}
+	if first[1]+snippetRadius < len(text) {
+		if i := strings.Index(text[first[1]+snippetRadius:], " "); i >= 0 {
+			end = first[1] + snippetRadius + i
+		}
+	}
+	excerpt := text[start:end]
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	last := 0
+	for _, match := range pattern.FindAllStringIndex(excerpt, -1) {
+		b.WriteString(html.EscapeString(excerpt[last:match[0]]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(excerpt[match[0]:match[1]]))
+		b.WriteString("</mark>")
+		last = match[1]
+	}
+	b.WriteString(html.EscapeString(excerpt[last:]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
+
+// searchTerms returns the words of a query worth highlighting, leaving out excluded
+// words such as "-draft"
+func searchTerms(query string) []string {
+	var terms []string
+	for _, word := range strings.Fields(strings.ReplaceAll(query, `"`, " ")) {
+		if strings.HasPrefix(word, "-") {
+			continue
+		}
+		word = strings.ToLower(strings.Trim(word, ".,;:!?()[]{}'"))
+		if word != "" {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// highlightPattern matches words starting with the stem of any term. Text search stems
+// words, so "speakers" should also highlight "speaker" and "speaking".
+func highlightPattern(terms []string) *regexp.Regexp {
+	stems := make([]string, len(terms))
+	for i, term := range terms {
+		stems[i] = regexp.QuoteMeta(stem(term))
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(stems, "|") + `)\w*`)
+}
+
+// stem strips a common English suffix from a word, keeping at least three letters
+func stem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+// stripHTML reduces rich text to plain text on a single line
+func stripHTML(text string) string {
+	return whitespacePattern.ReplaceAllString(sanitize.PlainText(text), " ")
+}
+
+// isIndexConflict reports whether an index could not be created because one with the
+// same name exists with different keys or options
+func isIndexConflict(err error) bool {
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) {
+		return commandErr.Code == 85 || commandErr.Code == 86 // IndexOptionsConflict, IndexKeySpecsConflict
+	}
+	return false
+}
+
+func weekTitle(week models.Week) string {
+	return "Week of " + week.StartTime.Format("2 Jan 2006")
+}
@@ -17,12 +17,16 @@ import (
 const WeeksCollection = "weeks"
 
 type WeekService struct {
-	collection *mongo.Collection
+	collection        *mongo.Collection
+	complianceService *ComplianceService
+	peopleService     *PeopleService
 }
 
-func NewWeekService() *WeekService {
+func NewWeekService(complianceService *ComplianceService, peopleService *PeopleService) *WeekService {
 	return &WeekService{
-		collection: database.GetCollection(WeeksCollection),
+		collection:        database.GetCollection(WeeksCollection),
+		complianceService: complianceService,
+		peopleService:     peopleService,
 	}
 }
 
@@ -36,7 +40,7 @@ func (s *WeekService) CreateWeek(ctx context.Context, req models.CreateWeekReque
 	}).Decode(existingWeek)
 	
 	if err == nil {
-		return nil, fmt.Errorf("a week with the same start and end dates already exists")
+		return nil, conflict(CodeAlreadyExists, "a week with the same start and end dates already exists")
 	} else if err != mongo.ErrNoDocuments {
 		return nil, fmt.Errorf("failed to check for duplicate week: %v", err)
 	}
@@ -51,6 +55,13 @@ func (s *WeekService) CreateWeek(ctx context.Context, req models.CreateWeekReque
 		}
 	}
 
+	if err := s.peopleService.checkRosterReferences(ctx, services); err != nil {
+		return nil, err
+	}
+	if err := s.complianceService.EnforceRoster(ctx, services, req.EndTime); err != nil {
+		return nil, err
+	}
+
 	week := &models.Week{
 		ID:        primitive.NewObjectID(),
 		StartTime: req.StartTime,
@@ -72,14 +83,14 @@ func (s *WeekService) CreateWeek(ctx context.Context, req models.CreateWeekReque
 func (s *WeekService) GetWeekByID(ctx context.Context, id string) (*models.Week, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid week ID: %v", err)
+		return nil, invalidID("week", err)
 	}
 
 	var week models.Week
 	err = s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&week)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("week not found")
+			return nil, notFound("week not found")
 		}
 		return nil, fmt.Errorf("failed to get week: %v", err)
 	}
@@ -90,7 +101,7 @@ func (s *WeekService) GetWeekByID(ctx context.Context, id string) (*models.Week,
 // GetAllWeeks retrieves all weeks
 func (s *WeekService) GetAllWeeks(ctx context.Context) ([]*models.Week, error) {
 	// Sort by start_time in ascending order (oldest first)
-	opts := options.Find().SetSort(bson.D{{"start_time", 1}})
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}})
 	cursor, err := s.collection.Find(ctx, bson.M{}, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get weeks: %v", err)
@@ -117,7 +128,18 @@ func (s *WeekService) GetAllWeeks(ctx context.Context) ([]*models.Week, error) {
 func (s *WeekService) UpdateWeekServices(ctx context.Context, id string, req models.UpdateWeekServicesRequest) (*models.Week, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid week ID: %v", err)
+		return nil, invalidID("week", err)
+	}
+
+	week, err := s.GetWeekByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.peopleService.checkRosterReferences(ctx, req.Services); err != nil {
+		return nil, err
+	}
+	if err := s.complianceService.EnforceRoster(ctx, req.Services, week.EndTime); err != nil {
+		return nil, err
 	}
 
 	update := bson.M{
@@ -133,14 +155,119 @@ func (s *WeekService) UpdateWeekServices(ctx context.Context, id string, req mod
 	}
 
 	// Return the updated week
-	return s.GetWeekByID(ctx, id)
+	week, err = s.GetWeekByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.AnnotateCompliance(ctx, week); err != nil {
+		return nil, err
+	}
+	return week, nil
+}
+
+// CloneWeek creates a new week at the requested date range using the services of an existing week
+func (s *WeekService) CloneWeek(ctx context.Context, id string, req models.CloneWeekRequest) (*models.Week, error) {
+	source, err := s.GetWeekByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject exact duplicates first so callers get the same error as CreateWeek
+	count, err := s.collection.CountDocuments(ctx, bson.M{
+		"start_time": req.StartTime,
+		"end_time":   req.EndTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicate week: %v", err)
+	}
+	if count > 0 {
+		return nil, conflict(CodeAlreadyExists, "a week with the same start and end dates already exists")
+	}
+
+	// Two ranges overlap when each one starts before the other ends
+	count, err = s.collection.CountDocuments(ctx, bson.M{
+		"start_time": bson.M{"$lt": req.EndTime},
+		"end_time":   bson.M{"$gt": req.StartTime},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for overlapping week: %v", err)
+	}
+	if count > 0 {
+		return nil, conflict(CodeAlreadyExists, "the target date range overlaps an existing week")
+	}
+
+	services := make([]models.Service, len(source.Services))
+	for i, service := range source.Services {
+		services[i] = service
+		if !req.KeepAssignments {
+			services[i].SIC = ""
+			services[i].Ministers = nil
+		}
+	}
+
+	// Kept assignments may name ministers removed since the source week
+	if err := s.peopleService.checkRosterReferences(ctx, services); err != nil {
+		return nil, err
+	}
+	if err := s.complianceService.EnforceRoster(ctx, services, req.EndTime); err != nil {
+		return nil, err
+	}
+
+	week := &models.Week{
+		ID:        primitive.NewObjectID(),
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Services:  services,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	_, err = s.collection.InsertOne(ctx, week)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone week: %v", err)
+	}
+
+	return week, nil
+}
+
+// AnnotateCompliance records on each service the safeguarding items its rostered ministers
+// are missing for the week. Items must still be current when the week ends.
+func (s *WeekService) AnnotateCompliance(ctx context.Context, week *models.Week) error {
+	gaps, err := s.complianceService.CheckRoster(ctx, week.Services, week.EndTime)
+	if err != nil {
+		return err
+	}
+	for i := range week.Services {
+		week.Services[i].MissingCompliance = gaps[i]
+	}
+	return nil
 }
 
-// DeleteWeek deletes a week by its ID
+// DeleteWeek deletes a week by its ID. A week with reviews, attendance, action items or
+// incidents is kept, and a ReferenceError says which.
 func (s *WeekService) DeleteWeek(ctx context.Context, id string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return fmt.Errorf("invalid week ID: %v", err)
+		return invalidID("week", err)
+	}
+
+	// Records of what happened in the week would be left pointing at nothing
+	for _, ref := range []struct {
+		collection string
+		name       string
+	}{
+		{ReviewsCollection, "reviews"},
+		{AttendanceCollection, "attendance"},
+		{ActionItemsCollection, "action items"},
+		{IncidentsCollection, "incidents"},
+	} {
+		count, err := database.GetCollection(ref.collection).CountDocuments(ctx, bson.M{"week_id": objID})
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %v", ref.name, err)
+		}
+		if count > 0 {
+			return &ReferenceError{Field: "id", Message: fmt.Sprintf("week has %s and cannot be deleted", ref.name)}
+		}
 	}
 
 	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": objID})
@@ -149,7 +276,7 @@ func (s *WeekService) DeleteWeek(ctx context.Context, id string) error {
 	}
 
 	if result.DeletedCount == 0 {
-		return fmt.Errorf("week not found")
+		return notFound("week not found")
 	}
 
 	return nil
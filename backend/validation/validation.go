@@ -0,0 +1,345 @@
+// Package validation checks request payloads against the rules in their `binding` struct
+// tags, such as `binding:"required,max=100"`, and reports every field that breaks one.
+//
+// Rules are separated by commas and checked in order:
+//
+//	required   the field must be set: non-blank strings, non-empty slices, non-zero times
+//	           and numbers, non-nil pointers
+//	omitempty  skips the remaining rules when the field is not set
+//	notblank   strings must not be empty or only spaces; unlike required, it allows a
+//	           nil pointer, so optional update fields can be left out but not cleared
+//	oneof=a b  the value must be one of the space-separated words
+//	email      the value must look like an email address
+//	phone      the value must be a phone number of 7 to 15 digits, optionally starting
+//	           with "+" and written with spaces, dashes, dots or parentheses
+//	min=N      strings must have at least N characters, slices at least N items and
+//	           integers a value of at least N
+//	max=N      the same as min, as an upper bound
+//
+// Nil pointers are only checked by required. Blank strings pass email and phone, so an
+// optional address can be cleared. Nested structs, and slices of structs, are
+// checked field by field, so errors name paths like "services[0].name".
+//
+// The tags of a type are checked the first time a value of it is validated; an unknown
+// rule or a limit that is not a number is reported as an error rather than as a field
+// breaking a rule.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldError reports a field that breaks one of its rules
+type FieldError struct {
+	Field   string `json:"field"` // Path of the field in the request, e.g. "phone" or "services[0].name"
+	Rule    string `json:"rule"`  // Rule broken, e.g. "required" or "max"
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Errors lists every field of a request that breaks a rule
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+const (
+	minPhoneDigits = 7
+	maxPhoneDigits = 15
+)
+
+var (
+	emailPattern = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@.]+$`)
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// checkedTypes holds the error, or nil, from checking the tags of each type validated so far
+var checkedTypes sync.Map
+
+// Struct checks v, a struct or a pointer to one. It returns Errors listing the fields
+// breaking a rule, an error when the type's tags are not valid, or nil when every
+// field passes.
+func Struct(v interface{}) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	if err := typeTags(value.Type()); err != nil {
+		return err
+	}
+	var errs Errors
+	checkStruct(value, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Tag checks that a binding tag only uses known rules with valid parameters
+func Tag(tag string) error {
+	if tag == "" {
+		return nil
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required", "omitempty", "notblank", "email", "phone":
+			if param != "" {
+				return fmt.Errorf("rule %q takes no parameter", name)
+			}
+		case "oneof":
+			if len(strings.Fields(param)) == 0 {
+				return fmt.Errorf("rule %q needs at least one option", name)
+			}
+		case "min", "max":
+			if _, err := strconv.Atoi(param); err != nil {
+				return fmt.Errorf("invalid %s limit %q", name, param)
+			}
+		default:
+			return fmt.Errorf("unknown rule %q", name)
+		}
+	}
+	return nil
+}
+
+// typeTags checks the binding tags of a struct type and the structs nested in it,
+// remembering the result for the next value of the type
+func typeTags(t reflect.Type) error {
+	if err, ok := checkedTypes.Load(t); ok {
+		if err == nil {
+			return nil
+		}
+		return err.(error)
+	}
+	err := structTags(t, t.Name(), make(map[reflect.Type]bool))
+	checkedTypes.Store(t, err)
+	return err
+}
+
+func structTags(t reflect.Type, path string, seen map[reflect.Type]bool) error {
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || jsonName(field) == "" {
+			continue
+		}
+		if err := Tag(field.Tag.Get("binding")); err != nil {
+			return fmt.Errorf("validation: %s.%s: %w", path, field.Name, err)
+		}
+		nested := field.Type
+		for nested.Kind() == reflect.Ptr || nested.Kind() == reflect.Slice || nested.Kind() == reflect.Array {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && nested != timeType {
+			if err := structTags(nested, path+"."+field.Name, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkStruct(value reflect.Value, prefix string, errs *Errors) {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := jsonName(field)
+		if name == "" {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		checkField(value.Field(i), path, field.Tag.Get("binding"), errs)
+	}
+}
+
+func checkField(value reflect.Value, path, tag string, errs *Errors) {
+	var rules []string
+	if tag != "" {
+		rules = strings.Split(tag, ",")
+	}
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			if isZero(value) {
+				*errs = append(*errs, FieldError{Field: path, Rule: name, Message: "is required"})
+				return
+			}
+		case "omitempty":
+			if isZero(value) {
+				return
+			}
+		default:
+			if value.Kind() == reflect.Ptr && value.IsNil() {
+				return
+			}
+			if message := check(reflect.Indirect(value), name, param); message != "" {
+				*errs = append(*errs, FieldError{Field: path, Rule: name, Message: message})
+				return
+			}
+		}
+	}
+	checkNested(reflect.Indirect(value), path, errs)
+}
+
+// checkNested checks the fields of a nested struct, or of every struct in a slice
+func checkNested(value reflect.Value, path string, errs *Errors) {
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() != timeType {
+			checkStruct(value, path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			item := reflect.Indirect(value.Index(i))
+			if item.Kind() == reflect.Struct && item.Type() != timeType {
+				checkStruct(item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	}
+}
+
+// check returns why value breaks a rule, or "" when it passes. Its tag has been
+// checked by Tag.
+func check(value reflect.Value, rule, param string) string {
+	switch rule {
+	case "notblank":
+		if value.Kind() == reflect.String && strings.TrimSpace(value.String()) == "" {
+			return "must not be blank"
+		}
+	case "oneof":
+		options := strings.Fields(param)
+		if value.Kind() == reflect.String {
+			for _, option := range options {
+				if value.String() == option {
+					return ""
+				}
+			}
+		}
+		return "must be one of " + strings.Join(options, ", ")
+	case "email":
+		if value.Kind() == reflect.String && !isZero(value) && !emailPattern.MatchString(strings.TrimSpace(value.String())) {
+			return "must be a valid email address"
+		}
+	case "phone":
+		if value.Kind() == reflect.String && !isZero(value) && !isPhone(value.String()) {
+			return fmt.Sprintf("must be a phone number of %d to %d digits", minPhoneDigits, maxPhoneDigits)
+		}
+	case "min", "max":
+		limit, _ := strconv.Atoi(param)
+		return checkLength(value, rule, limit)
+	}
+	return ""
+}
+
+// checkLength checks a min or max rule against a string's characters, a slice's items
+// or a number's value
+func checkLength(value reflect.Value, rule string, limit int) string {
+	var n int64
+	var unit string
+	switch value.Kind() {
+	case reflect.String:
+		n, unit = int64(utf8.RuneCountInString(strings.TrimSpace(value.String()))), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, unit = int64(value.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = int64(value.Uint())
+	default:
+		return ""
+	}
+	if unit != "" && limit == 1 {
+		unit = strings.TrimSuffix(unit, "s")
+	}
+	if rule == "min" && n < int64(limit) {
+		return fmt.Sprintf("must be at least %d%s", limit, unit)
+	}
+	if rule == "max" && n > int64(limit) {
+		return fmt.Sprintf("must be at most %d%s", limit, unit)
+	}
+	return ""
+}
+
+// isPhone reports whether s is a phone number: 7 to 15 digits, an optional leading "+",
+// and spaces, dashes, dots or parentheses between them
+func isPhone(s string) bool {
+	s = strings.TrimSpace(s)
+	digits := 0
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return false
+		}
+	}
+	return digits >= minPhoneDigits && digits <= maxPhoneDigits
+}
+
+// isZero reports whether a field is not set. Strings of only spaces count as not set,
+// and so do empty slices and maps.
+func isZero(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.Bool:
+		return false
+	case reflect.Struct:
+		if value.Type() == timeType {
+			return value.Interface().(time.Time).IsZero()
+		}
+		return false
+	default:
+		return value.IsZero()
+	}
+}
+
+// jsonName returns the name of a field in JSON, or "" when it is not encoded
+func jsonName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
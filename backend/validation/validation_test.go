@@ -0,0 +1,131 @@
+package validation
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		wantErr bool
+	}{
+		{"", false},
+		{"required", false},
+		{"required,max=100", false},
+		{"omitempty,oneof=open in_progress done", false},
+		{"notblank, phone", false},
+		{"email,max=254", false},
+		{"min=0", false},
+		{"requried", true},
+		{"max", true},
+		{"max=ten", true},
+		{"min=", true},
+		{"oneof=", true},
+		{"required=true", true},
+		{"required,", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if err := Tag(tt.tag); (err != nil) != tt.wantErr {
+				t.Errorf("Tag(%q) = %v, want error %v", tt.tag, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStruct(t *testing.T) {
+	type item struct {
+		Name string `json:"name" binding:"required,max=5"`
+	}
+	type request struct {
+		Name   string  `json:"name" binding:"required"`
+		Status string  `json:"status" binding:"omitempty,oneof=open done"`
+		Phone  *string `json:"phone" binding:"notblank,phone"`
+		Items  []item  `json:"items" binding:"min=1"`
+	}
+	blank, phone := " ", "+44 20 7946 0958"
+
+	tests := []struct {
+		name    string
+		request request
+		want    []string
+	}{
+		{"valid", request{Name: "a", Phone: &phone, Items: []item{{Name: "b"}}}, nil},
+		{"missing", request{Status: "closed", Phone: &blank}, []string{"name", "status", "phone", "items"}},
+		{"nested", request{Name: "a", Items: []item{{Name: "b"}, {Name: "too long"}}}, []string{"items[1].name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Struct(&tt.request)
+			var errs Errors
+			if err != nil && !errors.As(err, &errs) {
+				t.Fatalf("Struct returned %v, want field errors", err)
+			}
+			var fields []string
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.want) {
+				t.Errorf("fields = %v, want %v", fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructInvalidTags(t *testing.T) {
+	type item struct {
+		Count int `json:"count" binding:"max=many"`
+	}
+	type request struct {
+		Name  string `json:"name" binding:"requird"`
+		Items []item `json:"items"`
+	}
+	type nested struct {
+		Items []item `json:"items"`
+	}
+
+	for _, v := range []interface{}{&request{}, &nested{}, &nested{Items: []item{{Count: 1}}}} {
+		err := Struct(v)
+		var errs Errors
+		if err == nil || errors.As(err, &errs) {
+			t.Errorf("Struct(%T) = %v, want a tag error", v, err)
+		}
+	}
+}
+
+// TestRequestTags checks the binding tag of every field declared in the packages
+// holding request models, so a bad tag fails here rather than in a request
+func TestRequestTags(t *testing.T) {
+	for _, dir := range []string{"../models", "../handlers"} {
+		fset := token.NewFileSet()
+		pkgs, err := parser.ParseDir(fset, dir, nil, 0)
+		if err != nil {
+			t.Fatalf("parse %s: %v", dir, err)
+		}
+		for _, pkg := range pkgs {
+			ast.Inspect(pkg, func(node ast.Node) bool {
+				field, ok := node.(*ast.Field)
+				if !ok || field.Tag == nil {
+					return true
+				}
+				tag, err := strconv.Unquote(field.Tag.Value)
+				if err != nil {
+					t.Errorf("%s: %v", fset.Position(field.Pos()), err)
+					return true
+				}
+				if err := Tag(reflect.StructTag(tag).Get("binding")); err != nil {
+					t.Errorf("%s: %v", fset.Position(field.Pos()), err)
+				}
+				return true
+			})
+		}
+	}
+}
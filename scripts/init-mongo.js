@@ -6,16 +6,88 @@ db.createCollection('weeks');
 db.createCollection('reviews');
 db.createCollection('ministers');
 db.createCollection('children');
+db.createCollection('attendance');
+db.createCollection('follow_ups');
+db.createCollection('medical_info');
+db.createCollection('audit_log');
+db.createCollection('erasures');
+db.createCollection('age_groups');
+db.createCollection('roles');
+db.createCollection('compliance_items');
+db.createCollection('incidents');
+db.createCollection('action_items');
+db.createCollection('review_templates');
+db.createCollection('review_template_versions');
+db.createCollection('review_comments');
 
 // Create indexes for better performance
 db.weeks.createIndex({ "start_date": 1 });
 db.weeks.createIndex({ "end_date": 1 });
 db.reviews.createIndex({ "week_id": 1 });
 db.reviews.createIndex({ "created_at": -1 });
+db.reviews.createIndex({ "week_id": 1, "service_name": 1, "service_time": 1 });
 db.ministers.createIndex({ "first_name": 1 });
 db.ministers.createIndex({ "last_name": 1 });
 db.children.createIndex({ "first_name": 1 });
 db.children.createIndex({ "last_name": 1 });
 db.children.createIndex({ "age_group": 1 });
+db.attendance.createIndex({ "week_id": 1, "service_name": 1, "service_time": 1 });
+db.attendance.createIndex({ "child_id": 1 });
+db.attendance.createIndex({ "child_id": 1, "week_id": 1 }, { name: "open_check_in", unique: true, partialFilterExpression: { "open": true } });
+db.follow_ups.createIndex({ "child_id": 1, "status": 1 });
+db.people.createIndex({ "phone_index": 1 });
+db.people.createIndex({ "type": 1, "status": 1 });
+db.medical_info.createIndex({ "person_id": 1 }, { unique: true });
+db.audit_log.createIndex({ "entity_type": 1, "entity_id": 1, "created_at": -1 });
+db.audit_log.createIndex({ "actor_id": 1 });
+db.weeks.createIndex({ "services.sic": 1 });
+db.weeks.createIndex({ "services.ministers": 1 });
+db.people.createIndex({ "type": 1, "date_of_birth": 1 });
+db.age_groups.createIndex({ "sort_order": 1, "name": 1 });
+db.roles.createIndex({ "name": 1 }, { unique: true });
+db.people.createIndex({ "age_group": 1 });
+db.people.createIndex({ "roles": 1 });
+db.erasures.createIndex({ "person_id": 1 }, { unique: true });
+db.compliance_items.createIndex({ "minister_id": 1, "type": 1, "expires_at": -1 });
+db.compliance_items.createIndex({ "expires_at": 1 });
+db.incidents.createIndex({ "week_id": 1, "occurred_at": -1 });
+db.incidents.createIndex({ "child_ids": 1 });
+db.incidents.createIndex({ "follow_up_status": 1 });
+db.action_items.createIndex({ "review_id": 1 });
+db.action_items.createIndex({ "week_id": 1, "status": 1 });
+db.action_items.createIndex({ "status": 1, "due_date": 1 });
+db.action_items.createIndex({ "owner_id": 1 });
+db.review_templates.createIndex({ "built_in": 1 });
+db.review_template_versions.createIndex({ "template_id": 1, "version": 1 }, { unique: true });
+db.reviews.createIndex({ "template_id": 1 });
+db.reviews.createIndex({ "status": 1, "week_id": 1 });
+db.review_comments.createIndex({ "review_id": 1, "created_at": 1 });
+db.review_comments.createIndex({ "author_id": 1 });
+db.review_comments.createIndex({ "mentions": 1 });
+db.people.createIndex(
+  { "first_name": "text", "last_name": "text", "notes": "text" },
+  { name: "search", weights: { "first_name": 10, "last_name": 10 } }
+);
+db.reviews.createIndex(
+  { "what_went_well": "text", "can_improve": "text", "action_plans": "text", "summary": "text", "answers.text": "text" },
+  { name: "search" }
+);
+db.weeks.createIndex({ "services.name": "text" }, { name: "search" });
+
+// Default age groups; adjust the bounds to match the ministry's classes
+db.age_groups.insertMany([
+  { name: "Little Eagle", min_age: 0, max_age: 3, sort_order: 1, max_children_per_adult: 4, created_at: new Date(), updated_at: new Date() },
+  { name: "All Star", min_age: 4, max_age: 6, sort_order: 2, max_children_per_adult: 6, created_at: new Date(), updated_at: new Date() },
+  { name: "Super Trooper", min_age: 7, max_age: 9, sort_order: 3, max_children_per_adult: 8, created_at: new Date(), updated_at: new Date() },
+  { name: "Voltage", min_age: 10, max_age: 12, sort_order: 4, max_children_per_adult: 10, created_at: new Date(), updated_at: new Date() }
+]);
+
+// Default minister roles; "Leader" and "First Aider" grant restricted access by default
+db.roles.insertMany([
+  { name: "Leader", created_at: new Date(), updated_at: new Date() },
+  { name: "Teacher", created_at: new Date(), updated_at: new Date() },
+  { name: "Helper", created_at: new Date(), updated_at: new Date() },
+  { name: "First Aider", created_at: new Date(), updated_at: new Date() }
+]);
 
 print('EagleKidz database initialized successfully!');
\ No newline at end of file